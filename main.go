@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
 
 	"github.com/drappier-charles/covreport/reporter"
 )
@@ -14,8 +16,14 @@ func main() {
 		goto LogError
 	}
 
-	// Generate a coverage report using the configuration.
-	err = reporter.Report(cfg)
+	// Generate a coverage report using the configuration, or keep regenerating it until interrupted in watch mode.
+	if cfg.Watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		err = reporter.Watch(ctx, cfg)
+		stop()
+	} else {
+		err = reporter.Report(cfg)
+	}
 
 LogError:
 	// If an error occurred, log it and exit with a non-zero status code.
@@ -1,17 +1,338 @@
 // Package config provides types and functions for working with configuration data.
 package config
 
+import (
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
 // Config represents the configuration for a program.
 type Config struct {
-	Input    string
-	Output   string
-	Root     string
-	Cutlines *Cutlines
-	Ignores  []string
+	Input  string
+	Output string
+	Root   string
+	// Workspace is the directory of the go.work file whose modules the report spans, if any.
+	// Profiles of a workspace name their files by import path, so Root is left out.
+	Workspace string
+	Cutlines  *Cutlines
+	// Colors overrides the colors of the coverage classes, nil keeping the default palette.
+	Colors  *Colors
+	Ignores []string
+	Layout  string
+	// ScoreExcludes are the patterns of the files shown in the report but left out of the totals and thresholds,
+	// such as generated code: path prefixes, or globs matching the path or the base name of the files.
+	ScoreExcludes []string
+	// GoOnly leaves the files of other languages than Go, such as the C and assembly files of cgo packages,
+	// out of the totals and thresholds, still showing them in the report.
+	GoOnly bool
+	// PathStyle tells whether breadcrumbs and items show full package paths or base names.
+	PathStyle string
+	Sort      string
+	Collapse  int
+	Format    string
+	// Title is the title of the HTML report, naming the project, empty for the default one.
+	Title string
+	// NoCollapseRoot starts the report at the root rather than at its first directory having files or several subdirectories.
+	NoCollapseRoot bool
+	// GroupBy prints a flat summary of the coverage grouped by top directory or extension to stdout, if any,
+	// or to stderr when reporter.Report writes the report itself to stdout.
+	GroupBy string
+	// SplitOutput is the directory the HTML report is written to as a page per directory and file, if any.
+	SplitOutput string
+	// Manifest is the JSON file indexing the reports generated with it, which the report is added to, if any.
+	Manifest string
+
+	// Precision is the number of decimal places of the percentages shown in the report, nil for one.
+	Precision *int
+	Diff      string
+	// Compare is the baseline profile the coverage is compared with, if any.
+	Compare string
+
+	// FailUnder is the minimum total coverage percentage.
+	FailUnder float64
+	// Thresholds maps package path prefixes to their minimum coverage percentage.
+	Thresholds map[string]float64
+	// FailOnZero fails when a file has statements but none of them covered.
+	FailOnZero bool
+
+	MaxAnnotations int
+	// BadgeStyle tells whether the badge shows the percentage only or the statement counts too.
+	BadgeStyle string
+	// Context is the number of source lines printed around uncovered lines in the text format.
+	Context int
+
+	// IncludeTests counts _test.go files in the coverage.
+	IncludeTests bool
+
+	// SelfContained embeds the input profile in the HTML report.
+	SelfContained bool
+	// Minify strips the whitespace of the HTML report which doesn't change its appearance.
+	Minify bool
+	// StrictLines only shows lines as covered in the HTML report when every block overlapping them ran.
+	StrictLines bool
+	// LinkUncovered links the files listed in the HTML report to their first uncovered line.
+	LinkUncovered bool
+	// OnlyIncomplete hides the fully covered files, and the directories left empty, from the report.
+	// The totals still count them.
+	OnlyIncomplete bool
+	// Sidebar shows a navigation pane listing every directory next to the views of the HTML report.
+	Sidebar bool
+	// LineBackgrounds overrides the backgrounds of the uncovered and covered lines of the HTML report,
+	// nil keeping the default ones.
+	LineBackgrounds *LineBackgrounds
+
+	// InputFormat is the format of the input, a coverage profile or go test -json events.
+	InputFormat string
+	// Profile is the coverage profile of go test -json input, whose events only give the test results.
+	Profile string
+
+	// Lenient skips malformed profile lines instead of failing.
+	Lenient bool
+	// AllowEmpty reports profiles without coverage blocks instead of failing.
+	AllowEmpty bool
+	// Strict fails on profiles which look stale, referencing lines past the end of a source file, instead of warning.
+	Strict bool
+
+	// Summary prints the summary line of the total coverage to stderr once the report is generated.
+	Summary bool
+	// Quiet doesn't print warnings to stderr.
+	Quiet bool
+	// Verbose prints debug messages tracing the files read, the ignored blocks and the profile blocks to stderr.
+	Verbose bool
+	// Progress prints the progress of parsing the profile and rendering the files to stderr.
+	Progress bool
+	// Stats prints the number of blocks parsed, of files, directories and statements of the report,
+	// and the time spent parsing and rendering, to stderr.
+	Stats bool
+	// Stdout is where the messages said to go to stdout are written, os.Stdout when nil.
+	Stdout io.Writer
+	// Stderr is where the messages said to go to stderr are written, warnings included, os.Stderr when nil.
+	Stderr io.Writer
+
+	// CacheSources keeps the source files in memory once read, for the formats and the views rendering them again,
+	// instead of reading them every time they are needed.
+	CacheSources bool
+	// ReadRetries is the number of times a failed read of a source file is tried again, with an exponential backoff,
+	// for network filesystems returning transient errors. 0 tries each file once.
+	ReadRetries int
+	// DryRun parses the profile and prints the summary line of the total coverage without writing the report,
+	// the thresholds still being checked.
+	DryRun bool
+	// Watch regenerates the report whenever the input changes, see reporter.Watch.
+	// The HTML report then reloads itself in the browser.
+	Watch bool
+	// WatchInterval is the interval between the checks of the input in watch mode, 0 meaning a default interval.
+	WatchInterval time.Duration
+
+	// External tells how files outside of Root are handled.
+	External string
+	// Remaps rewrite the leading paths of the files of the profile before locating them,
+	// to render a profile generated on another machine.
+	Remaps []PathRemap
+	// RespectGitignore leaves the files ignored by git out of the report.
+	RespectGitignore bool
+	// CodeOwners is the CODEOWNERS file giving the owners shown in the views of the HTML report, if any.
+	CodeOwners string
+
+	// Constraints tells how files whose build constraints don't match GOOS and GOARCH are handled.
+	Constraints string
+	GOOS        string
+	GOARCH      string
+}
+
+// Formats of the input.
+const (
+	// InputFormatProfile reads a coverage profile.
+	InputFormatProfile = "profile"
+	// InputFormatJSON reads go test -json events for the test results, the coverage profile being read from Profile.
+	InputFormatJSON = "json"
+)
+
+// Handling of files whose build constraints don't match the target platform.
+const (
+	// ConstraintsAnnotate tags such files in the report.
+	ConstraintsAnnotate = "annotate"
+	// ConstraintsExclude leaves such files out of the report.
+	ConstraintsExclude = "exclude"
+	// ConstraintsIgnore doesn't check build constraints.
+	ConstraintsIgnore = "ignore"
+)
+
+// Handling of the files of the profile outside of the root package.
+const (
+	// ExternalSkip leaves such files out of the report, with a warning.
+	ExternalSkip = "skip"
+	// ExternalInclude groups such files under a synthetic external directory of the root.
+	ExternalInclude = "include"
+)
+
+// Orders of the items listed in directory views.
+const (
+	// SortWorst lists the least covered items first.
+	SortWorst = "worst"
+	// SortBest lists the most covered items first.
+	SortBest = "best"
+	// SortName lists items by name.
+	SortName = "name"
+	// SortUncovered lists the items with the most uncovered statements first.
+	SortUncovered = "uncovered"
+)
+
+// Formats of the generated report.
+const (
+	// FormatHTML renders a browsable HTML report.
+	FormatHTML = "html"
+	// FormatJSON marshals the coverage tree into JSON.
+	FormatJSON = "json"
+	// FormatGitHubActions prints GitHub Actions workflow commands for uncovered blocks to stdout.
+	FormatGitHubActions = "github-actions"
+	// FormatMarkdown renders a compact summary table for pull request comments.
+	FormatMarkdown = "markdown"
+	// FormatTreemap renders a standalone SVG treemap of the files sized by statements.
+	FormatTreemap = "treemap"
+	// FormatSunburst renders a standalone HTML page of a zoomable sunburst of the directories and files sized by statements.
+	FormatSunburst = "sunburst"
+	// FormatCSV renders a row of statement counts and percentage per file, and a total row, for spreadsheets.
+	FormatCSV = "csv"
+	// FormatBadge renders an SVG badge of the total coverage.
+	FormatBadge = "badge"
+	// FormatText prints the uncovered lines of every file to stdout.
+	FormatText = "text"
+	// FormatTeamCity prints TeamCity service messages recording the total coverage as build statistics to stdout.
+	FormatTeamCity = "teamcity"
+)
+
+// outputFormats maps the extensions of output file names to the formats they imply.
+var outputFormats = map[string]string{
+	".html": FormatHTML,
+	".htm":  FormatHTML,
+	".json": FormatJSON,
+	".md":   FormatMarkdown,
+	".csv":  FormatCSV,
+	".svg":  FormatBadge,
+}
+
+// FormatFromOutput returns the format implied by the extension of the output file name, case insensitively.
+// Names without extension imply the HTML format, and names with an unknown one, such as cover.xml, no format.
+func FormatFromOutput(output string) string {
+	extension := filepath.Ext(output)
+	if extension == "" {
+		return FormatHTML
+	}
+	return outputFormats[strings.ToLower(extension)]
+}
+
+// FormatExtensions returns the extensions of the output file names implying the format, sorted.
+func FormatExtensions(format string) []string {
+	var extensions []string
+	for extension, f := range outputFormats {
+		if f == format {
+			extensions = append(extensions, extension)
+		}
+	}
+	sort.Strings(extensions)
+	return extensions
+}
+
+// Groupings of the flat coverage summary.
+const (
+	// GroupByTopDir groups the files by the first path segment below the initial directory.
+	GroupByTopDir = "top-dir"
+	// GroupByExt groups the files by extension.
+	GroupByExt = "ext"
+	// GroupByOwner groups the files by code owners, read from the CodeOwners file.
+	GroupByOwner = "owner"
+	// GroupByComponent groups the files by the "// component: name" tags of their packages.
+	GroupByComponent = "component"
+)
+
+// Styles of the badge.
+const (
+	// BadgeStyleCompact shows the percentage only.
+	BadgeStyleCompact = "compact"
+	// BadgeStyleFull also shows the covered and total statement counts.
+	BadgeStyleFull = "full"
+)
+
+// Layouts of the HTML report.
+const (
+	// LayoutDrilldown renders one view per directory, navigated through links.
+	LayoutDrilldown = "drilldown"
+	// LayoutTree additionally renders the whole hierarchy as a collapsible tree in a single view.
+	LayoutTree = "tree"
+	// LayoutFlat additionally lists every file with its full path in a single sorted view.
+	LayoutFlat = "flat"
+)
+
+// Styles of the paths of directories and files shown in the HTML report.
+const (
+	// PathStyleBase shows the base name of directories and files.
+	PathStyleBase = "base"
+	// PathStyleFull shows the package path of directories and files, relative to the root.
+	PathStyleFull = "full"
+)
+
+// PathRemap rewrites the leading path From of the files of a profile to To, such as the directory
+// of the sources in a CI container to their local directory.
+type PathRemap struct {
+	From string
+	To   string
 }
 
 // Cutlines represents the values for safe, warning and danger.
+// Excellent is an optional cutline above Safe, 0 meaning no excellent band.
 type Cutlines struct {
-	Safe    float64
-	Warning float64
+	Safe      float64
+	Warning   float64
+	Excellent float64
+}
+
+// Classes of the coverage percentages, named after the bands of the cutlines.
+const (
+	ClassDanger    = "danger"
+	ClassWarning   = "warning"
+	ClassSafe      = "safe"
+	ClassExcellent = "excellent"
+)
+
+// Classify returns the class of the band of the cutlines the coverage percentage falls in.
+// Percentages below Warning are in danger, below Safe in warning, and safe above,
+// up to Excellent when it is set. Nil cutlines classify nothing, returning an empty class.
+func (cutlines *Cutlines) Classify(percent float64) string {
+	if cutlines == nil {
+		return ""
+	}
+	if percent < cutlines.Warning {
+		return ClassDanger
+	} else if percent < cutlines.Safe {
+		return ClassWarning
+	} else if cutlines.Excellent > 0 && percent >= cutlines.Excellent {
+		return ClassExcellent
+	}
+	return ClassSafe
+}
+
+// LineBackground represents the background overlaid on lines of code: a color, as a hex code,
+// and its opacity between 0 and 1. An empty Color keeps the color of the coverage class of the line.
+type LineBackground struct {
+	Color   string
+	Opacity float64
+}
+
+// LineBackgrounds represents the backgrounds of the uncovered and covered lines of the HTML report.
+type LineBackgrounds struct {
+	Uncovered LineBackground
+	Covered   LineBackground
+}
+
+// Colors represents the colors of the danger, warning, safe and excellent coverage classes, as hex codes.
+// An empty Excellent keeps the default color of the excellent class.
+type Colors struct {
+	Danger    string
+	Warning   string
+	Safe      string
+	Excellent string
 }
@@ -0,0 +1,58 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCutlinesClassify(t *testing.T) {
+	t.Run("should classify percentages by band", func(t *testing.T) {
+		cutlines := &config.Cutlines{Safe: 70, Warning: 40}
+		assert.Equal(t, config.ClassDanger, cutlines.Classify(0))
+		assert.Equal(t, config.ClassDanger, cutlines.Classify(39.9))
+		assert.Equal(t, config.ClassWarning, cutlines.Classify(40))
+		assert.Equal(t, config.ClassWarning, cutlines.Classify(65))
+		assert.Equal(t, config.ClassSafe, cutlines.Classify(70))
+		assert.Equal(t, config.ClassSafe, cutlines.Classify(100))
+	})
+
+	t.Run("should classify percentages above the excellent cutline when set", func(t *testing.T) {
+		cutlines := &config.Cutlines{Safe: 70, Warning: 40, Excellent: 95}
+		assert.Equal(t, config.ClassSafe, cutlines.Classify(94.9))
+		assert.Equal(t, config.ClassExcellent, cutlines.Classify(95))
+	})
+
+	t.Run("should return an empty class without cutlines", func(t *testing.T) {
+		var cutlines *config.Cutlines
+		assert.Empty(t, cutlines.Classify(50))
+	})
+}
+
+func TestFormatFromOutput(t *testing.T) {
+	t.Run("should infer the format from the extension", func(t *testing.T) {
+		assert.Equal(t, config.FormatHTML, config.FormatFromOutput("cover.html"))
+		assert.Equal(t, config.FormatHTML, config.FormatFromOutput("cover.HTM"))
+		assert.Equal(t, config.FormatJSON, config.FormatFromOutput("out/cover.json"))
+		assert.Equal(t, config.FormatMarkdown, config.FormatFromOutput("summary.md"))
+		assert.Equal(t, config.FormatBadge, config.FormatFromOutput("badge.svg"))
+		assert.Equal(t, config.FormatCSV, config.FormatFromOutput("files.CSV"))
+	})
+
+	t.Run("should fall back on html without extension", func(t *testing.T) {
+		assert.Equal(t, config.FormatHTML, config.FormatFromOutput("cover"))
+		assert.Equal(t, config.FormatHTML, config.FormatFromOutput("out.d/cover"))
+	})
+
+	t.Run("should return no format for an unknown extension", func(t *testing.T) {
+		assert.Empty(t, config.FormatFromOutput("cover.xml"))
+		assert.Empty(t, config.FormatFromOutput("lcov.info"))
+	})
+}
+
+func TestFormatExtensions(t *testing.T) {
+	assert.Equal(t, []string{".htm", ".html"}, config.FormatExtensions(config.FormatHTML))
+	assert.Equal(t, []string{".csv"}, config.FormatExtensions(config.FormatCSV))
+	assert.Empty(t, config.FormatExtensions(config.FormatText))
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the configuration file looked up in the working directory.
+const DefaultFile = ".covreport.yaml"
+
+// File represents the settings of a configuration file, mirroring the command-line flags.
+// Empty values are left unset.
+type File struct {
+	Input    string   `yaml:"input"`
+	Output   string   `yaml:"output"`
+	Cutlines string   `yaml:"cutlines"`
+	Root     string   `yaml:"root"`
+	Ignores  []string `yaml:"ignores"`
+}
+
+// LoadFile reads the configuration file with the given name. Unknown keys are rejected.
+func LoadFile(filename string) (*File, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %q: %v", filename, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	file := &File{}
+	if err := dec.Decode(file); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("can't parse %q: %v", filename, err)
+	}
+	return file, nil
+}
+
+// EnvPrefix is the prefix of the environment variables mirroring the settings of the configuration file,
+// such as COVREPORT_INPUT.
+const EnvPrefix = "COVREPORT_"
+
+// LoadEnv reads the settings of the environment variables, COVREPORT_INPUT, COVREPORT_OUTPUT, COVREPORT_CUTLINES,
+// COVREPORT_ROOT and COVREPORT_IGNORES, the latter being comma separated.
+// Unset and empty variables are left unset.
+func LoadEnv() *File {
+	file := &File{
+		Input:    os.Getenv(EnvPrefix + "INPUT"),
+		Output:   os.Getenv(EnvPrefix + "OUTPUT"),
+		Cutlines: os.Getenv(EnvPrefix + "CUTLINES"),
+		Root:     os.Getenv(EnvPrefix + "ROOT"),
+	}
+	if ignores := os.Getenv(EnvPrefix + "IGNORES"); ignores != "" {
+		file.Ignores = strings.Split(ignores, ",")
+	}
+	return file
+}
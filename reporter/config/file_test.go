@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFile(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		filename := filepath.Join(t.TempDir(), config.DefaultFile)
+		assert.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+		return filename
+	}
+
+	t.Run("should return error when cannot read file", func(t *testing.T) {
+		_, err := config.LoadFile("not-exist.yaml")
+		assert.ErrorContains(t, err, `can't read "not-exist.yaml"`)
+	})
+
+	t.Run("should read all keys", func(t *testing.T) {
+		file, err := config.LoadFile(write(t, "input: a.prof\noutput: a.html\ncutlines: 80,50\nroot: app\nignores:\n  - app/gen\n  - app/mock\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, &config.File{
+			Input:    "a.prof",
+			Output:   "a.html",
+			Cutlines: "80,50",
+			Root:     "app",
+			Ignores:  []string{"app/gen", "app/mock"},
+		}, file)
+	})
+
+	t.Run("should accept empty file", func(t *testing.T) {
+		file, err := config.LoadFile(write(t, ""))
+		assert.NoError(t, err)
+		assert.Equal(t, &config.File{}, file)
+	})
+
+	t.Run("should return error with unknown key", func(t *testing.T) {
+		_, err := config.LoadFile(write(t, "inputs: a.prof\n"))
+		assert.ErrorContains(t, err, "can't parse")
+		assert.ErrorContains(t, err, "field inputs not found")
+	})
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Run("should read all variables", func(t *testing.T) {
+		t.Setenv("COVREPORT_INPUT", "a.prof")
+		t.Setenv("COVREPORT_OUTPUT", "a.html")
+		t.Setenv("COVREPORT_CUTLINES", "80,50")
+		t.Setenv("COVREPORT_ROOT", "app")
+		t.Setenv("COVREPORT_IGNORES", "app/gen,app/mock")
+		assert.Equal(t, &config.File{
+			Input:    "a.prof",
+			Output:   "a.html",
+			Cutlines: "80,50",
+			Root:     "app",
+			Ignores:  []string{"app/gen", "app/mock"},
+		}, config.LoadEnv())
+	})
+
+	t.Run("should leave unset variables unset", func(t *testing.T) {
+		t.Setenv("COVREPORT_INPUT", "")
+		t.Setenv("COVREPORT_IGNORES", "")
+		file := config.LoadEnv()
+		assert.Empty(t, file.Input)
+		assert.Nil(t, file.Ignores)
+	})
+}
@@ -0,0 +1,59 @@
+package reporter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+// Handler returns an http.Handler serving the coverage report of the given configuration.
+// The profile is parsed and the reports are rendered once, when the handler is created:
+//
+//	/               the HTML report
+//	/coverage.json  the JSON report
+//	/badge.svg      the SVG badge of the total coverage
+//
+// The warnings of parsing the profile go to cfg.Stderr, as for ReportTo.
+func Handler(cfg *config.Config) (http.Handler, error) {
+	if cfg.Quiet && cfg.Verbose {
+		return nil, errors.New("quiet and verbose modes are mutually exclusive")
+	}
+	gp := newProject(cfg)
+	if err := parseProject(gp, cfg); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	for _, endpoint := range []struct {
+		pattern     string
+		contentType string
+		report      func(io.Writer) error
+	}{
+		{"/", "text/html; charset=utf-8", gp.Report},
+		{"/coverage.json", "application/json", gp.ReportJSON},
+		{"/badge.svg", "image/svg+xml", gp.ReportBadge},
+	} {
+		var buf bytes.Buffer
+		if err := endpoint.report(&buf); err != nil {
+			return nil, err
+		}
+		mux.Handle(endpoint.pattern, serveContent(endpoint.pattern, endpoint.contentType, buf.Bytes()))
+	}
+	return mux, nil
+}
+
+// serveContent returns an http.Handler serving the content with the given content type at the path.
+// Other paths, which the "/" pattern matches as well, are not found.
+func serveContent(path, contentType string, content []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(content)
+	})
+}
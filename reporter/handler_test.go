@@ -0,0 +1,114 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter"
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	temp := t.TempDir()
+	source := filepath.Join(temp, "x.go")
+	assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+	input := filepath.Join(temp, "cover.prof")
+	assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+
+	cfg := &config.Config{
+		Input:    input,
+		Root:     temp,
+		Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+	}
+
+	t.Run("should return error when the profile can't be parsed", func(t *testing.T) {
+		_, err := reporter.Handler(&config.Config{Input: filepath.Join(temp, "not-exist.prof")})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when both quiet and verbose", func(t *testing.T) {
+		_, err := reporter.Handler(&config.Config{Input: input, Quiet: true, Verbose: true})
+		assert.EqualError(t, err, "quiet and verbose modes are mutually exclusive")
+	})
+
+	t.Run("should print the warnings to the stderr of the configuration", func(t *testing.T) {
+		malformed := filepath.Join(temp, "malformed.prof")
+		assert.NoError(t, os.WriteFile(malformed, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\ngarbage\n", source)), 0o644))
+		var stderr bytes.Buffer
+		_, err := reporter.Handler(&config.Config{
+			Input:    malformed,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Lenient:  true,
+			Stderr:   &stderr,
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, stderr.String(), "warning: skipping malformed profile line 3")
+
+		stderr.Reset()
+		_, err = reporter.Handler(&config.Config{
+			Input:    malformed,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Lenient:  true,
+			Quiet:    true,
+			Stderr:   &stderr,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, stderr.String())
+	})
+
+	handler, err := reporter.Handler(cfg)
+	assert.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp, string(body)
+	}
+
+	t.Run("should serve the html report", func(t *testing.T) {
+		resp, body := get("/")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Contains(t, body, "<title>Go Coverage Report</title>")
+	})
+
+	t.Run("should serve the json report", func(t *testing.T) {
+		resp, body := get("/coverage.json")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		var report struct {
+			Root struct {
+				Statements int `json:"statements"`
+			} `json:"root"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(body), &report))
+		assert.Equal(t, 1, report.Root.Statements)
+	})
+
+	t.Run("should serve the badge", func(t *testing.T) {
+		resp, body := get("/badge.svg")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/svg+xml", resp.Header.Get("Content-Type"))
+		assert.Contains(t, body, "<title>coverage: 100.0%</title>")
+	})
+
+	t.Run("should not find other paths", func(t *testing.T) {
+		resp, _ := get("/other")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+const (
+	// badgeLabel is the text of the left part of the badge.
+	badgeLabel = "coverage"
+	// badgeCharWidth is the approximate width of a character of the badge font, in pixels.
+	badgeCharWidth = 7
+	// badgeMargin is the horizontal space around the text of each part of the badge, in pixels.
+	badgeMargin = 10
+)
+
+// ReportBadge writes an SVG badge showing the total coverage of the GoProject to the provided io.Writer.
+// The value is colored by the cutlines, like the items of the HTML report.
+// In the full style, the value also shows the covered and total statement counts, like "73.4% (1234/1680)".
+func (gp *GoProject) ReportBadge(wr io.Writer) error {
+	data := NewTemplateListItemData(gp.Root().GoListItem, gp.Cutlines, gp.Precision)
+	value := data.Percent
+	switch gp.BadgeStyle {
+	case "", config.BadgeStyleCompact:
+	case config.BadgeStyleFull:
+		value += fmt.Sprintf(" (%d/%d)", data.NumStmtCovered, data.NumStmt)
+	default:
+		return fmt.Errorf("unknown badge style %q", gp.BadgeStyle)
+	}
+
+	labelWidth := badgeTextWidth(badgeLabel)
+	valueWidth := badgeTextWidth(value)
+	width := labelWidth + valueWidth
+
+	_, err := fmt.Fprintf(wr, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+<title>%s: %s</title>
+<rect width="%d" height="20" fill="#555555"/>
+<rect x="%d" width="%d" height="20" fill="%s"/>
+<g fill="#ffffff" text-anchor="middle" font-family="Verdana, DejaVu Sans, sans-serif" font-size="11">
+<text x="%d" y="14">%s</text>
+<text x="%d" y="14">%s</text>
+</g>
+</svg>
+`,
+		width, badgeLabel, value,
+		badgeLabel, value,
+		labelWidth,
+		labelWidth, valueWidth, gp.classColor(data.ClassName),
+		labelWidth/2, badgeLabel,
+		labelWidth+valueWidth/2, value,
+	)
+	return err
+}
+
+// badgeTextWidth returns the width of a part of the badge holding the text.
+func badgeTextWidth(text string) int {
+	return len(text)*badgeCharWidth + 2*badgeMargin
+}
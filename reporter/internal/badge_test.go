@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportBadge(t *testing.T) {
+	newProject := func(covered, total int) *GoProject {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Root().AddFile(&GoFile{GoListItem: &GoListItem{StmtCount: total, StmtCoveredCount: covered}})
+		gp.Root().Aggregate()
+		return gp
+	}
+
+	tests := []struct {
+		name      string
+		covered   int
+		total     int
+		wantValue string
+		wantColor string
+	}{
+		{"safe", 8, 10, "80.0%", treemapColors["safe"]},
+		{"warning", 5, 10, "50.0%", treemapColors["warning"]},
+		{"danger", 1, 10, "10.0%", treemapColors["danger"]},
+		{"no statements", 0, 0, "N/A", treemapColors[""]},
+	}
+	for _, tt := range tests {
+		t.Run("should render "+tt.name+" coverage", func(t *testing.T) {
+			var buf strings.Builder
+			assert.NoError(t, newProject(tt.covered, tt.total).ReportBadge(&buf))
+
+			var svg struct {
+				Title string `xml:"title"`
+				Rects []struct {
+					Fill string `xml:"fill,attr"`
+				} `xml:"rect"`
+			}
+			assert.NoError(t, xml.Unmarshal([]byte(buf.String()), &svg))
+			assert.Equal(t, "coverage: "+tt.wantValue, svg.Title)
+			assert.Len(t, svg.Rects, 2)
+			assert.Equal(t, tt.wantColor, svg.Rects[1].Fill)
+		})
+	}
+
+	t.Run("should use the configured colors", func(t *testing.T) {
+		gp := newProject(8, 10)
+		gp.Colors = &config.Colors{Danger: "#d55e00", Warning: "#f0e442", Safe: "#0072b2"}
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportBadge(&buf))
+		assert.Contains(t, buf.String(), `fill="#0072b2"`)
+	})
+	t.Run("should show the statement counts in the full style", func(t *testing.T) {
+		gp := newProject(1234, 1680)
+		gp.BadgeStyle = config.BadgeStyleFull
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportBadge(&buf))
+		assert.Contains(t, buf.String(), "<title>coverage: 73.5% (1234/1680)</title>")
+		assert.Contains(t, buf.String(), `width="`+strconv.Itoa(badgeTextWidth(badgeLabel)+badgeTextWidth("73.5% (1234/1680)"))+`"`)
+	})
+
+	t.Run("should return error with unknown style", func(t *testing.T) {
+		gp := newProject(1, 2)
+		gp.BadgeStyle = "flat"
+		assert.EqualError(t, gp.ReportBadge(&strings.Builder{}), `unknown badge style "flat"`)
+	})
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/cover"
+)
+
+// countBranches approximates the branch coverage of the parsed file from its statement profile blocks.
+// The branches are the explicit arms of the if, switch and select statements: the bodies of if statements
+// and their else branches, and the case clauses. An arm is covered when a block within it ran.
+// The else if of a chain isn't an arm of its own: the chain has an arm per body and its final else.
+// Implicit arms, like the missing else of an if statement or the missing default of a switch statement,
+// aren't counted, as statement blocks can't tell whether they were taken. Arms without blocks aren't counted either.
+func countBranches(fset *token.FileSet, parsed *ast.File, blocks []cover.ProfileBlock) (count, covered int) {
+	arm := func(from, to token.Pos) {
+		start, end := fset.Position(from), fset.Position(to)
+		found, ran := false, false
+		for _, b := range blocks {
+			if b.StartLine < start.Line || (b.StartLine == start.Line && b.StartCol < start.Column) {
+				// Block starts before the arm.
+				continue
+			}
+			if b.StartLine > end.Line || (b.StartLine == end.Line && b.StartCol > end.Column) {
+				// Block starts after the arm. The block of an empty case clause starts at its very end.
+				continue
+			}
+			found = true
+			ran = ran || b.Count > 0
+		}
+		if found {
+			count++
+			if ran {
+				covered++
+			}
+		}
+	}
+	clauses := func(body *ast.BlockStmt) {
+		for _, stmt := range body.List {
+			switch clause := stmt.(type) {
+			case *ast.CaseClause:
+				arm(clause.Colon, clause.End())
+			case *ast.CommClause:
+				arm(clause.Colon, clause.End())
+			}
+		}
+	}
+
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		switch stmt := node.(type) {
+		case *ast.IfStmt:
+			arm(stmt.Body.Lbrace, stmt.Body.End())
+			if _, elseIf := stmt.Else.(*ast.IfStmt); stmt.Else != nil && !elseIf {
+				arm(stmt.Else.Pos(), stmt.Else.End())
+			}
+		case *ast.SwitchStmt:
+			clauses(stmt.Body)
+		case *ast.TypeSwitchStmt:
+			clauses(stmt.Body)
+		case *ast.SelectStmt:
+			clauses(stmt.Body)
+		}
+		return true
+	})
+	return count, covered
+}
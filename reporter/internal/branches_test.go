@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestCountBranches(t *testing.T) {
+	src := []byte(`package x
+
+func f(a int, c chan int) {
+	if a > 0 {
+		a++
+	} else if a < 0 {
+		a--
+	} else {
+		a = 1
+	}
+	switch a {
+	case 1:
+		a++
+	case 2:
+		a--
+	default:
+	}
+	select {
+	case <-c:
+	default:
+		a++
+	}
+	if a == 3 {
+		a = 4
+	}
+}
+`)
+	// The blocks of the profile of the go tool for f(1, make(chan int)).
+	file := &GoFile{GoListItem: NewGoListItem("x.go"), ABSPath: "x.go", Profile: []cover.ProfileBlock{
+		{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 11, NumStmt: 1, Count: 1},
+		{StartLine: 5, StartCol: 3, EndLine: 6, EndCol: 1, NumStmt: 1, Count: 1},
+		{StartLine: 6, StartCol: 9, EndLine: 6, EndCol: 18, NumStmt: 1, Count: 0},
+		{StartLine: 7, StartCol: 3, EndLine: 8, EndCol: 1, NumStmt: 1, Count: 0},
+		{StartLine: 9, StartCol: 3, EndLine: 10, EndCol: 1, NumStmt: 1, Count: 0},
+		{StartLine: 11, StartCol: 2, EndLine: 11, EndCol: 11, NumStmt: 1, Count: 1},
+		{StartLine: 13, StartCol: 3, EndLine: 13, EndCol: 6, NumStmt: 1, Count: 0},
+		{StartLine: 15, StartCol: 3, EndLine: 15, EndCol: 6, NumStmt: 1, Count: 1},
+		{StartLine: 16, StartCol: 10, EndLine: 16, EndCol: 10, NumStmt: 0, Count: 0},
+		{StartLine: 18, StartCol: 2, EndLine: 18, EndCol: 9, NumStmt: 1, Count: 1},
+		{StartLine: 19, StartCol: 11, EndLine: 19, EndCol: 11, NumStmt: 0, Count: 0},
+		{StartLine: 21, StartCol: 3, EndLine: 21, EndCol: 6, NumStmt: 1, Count: 1},
+		{StartLine: 23, StartCol: 2, EndLine: 23, EndCol: 12, NumStmt: 1, Count: 1},
+		{StartLine: 24, StartCol: 3, EndLine: 25, EndCol: 1, NumStmt: 1, Count: 0},
+	}}
+
+	t.Run("should count the explicit arms with blocks and the ones which ran", func(t *testing.T) {
+		// The if/else if/else chain has 3 arms, the switch 3 and the select 2, empty clauses having empty blocks,
+		// and the last if 1 as its implicit else isn't counted.
+		file.parseFuncs(src)
+		assert.Equal(t, 9, file.BranchCount)
+		assert.Equal(t, 3, file.BranchCoveredCount)
+		assert.InDelta(t, 33.3, file.BranchPercent(), 0.1)
+	})
+
+	t.Run("should count an arm per body of an else if chain", func(t *testing.T) {
+		src := []byte(`package x
+
+func g(a int) {
+	if a > 0 {
+		a++
+	} else if a < 0 {
+		a--
+	} else if a == 0 {
+		a = 1
+	}
+}
+`)
+		// The blocks of the profile of the go tool for g(0).
+		file := &GoFile{GoListItem: NewGoListItem("x.go"), ABSPath: "x.go", Profile: []cover.ProfileBlock{
+			{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 11, NumStmt: 1, Count: 1},
+			{StartLine: 5, StartCol: 3, EndLine: 6, EndCol: 1, NumStmt: 1, Count: 0},
+			{StartLine: 6, StartCol: 9, EndLine: 6, EndCol: 18, NumStmt: 1, Count: 1},
+			{StartLine: 7, StartCol: 3, EndLine: 8, EndCol: 1, NumStmt: 1, Count: 0},
+			{StartLine: 8, StartCol: 9, EndLine: 8, EndCol: 19, NumStmt: 1, Count: 1},
+			{StartLine: 9, StartCol: 3, EndLine: 10, EndCol: 1, NumStmt: 1, Count: 1},
+		}}
+		file.parseFuncs(src)
+		assert.Equal(t, 3, file.BranchCount)
+		assert.Equal(t, 1, file.BranchCoveredCount)
+	})
+
+	t.Run("should add up the branches of the files in directories", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.SafeDir("a").AddFile(file)
+		gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/b/y.go", BranchCount: 3, BranchCoveredCount: 3}})
+		gp.Root().Aggregate()
+		assert.Equal(t, 12, gp.Root().BranchCount)
+		assert.Equal(t, 6, gp.Root().BranchCoveredCount)
+	})
+
+	t.Run("should have no branch without profile blocks", func(t *testing.T) {
+		empty := &GoFile{GoListItem: NewGoListItem("x.go"), ABSPath: "x.go"}
+		empty.parseFuncs(src)
+		assert.Zero(t, empty.BranchCount)
+		assert.Zero(t, empty.BranchPercent())
+	})
+}
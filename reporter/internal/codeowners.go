@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CodeOwners matches files and directories against the rules of a CODEOWNERS file to find their owners.
+// Patterns follow the syntax of .gitignore files relative to the root of the repository, without negation,
+// and the last matching rule takes precedence.
+type CodeOwners struct {
+	// Root is the absolute directory of the repository the patterns are relative to.
+	Root  string
+	rules []*codeOwnersRule
+}
+
+// codeOwnersRule is a line of a CODEOWNERS file.
+type codeOwnersRule struct {
+	pattern *codeOwnersPattern
+	// filesOnly only matches the files directly in a directory, for patterns ending with "/*" like "docs/*".
+	filesOnly bool
+	// owners are the users, teams or emails owning the paths matching the pattern, none leaving them unowned.
+	owners []string
+}
+
+// LoadCodeOwners reads the given CODEOWNERS file, relative to the root of the git repository holding it,
+// or to the directory holding it outside of a repository, its parent for the .github and docs directories.
+func LoadCodeOwners(filename string) (*CodeOwners, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can't read codeowners %q: %v", filename, err)
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(abs)
+	if base := filepath.Base(dir); base == ".github" || base == "docs" {
+		dir = filepath.Dir(dir)
+	}
+	return &CodeOwners{Root: findRepoRoot(dir), rules: parseCodeOwners(data)}, nil
+}
+
+// parseCodeOwners parses the rules of a CODEOWNERS file, skipping blank lines, comments and negated patterns,
+// which CODEOWNERS doesn't support.
+func parseCodeOwners(data []byte) []*codeOwnersRule {
+	var rules []*codeOwnersRule
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+		pattern := parseCodeOwnersPattern(fields[0])
+		if pattern == nil {
+			continue
+		}
+		rule := &codeOwnersRule{pattern: pattern, filesOnly: strings.HasSuffix(fields[0], "/*")}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			rule.owners = append(rule.owners, owner)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// findRepoRoot returns the root of the git repository holding the absolute directory, found by walking up
+// to a .git entry, or the directory itself outside of a repository.
+func findRepoRoot(dir string) string {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// codeOwnersPattern is the pattern of a CODEOWNERS rule.
+type codeOwnersPattern struct {
+	re *regexp.Regexp
+	// dirOnly only matches directories.
+	dirOnly bool
+}
+
+// parseCodeOwnersPattern parses the pattern of a CODEOWNERS rule, returning nil for invalid ones.
+// A trailing "/" only matches directories, and a "/" at the start or in the middle anchors the pattern to the root.
+// Otherwise the pattern matches at any depth.
+func parseCodeOwnersPattern(line string) *codeOwnersPattern {
+	pattern := &codeOwnersPattern{}
+	if strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil
+	}
+	pattern.re = re
+	return pattern
+}
+
+// globToRegexp translates a CODEOWNERS glob into a regular expression.
+// "*" and "?" don't match "/", while "**" matches any number of directories: "**/" leading, "/**/" in the middle,
+// and "/**" trailing, matching everything inside.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**") && i+2 == len(glob) && (i == 0 || glob[i-1] == '/'):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// Owners returns the owners of the file or directory of the given path, those of the last matching rule.
+// It returns nil when no rule matches, for paths outside of the repository, and for a nil CodeOwners.
+func (co *CodeOwners) Owners(name string, isDir bool) []string {
+	if co == nil {
+		return nil
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(co.Root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	// The root matches the patterns matching any path, such as "*".
+	parts := []string{""}
+	if rel != "." {
+		parts = strings.Split(filepath.ToSlash(rel), "/")
+	}
+
+	var owners []string
+	for _, rule := range co.rules {
+		if rule.match(parts, isDir) {
+			owners = rule.owners
+		}
+	}
+	return owners
+}
+
+// match reports whether the rule matches the path of the given parts, or one of the directories above it.
+func (rule *codeOwnersRule) match(parts []string, isDir bool) bool {
+	for i := range parts {
+		last := i == len(parts)-1
+		if rule.filesOnly && (!last || isDir) || rule.pattern.dirOnly && !isDir && last {
+			continue
+		}
+		if rule.pattern.re.MatchString(strings.Join(parts[:i+1], "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignOwners sets the owners of the directories and files of the GoProject from its CodeOwners.
+func (gp *GoProject) assignOwners() {
+	for _, dir := range gp.Root().AllDirs() {
+		if sourceDir := dir.sourceDir(); sourceDir != "" {
+			dir.Owners = gp.CodeOwners.Owners(sourceDir, true)
+		}
+		for _, file := range dir.Files {
+			file.Owners = gp.CodeOwners.Owners(file.ABSPath, false)
+		}
+	}
+}
+
+// sourceDir returns the directory of the sources of the GoDir, found from the path of the first file under it,
+// or an empty string when it holds no file.
+func (dir *GoDir) sourceDir() string {
+	files := dir.AllFiles()
+	if len(files) == 0 {
+		return ""
+	}
+	file := files[0]
+	fileDir := path.Dir(file.RelPkgPath)
+	var depth int
+	switch {
+	case fileDir == dir.RelPkgPath:
+	case dir.RelPkgPath == ".":
+		depth = strings.Count(fileDir, "/") + 1
+	default:
+		depth = strings.Count(strings.TrimPrefix(fileDir, dir.RelPkgPath), "/")
+	}
+	sourceDir := filepath.Dir(file.ABSPath)
+	for ; depth > 0; depth-- {
+		sourceDir = filepath.Dir(sourceDir)
+	}
+	return sourceDir
+}
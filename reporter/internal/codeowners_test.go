@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwners(t *testing.T) {
+	temp := t.TempDir()
+	writeFile := func(name, content string) string {
+		name = filepath.Join(temp, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		assert.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+		return name
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Join(temp, ".git"), 0o755))
+	filename := writeFile(".github/CODEOWNERS", `# default owners
+*            @org/everyone
+*.pb.go      @org/api   # generated
+/internal/   @org/core
+docs/*       docs@example.com
+apps/        @org/apps @alice
+/internal/legacy/
+!/internal/keep.go @org/nobody
+`)
+
+	co, err := LoadCodeOwners(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, temp, co.Root)
+
+	tests := []struct {
+		Path   string
+		IsDir  bool
+		Owners []string
+	}{
+		{"main.go", false, []string{"@org/everyone"}},
+		{"", true, []string{"@org/everyone"}},
+		{"api/x.pb.go", false, []string{"@org/api"}},
+		{"internal", true, []string{"@org/core"}},
+		{"internal/x.go", false, []string{"@org/core"}},
+		{"internal/x.pb.go", false, []string{"@org/core"}},
+		{"internal/keep.go", false, []string{"@org/core"}},
+		{"internal/legacy/x.go", false, nil},
+		{"docs/x.md", false, []string{"docs@example.com"}},
+		{"docs/sub/x.md", false, []string{"@org/everyone"}},
+		{"docs/sub", true, []string{"@org/everyone"}},
+		{"cmd/apps/x.go", false, []string{"@org/apps", "@alice"}},
+		{"cmd/apps", false, []string{"@org/everyone"}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.Owners, co.Owners(filepath.Join(temp, filepath.FromSlash(test.Path)), test.IsDir), test.Path)
+	}
+
+	t.Run("should not own paths outside of the repository", func(t *testing.T) {
+		assert.Nil(t, co.Owners(filepath.Dir(temp), true))
+	})
+
+	t.Run("should not own anything when nil", func(t *testing.T) {
+		var co *CodeOwners
+		assert.Nil(t, co.Owners(filepath.Join(temp, "main.go"), false))
+	})
+
+	t.Run("should return error when the file can't be read", func(t *testing.T) {
+		_, err := LoadCodeOwners(filepath.Join(temp, "CODEOWNERS"))
+		assert.ErrorContains(t, err, "can't read codeowners")
+	})
+}
+
+func TestAssignOwners(t *testing.T) {
+	temp := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(temp, "a", "b"), 0o755))
+	filename := filepath.Join(temp, "CODEOWNERS")
+	assert.NoError(t, os.WriteFile(filename, []byte("* @org/all\n/a/ @org/a\n/a/b/x.go @bob\n"), 0o644))
+	co, err := LoadCodeOwners(filename)
+	assert.NoError(t, err)
+
+	gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+	gp.CodeOwners = co
+	gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: NewGoListItem("a/b/x.go"), ABSPath: filepath.Join(temp, "a", "b", "x.go")})
+	gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: NewGoListItem("a/b/y.go"), ABSPath: filepath.Join(temp, "a", "b", "y.go")})
+	gp.assignOwners()
+
+	assert.Equal(t, []string{"@org/all"}, gp.Root().Owners)
+	assert.Equal(t, []string{"@org/a"}, gp.SafeDir("a").Owners)
+	assert.Equal(t, []string{"@org/a"}, gp.SafeDir("a/b").Owners)
+	assert.Equal(t, []string{"@bob"}, gp.SafeDir("a/b").Files[0].Owners)
+	assert.Equal(t, []string{"@org/a"}, gp.SafeDir("a/b").Files[1].Owners)
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	assert.Equal(t, `[^/]*\.go`, globToRegexp("*.go"))
+	assert.Equal(t, `(?:.*/)?a/[^/]`, globToRegexp("**/a/?"))
+	assert.Equal(t, `a/(?:.*/)?b`, globToRegexp("a/**/b"))
+	assert.Equal(t, `a/.*`, globToRegexp("a/**"))
+	assert.Equal(t, `[^a-c]x`, globToRegexp("[!a-c]x"))
+}
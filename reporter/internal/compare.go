@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+)
+
+// Compare compares the coverage of the GoProject with a baseline parsed from another profile.
+// Directories and files are matched on their relative package path: the matching item of the
+// baseline becomes their Base, files without one are flagged as new, and the files of the
+// baseline without a match are listed in Removed.
+func (gp *GoProject) Compare(base *GoProject) {
+	baseItems := make(map[string]*GoListItem)
+	for _, dir := range base.Root().AllDirs() {
+		baseItems[dir.RelPkgPath] = dir.GoListItem
+	}
+	baseFiles := base.Root().AllFiles()
+	for _, file := range baseFiles {
+		baseItems[file.RelPkgPath] = file.GoListItem
+	}
+
+	items := make(map[string]bool)
+	for _, dir := range gp.Root().AllDirs() {
+		dir.Base = baseItems[dir.RelPkgPath]
+		dir.New = dir.Base == nil
+		items[dir.RelPkgPath] = true
+	}
+	for _, file := range gp.Root().AllFiles() {
+		file.Base = baseItems[file.RelPkgPath]
+		file.New = file.Base == nil
+		items[file.RelPkgPath] = true
+	}
+
+	gp.Removed = nil
+	for _, file := range baseFiles {
+		if !items[file.RelPkgPath] {
+			gp.Removed = append(gp.Removed, file.RelPkgPath)
+		}
+	}
+	sort.Strings(gp.Removed)
+	gp.Compared = true
+}
+
+// ParseBaseline parses the input profiles of a baseline to compare another GoProject with, see Compare.
+// Unlike Parse, the files are keyed by their path in the profiles without being looked up on disk nor read,
+// as a baseline names the packages and files deleted since, and its sources are expected to be out of date.
+// Malformed profile lines are skipped with a warning.
+func (gp *GoProject) ParseBaseline(input string) error {
+	profiles, skipped, err := ParseProfiles(input, true, gp.Logger)
+	if err != nil {
+		return err
+	}
+	if skipped > 0 {
+		gp.Logger.Warnf("skipped %d malformed lines of the baseline profile %q", skipped, input)
+	}
+	for _, profile := range profiles {
+		fileName := remapPath(normalizePath(profile.FileName), gp.Remaps)
+		if gp.skipFile(fileName) {
+			continue
+		}
+		relPath, ok := gp.relPath(fileName)
+		if !ok {
+			continue
+		}
+		dir := gp.SafeDir(path.Dir(relPath))
+		var file *GoFile
+		for _, f := range dir.Files {
+			if f.RelPkgPath == relPath {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			file = &GoFile{GoListItem: NewGoListItem(relPath)}
+			file.Unscored = gp.unscored(fileName)
+			dir.AddFile(file)
+		}
+		file.MergeBlocks(profile.Blocks, profile.Mode)
+	}
+	gp.Logger.Debugf("parsed %d baseline profiles from %q", len(profiles), input)
+	gp.Root().Aggregate()
+	return nil
+}
+
+// DeltaPercent returns the difference between the coverage percentage of the item and of its Base.
+// It reports false when there is no baseline to compare with, or when either side has no statements.
+func (item *GoListItem) DeltaPercent() (float64, bool) {
+	if item.Base == nil || item.StmtCount == 0 || item.Base.StmtCount == 0 {
+		return 0, false
+	}
+	return item.Percent() - item.Base.Percent(), true
+}
+
+// FormatDelta formats the change of coverage of the item with the given number of decimal places,
+// such as "+2.3%", along with "up", "down" or "" for an unchanged coverage.
+// Both are empty when the item has no delta.
+func FormatDelta(item *GoListItem, precision int) (string, string) {
+	delta, ok := item.DeltaPercent()
+	if !ok {
+		return "", ""
+	}
+	// Deltas rounding to zero are shown as unchanged rather than as "-0.0%".
+	if math.Abs(delta) < 0.5*math.Pow10(-precision) {
+		return fmt.Sprintf("%+.*f%%", precision, 0.0), ""
+	}
+	if delta > 0 {
+		return fmt.Sprintf("%+.*f%%", precision, delta), "up"
+	}
+	return fmt.Sprintf("%+.*f%%", precision, delta), "down"
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	newProject := func(files map[string][2]int) *GoProject {
+		gp := NewGoProject("app", nil, nil)
+		for path, counts := range files {
+			file := &GoFile{GoListItem: NewGoListItem(path)}
+			file.StmtCoveredCount, file.StmtCount = counts[0], counts[1]
+			gp.SafeDir(filepath.Dir(path)).AddFile(file)
+		}
+		gp.Root().Aggregate()
+		return gp
+	}
+
+	base := newProject(map[string][2]int{
+		"app/a/x.go":   {5, 10},
+		"app/a/old.go": {1, 10},
+		"app/b/y.go":   {8, 10},
+	})
+	gp := newProject(map[string][2]int{
+		"app/a/x.go":   {7, 10},
+		"app/b/y.go":   {7, 10},
+		"app/c/new.go": {3, 10},
+	})
+	gp.Compare(base)
+
+	files := make(map[string]*GoFile)
+	for _, file := range gp.Root().AllFiles() {
+		files[file.RelPkgPath] = file
+	}
+
+	t.Run("should flag new and removed files", func(t *testing.T) {
+		assert.True(t, gp.Compared)
+		assert.Equal(t, []string{"app/a/old.go"}, gp.Removed)
+		assert.False(t, files["app/a/x.go"].New)
+		assert.False(t, files["app/b/y.go"].New)
+		assert.True(t, files["app/c/new.go"].New)
+		assert.True(t, gp.SafeDir("app/c").New)
+	})
+
+	t.Run("should format deltas of files and directories", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			item      *GoListItem
+			wantDelta string
+			wantClass string
+		}{
+			{"root", gp.Root().GoListItem, "+10.0%", "up"},
+			{"improved dir", gp.SafeDir("app/a").GoListItem, "+40.0%", "up"},
+			{"worsened dir", gp.SafeDir("app/b").GoListItem, "-10.0%", "down"},
+			{"improved file", files["app/a/x.go"].GoListItem, "+20.0%", "up"},
+			{"new file", files["app/c/new.go"].GoListItem, "", ""},
+		}
+		for _, tt := range tests {
+			delta, class := FormatDelta(tt.item, 1)
+			assert.Equal(t, tt.wantDelta, delta, tt.name)
+			assert.Equal(t, tt.wantClass, class, tt.name)
+		}
+	})
+
+	t.Run("should format unchanged coverage without sign of the rounding", func(t *testing.T) {
+		item := &GoListItem{StmtCount: 3000, StmtCoveredCount: 1000, Base: &GoListItem{StmtCount: 3001, StmtCoveredCount: 1000}}
+		delta, class := FormatDelta(item, 1)
+		assert.Equal(t, "+0.0%", delta)
+		assert.Equal(t, "", class)
+	})
+
+	t.Run("should not format delta without statements", func(t *testing.T) {
+		delta, _ := FormatDelta(&GoListItem{Base: &GoListItem{StmtCount: 10}}, 1)
+		assert.Equal(t, "", delta)
+	})
+}
+
+func TestGoProject_ParseBaseline(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "base.prof")
+	assert.NoError(t, os.WriteFile(input, []byte("mode: count\n"+
+		"example.com/app/gone/x.go:1.1,2.1 2 1\n"+
+		"example.com/app/gone/x.go:3.1,4.1 2 0\n"+
+		"example.com/app/gone/x_test.go:1.1,2.1 1 1\n"+
+		"example.com/other/y.go:1.1,2.1 1 1\n"+
+		"not a profile line\n"), 0o644))
+
+	gp := NewGoProject("example.com/app", nil, nil)
+	assert.NoError(t, gp.ParseBaseline(input))
+
+	files := gp.Root().AllFiles()
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "example.com/app/gone/x.go", files[0].RelPkgPath)
+		assert.Equal(t, 4, files[0].StmtCount)
+		assert.Equal(t, 2, files[0].StmtCoveredCount)
+	}
+	assert.Equal(t, 4, gp.Root().StmtCount)
+
+	t.Run("should not check whether the baseline is stale", func(t *testing.T) {
+		temp := t.TempDir()
+		source := filepath.Join(temp, "x.go")
+		assert.NoError(t, os.WriteFile(source, []byte("package x\n"), 0o644))
+		input := filepath.Join(temp, "base.prof")
+		assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+
+		gp := NewGoProject(temp, nil, nil)
+		gp.Strict = true
+		assert.NoError(t, gp.ParseBaseline(input))
+		assert.Equal(t, 1, gp.Root().StmtCount)
+		assert.Empty(t, gp.Warnings)
+	})
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"go/build"
+	"path/filepath"
+)
+
+// buildContext returns the build context the profile is assumed to come from.
+// It defaults to the GOOS and GOARCH of the environment.
+func (gp *GoProject) buildContext() build.Context {
+	ctx := build.Default
+	if gp.GOOS != "" {
+		ctx.GOOS = gp.GOOS
+	}
+	if gp.GOARCH != "" {
+		ctx.GOARCH = gp.GOARCH
+	}
+	return ctx
+}
+
+// matchBuildContext reports whether the file name and build constraints match the build context.
+// Files which can't be read are assumed to match.
+func (gp *GoProject) matchBuildContext(absPath string) bool {
+	ctx := gp.buildContext()
+	match, err := ctx.MatchFile(filepath.Dir(absPath), filepath.Base(absPath))
+	return err != nil || match
+}
+
+// constraintNote returns the note shown on files whose build constraints don't match the build context.
+func (gp *GoProject) constraintNote() string {
+	ctx := gp.buildContext()
+	return "not built for " + ctx.GOOS + "/" + ctx.GOARCH
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildConstraints(t *testing.T) {
+	temp := t.TempDir()
+	sources := map[string]string{
+		"plain.go":       "package x\n",
+		"tagged.go":      "//go:build windows\n\npackage x\n",
+		"x_windows.go":   "package x\n",
+		"x_linux_arm.go": "package x\n",
+	}
+	for name, src := range sources {
+		assert.NoError(t, os.WriteFile(filepath.Join(temp, name), []byte(src), 0o644))
+	}
+
+	t.Run("should match file names and build constraints", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.GOOS, gp.GOARCH = "linux", "amd64"
+		assert.True(t, gp.matchBuildContext(filepath.Join(temp, "plain.go")))
+		assert.False(t, gp.matchBuildContext(filepath.Join(temp, "tagged.go")))
+		assert.False(t, gp.matchBuildContext(filepath.Join(temp, "x_windows.go")))
+		assert.False(t, gp.matchBuildContext(filepath.Join(temp, "x_linux_arm.go")))
+		assert.True(t, gp.matchBuildContext(filepath.Join(temp, "not-exist.go")))
+
+		gp.GOOS = "windows"
+		assert.True(t, gp.matchBuildContext(filepath.Join(temp, "tagged.go")))
+		assert.True(t, gp.matchBuildContext(filepath.Join(temp, "x_windows.go")))
+	})
+
+	input := filepath.Join(temp, "cover.prof")
+	content := fmt.Sprintf("mode: set\n%s:1.1,2.1 2 1\n%s:1.1,2.1 3 0\n", filepath.Join(temp, "plain.go"), filepath.Join(temp, "tagged.go"))
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+	t.Run("should annotate files not built for the platform", func(t *testing.T) {
+		gp := NewGoProject(temp, nil, nil)
+		gp.Constraints = config.ConstraintsAnnotate
+		gp.GOOS, gp.GOARCH = "linux", "amd64"
+		assert.NoError(t, gp.Parse(input))
+
+		files := gp.Root().Files
+		assert.Len(t, files, 2)
+		assert.Equal(t, "", files[0].Note)
+		assert.Equal(t, "not built for linux/amd64", files[1].Note)
+		assert.Equal(t, 5, gp.Root().StmtCount)
+	})
+
+	t.Run("should exclude files not built for the platform", func(t *testing.T) {
+		gp := NewGoProject(temp, nil, nil)
+		gp.Constraints = config.ConstraintsExclude
+		gp.GOOS, gp.GOARCH = "linux", "amd64"
+		assert.NoError(t, gp.Parse(input))
+
+		files := gp.Root().Files
+		assert.Len(t, files, 1)
+		assert.Equal(t, "plain.go", files[0].Title)
+		assert.Equal(t, 2, gp.Root().StmtCount)
+	})
+}
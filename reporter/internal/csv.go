@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// ReportCSV writes the coverage of every file of the GoProject to the provided io.Writer as CSV,
+// one path,statements,covered,percent row per file below a header, and a TOTAL row of the root totals.
+// Unscored files are left out, so the rows add up to the total.
+// Percentages are plain numbers, for spreadsheets to compute with them.
+func (gp *GoProject) ReportCSV(wr io.Writer) error {
+	root := gp.Root()
+	w := csv.NewWriter(wr)
+	row := func(path string, item *GoListItem) []string {
+		return []string{
+			path,
+			strconv.Itoa(item.StmtCount),
+			strconv.Itoa(item.StmtCoveredCount),
+			strconv.FormatFloat(item.Percent(), 'f', gp.Precision, 64),
+		}
+	}
+	if err := w.Write([]string{"path", "statements", "covered", "percent"}); err != nil {
+		return err
+	}
+	for _, file := range root.AllFiles() {
+		if file.Unscored {
+			continue
+		}
+		if err := w.Write(row(file.RelPkgPath, file.GoListItem)); err != nil {
+			return err
+		}
+	}
+	if err := w.Write(row("TOTAL", root.GoListItem)); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
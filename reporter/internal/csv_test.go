@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportCSV(t *testing.T) {
+	t.Run("should print a row per file and the total", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/x.go", StmtCount: 4, StmtCoveredCount: 3}})
+		gp.SafeDir("b").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "b/y,z.go", StmtCount: 2}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportCSV(&buf))
+		assert.Equal(t, strings.Join([]string{
+			"path,statements,covered,percent",
+			"a/x.go,4,3,75.0",
+			`"b/y,z.go",2,0,0.0`,
+			"TOTAL,6,3,50.0",
+			"",
+		}, "\n"), buf.String())
+	})
+
+	t.Run("should leave the unscored files out", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/x.go", StmtCount: 4, StmtCoveredCount: 3}})
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/x.pb.go", StmtCount: 6, Unscored: true}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportCSV(&buf))
+		assert.Equal(t, "path,statements,covered,percent\na/x.go,4,3,75.0\nTOTAL,4,3,75.0\n", buf.String())
+	})
+
+	t.Run("should print the total without files", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.Precision = 0
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportCSV(&buf))
+		assert.Equal(t, "path,statements,covered,percent\nTOTAL,0,0,0\n", buf.String())
+	})
+}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ChangedLines maps absolute file paths to the set of their added or modified line numbers.
+type ChangedLines map[string]map[int]bool
+
+// GitChangedLines returns the lines changed between the given git revision and HEAD,
+// as reported by "git diff <rev>...HEAD".
+// The prefixes of the file names are set whatever diff.noprefix or diff.mnemonicPrefix, and renamed files
+// are diffed as added files, so that the changed lines are always keyed by the current path of their file.
+func GitChangedLines(rev string) (ChangedLines, error) {
+	top, err := runGit("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runGit("", "diff", "--unified=0", "--no-color", "--no-ext-diff", "--no-renames",
+		"--src-prefix=a/", "--dst-prefix=b/", rev+"...HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiff(bytes.NewReader(out), strings.TrimSpace(string(top)))
+}
+
+// runGit runs git with the given arguments in the given directory, the current one when empty,
+// and returns its standard output.
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot run git %s: %v\n%s", args[0], err, stderr.Bytes())
+	}
+	return stdout, nil
+}
+
+// parseDiff parses a unified diff and returns the added lines of every file.
+// File names of the diff are resolved against the given root directory, git quoting the unusual ones.
+func parseDiff(r io.Reader, root string) (ChangedLines, error) {
+	changed := make(ChangedLines)
+	var lines map[int]bool
+	// oldLeft and newLeft are the lines of the current hunk still to read, so that an added line starting
+	// with "++ " isn't taken for a file header.
+	var oldLeft, newLeft int
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+			case strings.HasPrefix(line, " "), line == "":
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			// Names with spaces are followed by a tab.
+			name := strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\t")
+			if strings.HasPrefix(name, `"`) {
+				unquoted, err := strconv.Unquote(name)
+				if err != nil {
+					return nil, fmt.Errorf("invalid file header %q: %v", line, err)
+				}
+				name = unquoted
+			}
+			if name == "/dev/null" {
+				lines = nil
+				continue
+			}
+			name = strings.TrimPrefix(name, "b/")
+			lines = make(map[int]bool)
+			changed[filepath.Join(root, filepath.FromSlash(name))] = lines
+		case strings.HasPrefix(line, "@@ "):
+			oldCount, start, count, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			oldLeft, newLeft = oldCount, count
+			for i := start; lines != nil && i < start+count; i++ {
+				lines[i] = true
+			}
+		}
+	}
+	return changed, scanner.Err()
+}
+
+// parseHunkHeader returns the line count of the old side, and the first line and the line count of the new side
+// of a hunk header, such as "@@ -10,2 +12,3 @@".
+func parseHunkHeader(header string) (oldCount, start, count int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+
+	if _, oldCount, err = parseHunkRange(strings.TrimPrefix(fields[1], "-")); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %v", header, err)
+	}
+	if start, count, err = parseHunkRange(strings.TrimPrefix(fields[2], "+")); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %v", header, err)
+	}
+	return oldCount, start, count, nil
+}
+
+// parseHunkRange parses a side of a hunk header, such as "12,3", or "12" for a single line.
+func parseHunkRange(hunkRange string) (start, count int, err error) {
+	frags := strings.SplitN(hunkRange, ",", 2)
+	if start, err = strconv.Atoi(frags[0]); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if len(frags) == 2 {
+		if count, err = strconv.Atoi(frags[1]); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiff(t *testing.T) {
+	t.Run("should collect added lines per file", func(t *testing.T) {
+		diff := strings.Join([]string{
+			"diff --git a/a/x.go b/a/x.go",
+			"--- a/a/x.go",
+			"+++ b/a/x.go",
+			"@@ -3 +3,2 @@ func x() {",
+			"-	old()",
+			"+	new()",
+			"+	other()",
+			"@@ -10,0 +12 @@",
+			"+	added()",
+			"@@ -20,2 +22,0 @@",
+			"-	removed()",
+			"-	removed()",
+			"diff --git a/y.go b/y.go",
+			"--- a/y.go",
+			"+++ /dev/null",
+			"@@ -1,2 +0,0 @@",
+			"-package y",
+			"-",
+		}, "\n")
+
+		changed, err := parseDiff(strings.NewReader(diff), "/repo")
+		assert.NoError(t, err)
+		assert.Equal(t, ChangedLines{
+			filepath.Join("/repo", "a", "x.go"): {3: true, 4: true, 12: true},
+		}, changed)
+	})
+
+	t.Run("should not take added lines for file headers", func(t *testing.T) {
+		diff := strings.Join([]string{
+			"--- a/x.go",
+			"+++ b/x.go",
+			"@@ -1,2 +1,3 @@",
+			"--- removed",
+			"-- removed",
+			"+++ added",
+			"++ b/y.go",
+			"+@@ -1 +1 @@",
+			"@@ -9,0 +10 @@",
+			"+added",
+		}, "\n")
+
+		changed, err := parseDiff(strings.NewReader(diff), "/repo")
+		assert.NoError(t, err)
+		assert.Equal(t, ChangedLines{
+			filepath.Join("/repo", "x.go"): {1: true, 2: true, 3: true, 10: true},
+		}, changed)
+	})
+
+	t.Run("should read quoted file names and names with spaces", func(t *testing.T) {
+		diff := strings.Join([]string{
+			`diff --git "a/caf\303\251 \"x\".go" "b/caf\303\251 \"x\".go"`,
+			"new file mode 100644",
+			"--- /dev/null",
+			`+++ "b/caf\303\251 \"x\".go"` + "\t",
+			"@@ -0,0 +1 @@",
+			"+package x",
+			"diff --git a/a b.go b/a b.go",
+			"--- a/a b.go\t",
+			"+++ b/a b.go\t",
+			"@@ -2,0 +3 @@",
+			"+added",
+		}, "\n")
+
+		changed, err := parseDiff(strings.NewReader(diff), "/repo")
+		assert.NoError(t, err)
+		assert.Equal(t, ChangedLines{
+			filepath.Join("/repo", "café \"x\".go"): {1: true},
+			filepath.Join("/repo", "a b.go"):        {3: true},
+		}, changed)
+	})
+
+	t.Run("should return error with invalid hunk header", func(t *testing.T) {
+		_, err := parseDiff(strings.NewReader("+++ b/x.go\n@@ -1 +a @@\n"), "/repo")
+		assert.ErrorContains(t, err, `invalid hunk header "@@ -1 +a @@"`)
+	})
+}
+
+func TestGitChangedLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("config", "diff.noprefix", "true")
+	git("config", "diff.renames", "true")
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "old.go"), []byte("package x\n\nfunc f() {}\n"), 0o644))
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	git("mv", "old.go", "new.go")
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "new.go"), []byte("package x\n\nfunc f() {}\n\nfunc g() {}\n"), 0o644))
+	git("add", "-A")
+	git("commit", "-q", "-m", "rename")
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(repo))
+	defer os.Chdir(wd)
+
+	t.Run("should key the lines by the current path whatever the diff settings", func(t *testing.T) {
+		changed, err := GitChangedLines("HEAD~1")
+		assert.NoError(t, err)
+		assert.Equal(t, ChangedLines{
+			filepath.Join(repo, "new.go"): {1: true, 2: true, 3: true, 4: true, 5: true},
+		}, changed)
+	})
+}
@@ -1,7 +1,12 @@
 package internal
 
 import (
+	"bytes"
+	"fmt"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/drappier-charles/covreport/reporter/config"
@@ -11,57 +16,220 @@ import (
 
 func NewGoProject(root string, cutlines *config.Cutlines, ignores []string) *GoProject {
 	return &GoProject{
-		Dirs:     make(map[string]*GoDir),
-		RootPath: root,
-		Cutlines: cutlines,
-		Ignores:  ignores,
+		Dirs:         make(map[string]*GoDir),
+		RootPath:     normalizePath(root),
+		Cutlines:     cutlines,
+		Ignores:      ignores,
+		Sources:      &SourceCache{},
+		Precision:    1,
+		CollapseRoot: true,
 	}
 }
 
 type GoProject struct {
+	// Title is the title of the HTML report, naming the project, empty for the default one.
+	Title    string
 	Dirs     map[string]*GoDir
 	RootPath string
 	Cutlines *config.Cutlines
-	Ignores  []string
+	// Colors overrides the colors of the coverage classes when set.
+	Colors  *config.Colors
+	Ignores []string
+	Layout  string
+	// ScoreExcludes are the patterns of the files shown in the report but left out of the totals, see Unscored.
+	ScoreExcludes []string
+	// GoOnly leaves the files of other languages than Go, such as the C and assembly files of cgo packages,
+	// out of the totals like ScoreExcludes.
+	GoOnly bool
+	// PathStyle tells whether breadcrumbs and items show full package paths or base names.
+	PathStyle string
+	Sort      string
+	Collapse  int
+	// CollapseRoot starts the report below the chain of directories having a single subdirectory and no files
+	// when the root is ".".
+	CollapseRoot bool
+
+	// Precision is the number of decimal places of the percentages shown in the report.
+	Precision int
+
+	// Changed holds the lines changed since a base revision, restricting diff coverage to them when set.
+	Changed ChangedLines
+
+	// Constraints tells how files whose build constraints don't match GOOS and GOARCH are handled.
+	Constraints string
+	GOOS        string
+	GOARCH      string
+
+	// External tells how files outside of RootPath are handled, skipped by default.
+	External string
+	// Remaps rewrite the leading paths of the files of the profiles before anything else, see remapPath.
+	Remaps []config.PathRemap
+	// GitIgnore leaves the files it matches out of the report, nil keeping every file.
+	GitIgnore *GitIgnore
+	// CodeOwners sets the owners of the directories and files of the report, nil leaving them unowned.
+	CodeOwners *CodeOwners
+
+	// Modules are the modules of the workspace the GoProject spans, if any.
+	// Each of them is a top-level directory of the root, titled by its module path.
+	Modules []*Module
+
+	// IncludeTests keeps the _test.go files of the profile, which are left out by default.
+	IncludeTests bool
+
+	// Compared tells whether the GoProject was compared with a baseline.
+	Compared bool
+	// Removed lists the files of the baseline of a comparison missing from the GoProject.
+	Removed []string
+	// Hidden is the number of fully covered files pruned from the tree.
+	Hidden int
+	// Warnings are the problems of the input found while parsing it, such as a stale profile,
+	// shown in the header of the HTML report.
+	Warnings []string
+
+	// Profiles are the coverage profiles as parsed from the input, before any filtering.
+	Profiles []*cover.Profile
+	// SelfContained embeds Profiles in the HTML report.
+	SelfContained bool
+	// Minify strips the whitespace of the HTML report which doesn't change its appearance.
+	Minify bool
+	// StrictLines only shows lines as covered in the HTML report when every block overlapping them ran.
+	StrictLines bool
+	// LinkUncovered links the files listed in the HTML report to their first uncovered line.
+	LinkUncovered bool
+	// Sidebar shows a navigation pane listing every directory next to the views of the HTML report.
+	Sidebar bool
+	// LineBackgrounds overrides the backgrounds of the uncovered and covered lines of the HTML report when set.
+	LineBackgrounds *config.LineBackgrounds
+	// LiveReload makes the HTML report reload itself in the browser when it is regenerated, in watch mode.
+	LiveReload bool
+
+	// Lenient skips malformed profile lines instead of failing.
+	Lenient bool
+	// AllowEmpty reports profiles without coverage blocks instead of failing.
+	AllowEmpty bool
+	// Strict fails on profiles which look stale instead of warning.
+	Strict bool
+
+	// Sources reads the source files once for every format and view, nil or zero reading them every time they are needed.
+	Sources *SourceCache
+
+	// Progress prints the progress of parsing the profiles and rendering the files, nil printing nothing.
+	Progress *Progress
+	// Logger prints the warnings and debug messages of parsing the profiles, nil printing nothing.
+	Logger *Logger
+
+	// BadgeStyle tells whether the badge shows the percentage only or the statement counts too.
+	BadgeStyle string
+
+	// MaxAnnotations caps the number of GitHub Actions annotations, 0 meaning no limit.
+	MaxAnnotations int
+
+	// Context is the number of source lines printed around uncovered lines in the text report,
+	// 0 only listing the uncovered lines.
+	Context int
 }
 
 // Parse parses the input profiles filename and updates the GoProject's coverage report.
 func (gp *GoProject) Parse(input string) error {
-	profiles, err := cover.ParseProfiles(input)
+	gp.Progress.Begin("reading profiles")
+	profiles, skipped, err := ParseProfiles(input, gp.Lenient, gp.Logger)
+	gp.Progress.Finish()
 	if err != nil {
 		return err
 	}
+	if skipped > 0 {
+		gp.Logger.Warnf("skipped %d malformed profile lines, the report may be incomplete", skipped)
+	}
+	if !gp.AllowEmpty && !slices.ContainsFunc(profiles, func(profile *cover.Profile) bool { return len(profile.Blocks) > 0 }) {
+		return fmt.Errorf("no coverage data found in %q", input)
+	}
+	for _, profile := range profiles {
+		profile.FileName = normalizePath(profile.FileName)
+		if remapped := remapPath(profile.FileName, gp.Remaps); remapped != profile.FileName {
+			gp.Logger.Debugf("remapped %s to %s", profile.FileName, remapped)
+			profile.FileName = remapped
+		}
+	}
+	gp.Profiles = profiles
+	gp.Logger.Debugf("parsed %d profiles from %q", len(profiles), input)
 
-	pkgs, err := findPkgs(profiles)
+	gp.Progress.Begin("locating packages")
+	pkgs, err := findPkgs(profiles, gp.Modules, gp.Logger)
+	gp.Progress.Finish()
 	if err != nil {
 		return err
 	}
 
+	// files maps the resolved absolute paths to the files, to find the same file spelled differently.
+	files := make(map[string]*GoFile)
+	var external []string
+
+	gp.Progress.Start("parsing profiles", len(profiles))
 PROFILE_LOOP:
 	for _, profile := range profiles {
-		for _, ignore := range gp.Ignores {
-			if strings.HasPrefix(profile.FileName, ignore) {
-				continue PROFILE_LOOP
-			}
+		gp.Progress.Step()
+		if gp.skipFile(profile.FileName) {
+			continue PROFILE_LOOP
 		}
-
-		dir := gp.SafeDir(filepath.Dir(profile.FileName))
-		var file *GoFile
-		for _, f := range dir.Files {
-			if strings.HasSuffix(profile.FileName, f.RelPkgPath) {
-				file = f
-				break
+		relPath, ok := gp.relPath(profile.FileName)
+		if !ok {
+			if !slices.Contains(external, profile.FileName) {
+				external = append(external, profile.FileName)
 			}
+			continue PROFILE_LOOP
 		}
-		if file == nil {
+
+		if gp.Constraints == config.ConstraintsExclude {
 			absPath, err := findFile(pkgs, profile.FileName)
 			if err != nil {
 				return err
 			}
-			file = &GoFile{ABSPath: absPath, GoListItem: NewGoListItem(profile.FileName)}
-			dir.AddFile(file)
+			if !gp.matchBuildContext(absPath) {
+				continue PROFILE_LOOP
+			}
+		}
+
+		// The directory is only created along with its first file, not for files skipped below.
+		var file *GoFile
+		if dir, ok := gp.Dirs[path.Dir(relPath)]; ok {
+			for _, f := range dir.Files {
+				if strings.HasSuffix(relPath, f.RelPkgPath) {
+					file = f
+					break
+				}
+			}
+		}
+		if file != nil {
+			// The parser sums the counts of identical blocks of a file name, but the same file may be
+			// spelled differently, like Windows paths with either separator.
+			gp.Logger.Debugf("%s: merging %d profile blocks", relPath, len(profile.Blocks))
+			file.MergeBlocks(profile.Blocks, profile.Mode)
+			continue PROFILE_LOOP
+		}
+		absPath, err := findFile(pkgs, profile.FileName)
+		if err != nil {
+			return err
+		}
+		if gp.GitIgnore.Match(absPath) {
+			gp.Logger.Debugf("skipping %s, ignored by git", profile.FileName)
+			continue PROFILE_LOOP
+		}
+		resolved := resolvePath(absPath)
+		if same, ok := files[resolved]; ok {
+			gp.Logger.Warnf("%s and %s are the same file, merging their profiles", same.RelPkgPath, profile.FileName)
+			same.MergeBlocks(profile.Blocks, profile.Mode)
+			continue PROFILE_LOOP
+		}
+		gp.Logger.Debugf("resolved %s to %s", profile.FileName, absPath)
+		file = &GoFile{ABSPath: absPath, GoListItem: NewGoListItem(relPath)}
+		files[resolved] = file
+		if gp.Constraints == config.ConstraintsAnnotate && !gp.matchBuildContext(absPath) {
+			file.Note = gp.constraintNote()
 		}
+		file.Unscored = gp.unscored(profile.FileName)
+		gp.SafeDir(path.Dir(relPath)).AddFile(file)
 
+		gp.Logger.Debugf("%s: %d profile blocks", relPath, len(profile.Blocks))
 		for _, block := range profile.Blocks {
 			file.Profile = append(file.Profile, block)
 			file.StmtCount += block.NumStmt
@@ -70,10 +238,108 @@ PROFILE_LOOP:
 			}
 		}
 	}
+	gp.Progress.Finish()
+	for _, file := range files {
+		sortBlocks(file.Profile)
+	}
+	if len(external) > 0 {
+		gp.Logger.Warnf("skipped %d files outside of %s: %s", len(external), gp.RootPath, strings.Join(external, ", "))
+	}
+	allFiles := gp.Root().AllFiles()
+	gp.Progress.Start("reading sources", len(allFiles))
+	var stale []string
+	var outOfRange int
+	for _, file := range allFiles {
+		file.ReadSource(gp.Sources, gp.Logger)
+		if file.Stale {
+			stale = append(stale, file.RelPkgPath)
+			outOfRange += len(file.OutOfRange)
+		}
+		gp.Progress.Step()
+	}
+	gp.Progress.Finish()
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		if gp.Strict {
+			return fmt.Errorf("profile is stale, %s edited since it was generated: %s", plural(len(stale), "file was", "files were"), strings.Join(stale, ", "))
+		}
+		gp.Logger.Warnf("profile may be stale, %s edited since it was generated: %s", plural(len(stale), "file was", "files were"), strings.Join(stale, ", "))
+		gp.Warnings = append(gp.Warnings, fmt.Sprintf("profile may be stale, %s past the last line of %s: %s",
+			plural(outOfRange, "block ends", "blocks end"), plural(len(stale), "file", "files"), strings.Join(stale, ", ")))
+	}
+	for _, dir := range gp.Root().AllDirs() {
+		dir.ReadDoc()
+	}
+	if gp.CodeOwners != nil {
+		gp.assignOwners()
+	}
+	if gp.Changed != nil {
+		for _, file := range gp.Root().AllFiles() {
+			absPath, err := filepath.Abs(file.ABSPath)
+			if err != nil {
+				return err
+			}
+			file.Changed = gp.Changed[absPath]
+			file.CountChanged()
+		}
+	}
 	gp.Root().Aggregate()
 	return nil
 }
 
+// skipFile reports whether the file of a profile is left out of the report: a test file without IncludeTests,
+// or a file matching a prefix of Ignores.
+func (gp *GoProject) skipFile(fileName string) bool {
+	if !gp.IncludeTests && strings.HasSuffix(fileName, "_test.go") {
+		gp.Logger.Debugf("skipping test file %s", fileName)
+		return true
+	}
+	for _, ignore := range gp.Ignores {
+		if strings.HasPrefix(fileName, ignore) {
+			gp.Logger.Debugf("skipping %s, ignored by %q", fileName, ignore)
+			return true
+		}
+	}
+	return false
+}
+
+// relPath returns the path of the file of a profile in the tree of the GoProject, under ExternalDir
+// for the files outside of RootPath with ExternalInclude, and false for the other files outside of it.
+func (gp *GoProject) relPath(fileName string) (string, bool) {
+	if gp.RootPath == "." || matchPathPrefix(fileName, gp.RootPath) {
+		return fileName, true
+	}
+	if gp.External != config.ExternalInclude {
+		return "", false
+	}
+	return path.Join(gp.RootPath, ExternalDir, fileName), true
+}
+
+// unscored reports whether the file name of a profile matches a pattern of ScoreExcludes:
+// a prefix, like the ignores, or a glob matching the file name or its base name, like *.pb.go.
+// With GoOnly, the files of other languages than Go are unscored too.
+func (gp *GoProject) unscored(fileName string) bool {
+	if gp.GoOnly && FileLanguage(fileName) != LanguageGo {
+		return true
+	}
+	for _, pattern := range gp.ScoreExcludes {
+		if strings.HasPrefix(fileName, pattern) {
+			return true
+		}
+		if ok, _ := path.Match(pattern, fileName); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(fileName)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalDir is the synthetic directory of the root grouping the files outside of it.
+// Directories starting with an underscore are ignored by the go tool, so no package of the root can clash with it.
+const ExternalDir = "_external"
+
 // SafeDir returns a pointer to a GoDir object for the given relative package path.
 func (gp *GoProject) SafeDir(relPkgPath string) *GoDir {
 	if dir, ok := gp.Dirs[relPkgPath]; ok {
@@ -83,7 +349,12 @@ func (gp *GoProject) SafeDir(relPkgPath string) *GoDir {
 	dir := &GoDir{GoListItem: NewGoListItem(relPkgPath)}
 	gp.Dirs[relPkgPath] = dir
 
-	parent := gp.SafeDir(filepath.Dir(relPkgPath))
+	parentPath := path.Dir(relPkgPath)
+	if gp.isModule(relPkgPath) {
+		parentPath = gp.RootPath
+		dir.Title = relPkgPath
+	}
+	parent := gp.SafeDir(parentPath)
 	if parent != dir {
 		parent.SubDirs = append(parent.SubDirs, dir)
 	}
@@ -91,15 +362,40 @@ func (gp *GoProject) SafeDir(relPkgPath string) *GoDir {
 	return dir
 }
 
+// isModule reports whether the package path is the path of a module of the workspace.
+func (gp *GoProject) isModule(relPkgPath string) bool {
+	return relPkgPath != gp.RootPath && slices.ContainsFunc(gp.Modules, func(module *Module) bool {
+		return module.Path == relPkgPath
+	})
+}
+
 // Root returns the root directory of the Go project.
 func (gp *GoProject) Root() *GoDir {
 	return gp.SafeDir(gp.RootPath)
 }
 
+// InitialDir returns the directory the report starts from.
+// When the root is "." and CollapseRoot is set, it descends through directories having a single subdirectory and no files.
+func (gp *GoProject) InitialDir() *GoDir {
+	initialDir := gp.Root()
+	if gp.RootPath == "." && gp.CollapseRoot {
+		for len(initialDir.SubDirs) == 1 && len(initialDir.Files) == 0 {
+			initialDir = initialDir.SubDirs[0]
+		}
+	}
+	return initialDir
+}
+
 type GoDir struct {
 	*GoListItem
 	SubDirs []*GoDir
 	Files   []*GoFile
+	// Doc is the package comment of the directory, empty when it isn't a documented Go package.
+	Doc string
+	// Component is the component the package of the directory is tagged with, empty when untagged.
+	Component string
+	// Tests are the results of the tests of the package of the directory, nil without go test -json input.
+	Tests *TestResult
 }
 
 // Aggregate recursively aggregates the total and covered statement count
@@ -109,13 +405,92 @@ func (dir *GoDir) Aggregate() {
 		subDir.Aggregate()
 		dir.StmtCount += subDir.StmtCount
 		dir.StmtCoveredCount += subDir.StmtCoveredCount
+		dir.DiffStmtCount += subDir.DiffStmtCount
+		dir.DiffStmtCoveredCount += subDir.DiffStmtCoveredCount
+		dir.BranchCount += subDir.BranchCount
+		dir.BranchCoveredCount += subDir.BranchCoveredCount
+		dir.LineCount += subDir.LineCount
 	}
 	for _, file := range dir.Files {
+		if file.Unscored {
+			dir.LineCount += file.LineCount
+			continue
+		}
 		dir.StmtCount += file.StmtCount
 		dir.StmtCoveredCount += file.StmtCoveredCount
+		dir.DiffStmtCount += file.DiffStmtCount
+		dir.DiffStmtCoveredCount += file.DiffStmtCoveredCount
+		dir.BranchCount += file.BranchCount
+		dir.BranchCoveredCount += file.BranchCoveredCount
+		dir.LineCount += file.LineCount
 	}
 }
 
+// PruneCovered removes the fully covered files from the tree, and the directories they leave empty,
+// so the report only shows the parts which need work. Directories keep their totals, counting the removed files.
+// It returns the number of removed files, added to Hidden.
+func (gp *GoProject) PruneCovered() int {
+	hidden := gp.pruneCovered(gp.Root())
+	gp.Hidden += hidden
+	return hidden
+}
+
+// pruneCovered removes the fully covered files under dir, see PruneCovered.
+func (gp *GoProject) pruneCovered(dir *GoDir) int {
+	var hidden int
+	dir.SubDirs = slices.DeleteFunc(dir.SubDirs, func(subDir *GoDir) bool {
+		hidden += gp.pruneCovered(subDir)
+		if len(subDir.SubDirs) > 0 || len(subDir.Files) > 0 {
+			return false
+		}
+		delete(gp.Dirs, subDir.RelPkgPath)
+		return true
+	})
+	dir.Files = slices.DeleteFunc(dir.Files, func(file *GoFile) bool {
+		if file.StmtCount == 0 || file.StmtCoveredCount < file.StmtCount {
+			return false
+		}
+		hidden++
+		return true
+	})
+	return hidden
+}
+
+// WorstFile returns the least covered scored file with statements under the GoDir, the one with the most uncovered
+// statements among equally covered files, or nil when every file is fully covered.
+func (dir *GoDir) WorstFile() *GoFile {
+	var worst *GoFile
+	for _, file := range dir.AllFiles() {
+		if file.Unscored || file.StmtCount == 0 || file.StmtCoveredCount == file.StmtCount {
+			continue
+		}
+		if worst == nil || file.Percent() < worst.Percent() ||
+			file.Percent() == worst.Percent() && file.StmtCount-file.StmtCoveredCount > worst.StmtCount-worst.StmtCoveredCount {
+			worst = file
+		}
+	}
+	return worst
+}
+
+// AllDirs returns the GoDir and its subdirectories, recursively, parents first.
+func (dir *GoDir) AllDirs() []*GoDir {
+	dirs := []*GoDir{dir}
+	for _, subDir := range dir.SubDirs {
+		dirs = append(dirs, subDir.AllDirs()...)
+	}
+	return dirs
+}
+
+// AllFiles returns the files of the GoDir and of its subdirectories, recursively.
+// Files of subdirectories come first, as they are listed in the report.
+func (dir *GoDir) AllFiles() []*GoFile {
+	var files []*GoFile
+	for _, subDir := range dir.SubDirs {
+		files = append(files, subDir.AllFiles()...)
+	}
+	return append(files, dir.Files...)
+}
+
 // AddFile adds a GoFile to the GoDir's list of files.
 func (dir *GoDir) AddFile(file *GoFile) {
 	dir.Files = append(dir.Files, file)
@@ -125,23 +500,188 @@ type GoFile struct {
 	*GoListItem
 	ABSPath string
 	Profile []cover.ProfileBlock
+	Changed map[int]bool
+	Funcs   []*GoFunc
+	// Stale tells whether the profile references lines past the end of the source,
+	// the file having been edited since the profile was generated.
+	Stale bool
+	// OutOfRange are the blocks of the profile ending past the end of the source, set when Stale.
+	// Their lines don't exist, so they are missing from the file view.
+	OutOfRange []cover.ProfileBlock
+}
+
+// ReadSource reads the source of the file to count its lines, to check the profile isn't stale,
+// to drop the blocks ignored by magic comments and, for Go files, to find its functions.
+// Files which can't be read are left as is, the error being reported when rendering them.
+// The lines read and the blocks past them are traced by the debug messages of the Logger.
+func (file *GoFile) ReadSource(sources *SourceCache, logger *Logger) {
+	src, err := sources.ReadFile(file.ABSPath)
+	if err != nil {
+		logger.Debugf("can't read %s: %v", file.ABSPath, err)
+		return
+	}
+	file.LineCount = countLines(src)
+	logger.Debugf("read %s: %d lines", file.ABSPath, file.LineCount)
+	file.OutOfRange = nil
+	for _, block := range file.Profile {
+		if block.EndLine > file.LineCount {
+			logger.Debugf("%s: block %d.%d,%d.%d ends past the last line", file.ABSPath, block.StartLine, block.StartCol, block.EndLine, block.EndCol)
+			file.OutOfRange = append(file.OutOfRange, block)
+		}
+	}
+	file.Stale = len(file.OutOfRange) > 0
+	file.IgnoreLines(src, logger)
+	if filepath.Ext(file.ABSPath) == ".go" {
+		file.parseFuncs(src)
+	}
+}
+
+// plural formats the count followed by the singular or the plural form of the words.
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// countLines returns the number of lines of the source, the last one possibly missing its newline.
+func countLines(src []byte) int {
+	n := bytes.Count(src, []byte("\n"))
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// MergeBlocks merges the blocks of another profile of the same file into the file's profile.
+// The counts of identical blocks are added up, or combined with a logical or in set mode.
+func (file *GoFile) MergeBlocks(blocks []cover.ProfileBlock, mode string) {
+	for _, block := range blocks {
+		idx := slices.IndexFunc(file.Profile, func(b cover.ProfileBlock) bool {
+			return b.StartLine == block.StartLine && b.StartCol == block.StartCol && b.EndLine == block.EndLine && b.EndCol == block.EndCol
+		})
+		switch {
+		case idx < 0:
+			file.Profile = append(file.Profile, block)
+		case mode == "set":
+			file.Profile[idx].Count = max(file.Profile[idx].Count, block.Count)
+		default:
+			file.Profile[idx].Count += block.Count
+		}
+	}
+	sortBlocks(file.Profile)
+
+	file.StmtCount, file.StmtCoveredCount = 0, 0
+	for _, block := range file.Profile {
+		file.StmtCount += block.NumStmt
+		if block.Count > 0 {
+			file.StmtCoveredCount += block.NumStmt
+		}
+	}
+}
+
+// sortBlocks sorts the profile blocks by start then end position, as rendering them expects.
+// Blocks of concatenated profiles or of odd tooling may come out of order.
+func sortBlocks(blocks []cover.ProfileBlock) {
+	sort.SliceStable(blocks, func(i, j int) bool {
+		a, b := blocks[i], blocks[j]
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		if a.StartCol != b.StartCol {
+			return a.StartCol < b.StartCol
+		}
+		if a.EndLine != b.EndLine {
+			return a.EndLine < b.EndLine
+		}
+		return a.EndCol < b.EndCol
+	})
+}
+
+// resolvePath returns the absolute path of the file with symbolic links evaluated,
+// or the path as is when it can't be resolved.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// FirstUncoveredLine returns the start line of the first block of the profile which never ran, 0 when every block ran.
+func (file *GoFile) FirstUncoveredLine() int {
+	first := 0
+	for _, block := range file.Profile {
+		if block.Count == 0 && (first == 0 || block.StartLine < first) {
+			first = block.StartLine
+		}
+	}
+	return first
+}
+
+// CountChanged counts the statements of the profile blocks overlapping a changed line.
+func (file *GoFile) CountChanged() {
+	file.DiffStmtCount, file.DiffStmtCoveredCount = 0, 0
+	for _, block := range file.Profile {
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			if file.Changed[line] {
+				file.DiffStmtCount += block.NumStmt
+				if block.Count > 0 {
+					file.DiffStmtCoveredCount += block.NumStmt
+				}
+				break
+			}
+		}
+	}
 }
 
 func NewGoListItem(relPkgPath string) *GoListItem {
 	return &GoListItem{
 		RelPkgPath: relPkgPath,
-		ID:         uuid.NewSHA1(uuid.Nil, []byte(relPkgPath)).String(),
-		Title:      filepath.Base(relPkgPath),
+		ID:         itemID(relPkgPath),
+		Title:      path.Base(relPkgPath),
 	}
 }
 
+// itemID returns the ID of the item of the given path, a name-based UUID.
+// It only depends on the path, so links to the views of a report survive its regeneration,
+// and only has hexadecimal digits and dashes, so it is safe in URL fragments.
+func itemID(path string) string {
+	return uuid.NewSHA1(uuid.Nil, []byte(path)).String()
+}
+
 type GoListItem struct {
 	RelPkgPath string
-	ID         string
-	Title      string
+	// ID identifies the view of the item in URL fragments, see itemID.
+	ID    string
+	Title string
+	// Note is an annotation shown next to the title, such as a build constraint mismatch.
+	Note string
 
 	StmtCount        int
 	StmtCoveredCount int
+	// LineCount is the number of lines of the source, including comments and blank lines.
+	LineCount int
+
+	DiffStmtCount        int
+	DiffStmtCoveredCount int
+
+	// BranchCount is the number of explicit arms of the if, switch and select statements of the item,
+	// and BranchCoveredCount the number of them which ran, approximating branch coverage, see countBranches.
+	BranchCount        int
+	BranchCoveredCount int
+
+	// Base is the same item in the baseline of a comparison, nil when not compared or new.
+	Base *GoListItem
+	// New tells that the item has no counterpart in the baseline of a comparison.
+	New bool
+	// Unscored tells that the file is shown but left out of the totals of its directories, such as generated code.
+	Unscored bool
+	// Owners are the code owners of the item, nil when it has none.
+	Owners []string
 }
 
 // Percent calculates the percentage of statement coverage for a GoListItem.
@@ -151,3 +691,19 @@ func (item *GoListItem) Percent() float64 {
 	}
 	return float64(item.StmtCoveredCount) / float64(item.StmtCount) * 100
 }
+
+// BranchPercent calculates the percentage of approximate branch coverage for a GoListItem.
+func (item *GoListItem) BranchPercent() float64 {
+	if item.BranchCount == 0 {
+		return 0
+	}
+	return float64(item.BranchCoveredCount) / float64(item.BranchCount) * 100
+}
+
+// DiffPercent calculates the percentage of statement coverage restricted to changed lines.
+func (item *GoListItem) DiffPercent() float64 {
+	if item.DiffStmtCount == 0 {
+		return 0
+	}
+	return float64(item.DiffStmtCoveredCount) / float64(item.DiffStmtCount) * 100
+}
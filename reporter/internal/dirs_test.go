@@ -3,9 +3,14 @@ package internal
 import (
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/drappier-charles/covreport/reporter/config"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
 )
 
 func TestGoListItemPercent(t *testing.T) {
@@ -79,6 +84,68 @@ func TestSafeDir(t *testing.T) {
 	assert.Equal(t, c, b.SubDirs[0])
 }
 
+func TestGoListItemID(t *testing.T) {
+	build := func(paths ...string) *GoProject {
+		gp := NewGoProject(".", nil, nil)
+		for _, path := range paths {
+			gp.SafeDir(filepath.Dir(path)).AddFile(&GoFile{GoListItem: NewGoListItem(path)})
+		}
+		return gp
+	}
+	paths := []string{"a/b/x.go", "a/y.go", "a/b c/z+.go", "a/#d e/w<1>.go"}
+	gp := build(paths...)
+	reversed := build(paths[3], paths[2], paths[1], paths[0])
+
+	t.Run("should not depend on the order of the tree", func(t *testing.T) {
+		for relPkgPath, dir := range gp.Dirs {
+			assert.Equal(t, dir.ID, reversed.Dirs[relPkgPath].ID, relPkgPath)
+		}
+		for _, path := range paths {
+			assert.Equal(t, gp.SafeDir(filepath.Dir(path)).Files[0].ID, reversed.SafeDir(filepath.Dir(path)).Files[0].ID, path)
+		}
+	})
+
+	t.Run("should be stable across runs", func(t *testing.T) {
+		assert.Equal(t, "7ab55174-4086-5f80-9198-f8a45382bc01", NewGoListItem("a/b/x.go").ID)
+	})
+
+	t.Run("should be safe in URL fragments", func(t *testing.T) {
+		ids := make(map[string]bool)
+		for _, dir := range gp.Dirs {
+			ids[dir.ID] = true
+		}
+		for _, file := range gp.Root().AllFiles() {
+			ids[file.ID] = true
+		}
+		assert.Len(t, ids, len(gp.Dirs)+len(paths))
+		for id := range ids {
+			assert.Regexp(t, `^[0-9a-f-]+$`, id)
+		}
+	})
+}
+
+func TestInitialDir(t *testing.T) {
+	gp := NewGoProject(".", nil, nil)
+	b := gp.SafeDir("a/b")
+	b.AddFile(&GoFile{GoListItem: NewGoListItem("a/b/x.go")})
+
+	t.Run("should descend through directories having a single subdirectory", func(t *testing.T) {
+		assert.Equal(t, b, gp.InitialDir())
+	})
+
+	t.Run("should start at the root when not collapsing it", func(t *testing.T) {
+		gp.CollapseRoot = false
+		defer func() { gp.CollapseRoot = true }()
+		assert.Equal(t, gp.Root(), gp.InitialDir())
+	})
+
+	t.Run("should start at the root when it isn't the current directory", func(t *testing.T) {
+		gp := NewGoProject("a", nil, nil)
+		gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: NewGoListItem("a/b/x.go")})
+		assert.Equal(t, gp.Root(), gp.InitialDir())
+	})
+}
+
 func TestAggregate(t *testing.T) {
 	gp := NewGoProject(".", nil, nil)
 	a := gp.Root()
@@ -112,11 +179,12 @@ func TestAggregate(t *testing.T) {
 func TestGoProject_Parse(t *testing.T) {
 	curPkg := "github.com/drappier-charles/covreport/reporter/internal"
 	tests := []struct {
-		name        string
-		input       string
-		wantFiles   int
-		wantStmts   int
-		wantCovStmt int
+		name         string
+		input        string
+		includeTests bool
+		wantFiles    int
+		wantStmts    int
+		wantCovStmt  int
 	}{
 		{
 			name:        "one file, all statements covered",
@@ -134,18 +202,33 @@ func TestGoProject_Parse(t *testing.T) {
 		},
 		{
 			name:        "two files, all statements covered",
-			input:       fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 1\n%s/dirs_test.go:1.1,2.1 3 1\n", curPkg, curPkg),
+			input:       fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 1\n%s/find.go:1.1,2.1 3 1\n", curPkg, curPkg),
 			wantFiles:   2,
 			wantStmts:   5,
 			wantCovStmt: 5,
 		},
 		{
 			name:        "two files, no statements covered",
-			input:       fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 0\n%s/dirs_test.go:1.1,2.1 3 0\n", curPkg, curPkg),
+			input:       fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 0\n%s/find.go:1.1,2.1 3 0\n", curPkg, curPkg),
 			wantFiles:   2,
 			wantStmts:   5,
 			wantCovStmt: 0,
 		},
+		{
+			name:        "test files left out by default",
+			input:       fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 1\n%s/dirs_test.go:1.1,2.1 3 0\n", curPkg, curPkg),
+			wantFiles:   1,
+			wantStmts:   2,
+			wantCovStmt: 2,
+		},
+		{
+			name:         "test files included",
+			input:        fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 1\n%s/dirs_test.go:1.1,2.1 3 0\n", curPkg, curPkg),
+			includeTests: true,
+			wantFiles:    2,
+			wantStmts:    5,
+			wantCovStmt:  2,
+		},
 	}
 
 	for _, tt := range tests {
@@ -160,6 +243,7 @@ func TestGoProject_Parse(t *testing.T) {
 			assert.NoError(t, err)
 
 			gp := NewGoProject(curPkg, nil, nil)
+			gp.IncludeTests = tt.includeTests
 			err = gp.Parse(input)
 			assert.NoError(t, err)
 
@@ -170,3 +254,399 @@ func TestGoProject_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestGoProject_ParseExternal(t *testing.T) {
+	curPkg := "github.com/drappier-charles/covreport/reporter/internal"
+	configPkg := "github.com/drappier-charles/covreport/reporter/config"
+	input := filepath.Join(t.TempDir(), "cover.prof")
+	content := fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 1\n%s/config.go:1.1,2.1 3 0\n", curPkg, configPkg)
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+	t.Run("should skip files outside of the root with a warning", func(t *testing.T) {
+		var logs strings.Builder
+		gp := NewGoProject(curPkg, nil, nil)
+		gp.Logger = NewLogger(&logs, LogNormal)
+		assert.NoError(t, gp.Parse(input))
+		assert.Len(t, gp.Root().AllFiles(), 1)
+		assert.Equal(t, 2, gp.Root().StmtCount)
+		assert.Contains(t, logs.String(), "warning: skipped 1 files outside of "+curPkg+": "+configPkg+"/config.go")
+	})
+
+	t.Run("should group files outside of the root under the external directory", func(t *testing.T) {
+		gp := NewGoProject(curPkg, nil, nil)
+		gp.External = config.ExternalInclude
+		assert.NoError(t, gp.Parse(input))
+		files := gp.Root().AllFiles()
+		assert.Len(t, files, 2)
+		assert.Equal(t, curPkg+"/"+ExternalDir+"/"+configPkg+"/config.go", files[0].RelPkgPath)
+		assert.Equal(t, 3, gp.Dirs[curPkg+"/"+ExternalDir].StmtCount)
+		assert.Equal(t, 5, gp.Root().StmtCount)
+	})
+}
+
+func TestGoProject_ParseEmpty(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "cover.prof")
+	assert.NoError(t, os.WriteFile(input, []byte("mode: set\n"), 0o644))
+
+	t.Run("should return error without coverage blocks", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		err := gp.Parse(input)
+		assert.EqualError(t, err, fmt.Sprintf("no coverage data found in %q", input))
+	})
+
+	t.Run("should report an empty project when allowed", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.AllowEmpty = true
+		assert.NoError(t, gp.Parse(input))
+		assert.Equal(t, 0, gp.Root().StmtCount)
+	})
+}
+
+func TestPruneCovered(t *testing.T) {
+	gp := NewGoProject(".", nil, nil)
+	add := func(relPath string, stmts, covered int) {
+		file := &GoFile{GoListItem: NewGoListItem(relPath)}
+		file.StmtCount, file.StmtCoveredCount = stmts, covered
+		gp.SafeDir(filepath.Dir(relPath)).AddFile(file)
+	}
+	add("a/x.go", 2, 2)
+	add("a/b/y.go", 3, 3)
+	add("c/z.go", 4, 1)
+	add("c/w.go", 0, 0)
+	add("c/v.go", 1, 1)
+	gp.Root().Aggregate()
+
+	assert.Equal(t, 3, gp.PruneCovered())
+	assert.Equal(t, 3, gp.Hidden)
+
+	root := gp.Root()
+	assert.Equal(t, 1, len(root.SubDirs))
+	assert.Equal(t, "c", root.SubDirs[0].RelPkgPath)
+	var names []string
+	for _, file := range root.AllFiles() {
+		names = append(names, file.RelPkgPath)
+	}
+	assert.Equal(t, []string{"c/z.go", "c/w.go"}, names)
+	assert.NotContains(t, gp.Dirs, "a")
+	assert.NotContains(t, gp.Dirs, "a/b")
+	assert.Equal(t, 10, root.StmtCount)
+	assert.Equal(t, 7, root.StmtCoveredCount)
+}
+
+func TestWorstFile(t *testing.T) {
+	gp := NewGoProject(".", nil, nil)
+	add := func(relPath string, stmts, covered int) {
+		file := &GoFile{GoListItem: NewGoListItem(relPath)}
+		file.StmtCount, file.StmtCoveredCount = stmts, covered
+		gp.SafeDir(filepath.Dir(relPath)).AddFile(file)
+	}
+
+	t.Run("should return nil when every file is fully covered", func(t *testing.T) {
+		add("a/x.go", 2, 2)
+		add("a/empty.go", 0, 0)
+		assert.Nil(t, gp.Root().WorstFile())
+	})
+
+	t.Run("should return the least covered file", func(t *testing.T) {
+		add("a/y.go", 4, 2)
+		add("b/z.go", 10, 2)
+		assert.Equal(t, "b/z.go", gp.Root().WorstFile().RelPkgPath)
+	})
+
+	t.Run("should break ties by uncovered statements", func(t *testing.T) {
+		add("c/w.go", 20, 4)
+		assert.Equal(t, "c/w.go", gp.Root().WorstFile().RelPkgPath)
+	})
+}
+
+func TestAllFiles(t *testing.T) {
+	gp := NewGoProject(".", nil, nil)
+	x := &GoFile{GoListItem: NewGoListItem("x.go")}
+	y := &GoFile{GoListItem: NewGoListItem("a/y.go")}
+	z := &GoFile{GoListItem: NewGoListItem("a/b/z.go")}
+	gp.Root().AddFile(x)
+	gp.SafeDir("a").AddFile(y)
+	gp.SafeDir("a/b").AddFile(z)
+
+	assert.Equal(t, []*GoFile{z, y, x}, gp.Root().AllFiles())
+	assert.Equal(t, []*GoFile{z}, gp.SafeDir("a/b").AllFiles())
+}
+
+func TestFirstUncoveredLine(t *testing.T) {
+	t.Run("should return the start line of the first block which never ran", func(t *testing.T) {
+		file := &GoFile{Profile: []cover.ProfileBlock{
+			{StartLine: 3, EndLine: 4, Count: 1},
+			{StartLine: 12, EndLine: 14, Count: 0},
+			{StartLine: 8, EndLine: 9, Count: 0},
+		}}
+		assert.Equal(t, 8, file.FirstUncoveredLine())
+	})
+
+	t.Run("should return 0 when every block ran", func(t *testing.T) {
+		file := &GoFile{Profile: []cover.ProfileBlock{{StartLine: 3, EndLine: 4, Count: 1}}}
+		assert.Equal(t, 0, file.FirstUncoveredLine())
+	})
+}
+
+func TestCountChanged(t *testing.T) {
+	file := &GoFile{
+		GoListItem: NewGoListItem("x.go"),
+		Profile: []cover.ProfileBlock{
+			{StartLine: 1, EndLine: 3, NumStmt: 2, Count: 1},
+			{StartLine: 4, EndLine: 6, NumStmt: 3, Count: 0},
+			{StartLine: 7, EndLine: 9, NumStmt: 4, Count: 0},
+		},
+		Changed: map[int]bool{2: true, 3: true, 5: true},
+	}
+	file.CountChanged()
+	assert.Equal(t, 5, file.DiffStmtCount)
+	assert.Equal(t, 2, file.DiffStmtCoveredCount)
+	assert.InDelta(t, 40.0, file.DiffPercent(), 0.001)
+}
+
+func TestGoProject_ParseDoubledHeader(t *testing.T) {
+	curPkg := "github.com/drappier-charles/covreport/reporter/internal"
+	input := filepath.Join(t.TempDir(), "cover.prof")
+	content := fmt.Sprintf("mode: set\n%s/dirs.go:1.1,2.1 2 1\nmode: set\n%s/find.go:1.1,2.1 3 0\n", curPkg, curPkg)
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+	gp := NewGoProject(curPkg, nil, nil)
+	err := gp.Parse(input)
+	assert.NoError(t, err)
+
+	root := gp.Root()
+	assert.Equal(t, 2, len(root.Files))
+	assert.Equal(t, 5, root.StmtCount)
+	assert.Equal(t, 2, root.StmtCoveredCount)
+}
+
+func TestGoProject_ParseWindowsPaths(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "cover.prof")
+	content := "mode: set\nC:\\Users\\me\\app\\x.go:1.1,2.1 2 1\nC:\\Users\\me\\app\\pkg\\y.go:1.1,2.1 3 0\n"
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+	gp := NewGoProject(`C:\Users\me\app`, nil, nil)
+	assert.NoError(t, gp.Parse(input))
+
+	root := gp.Root()
+	assert.Equal(t, "C:/Users/me/app", root.RelPkgPath)
+	assert.Equal(t, 1, len(root.Files))
+	assert.Equal(t, "x.go", root.Files[0].Title)
+	assert.Equal(t, 1, len(root.SubDirs))
+	assert.Equal(t, "C:/Users/me/app/pkg", root.SubDirs[0].RelPkgPath)
+	assert.Equal(t, "y.go", root.SubDirs[0].Files[0].Title)
+	assert.Equal(t, 5, root.StmtCount)
+	assert.Equal(t, 2, root.StmtCoveredCount)
+}
+
+func TestGoProject_ParseRemaps(t *testing.T) {
+	temp := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(temp, "pkg"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(temp, "pkg", "x.go"), []byte("package pkg\n\nfunc f() {\n}\n"), 0o644))
+	input := filepath.Join(temp, "cover.prof")
+	assert.NoError(t, os.WriteFile(input, []byte("mode: set\n/workspace/app/pkg/x.go:3.10,4.2 1 1\n"), 0o644))
+
+	gp := NewGoProject(temp, nil, nil)
+	gp.Remaps = []config.PathRemap{{From: "/workspace/app", To: filepath.ToSlash(temp)}}
+	assert.NoError(t, gp.Parse(input))
+
+	files := gp.Root().AllFiles()
+	assert.Len(t, files, 1)
+	assert.Equal(t, filepath.Join(temp, "pkg", "x.go"), files[0].ABSPath)
+	assert.Equal(t, 4, files[0].LineCount)
+	assert.False(t, files[0].Stale)
+}
+
+func TestGoProject_ParseDuplicatedBlocks(t *testing.T) {
+	temp := t.TempDir()
+	input := filepath.Join(temp, "cover.prof")
+
+	t.Run("should sum the counts of duplicated block lines", func(t *testing.T) {
+		content := "mode: count\nC:/app/x.go:1.1,2.1 2 3\nC:/app/x.go:3.1,4.1 1 0\nC:/app/x.go:1.1,2.1 2 4\n"
+		assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+		gp := NewGoProject("C:/app", nil, nil)
+		assert.NoError(t, gp.Parse(input))
+		file := gp.Root().Files[0]
+		assert.Equal(t, []int{7, 0}, []int{file.Profile[0].Count, file.Profile[1].Count})
+		assert.Equal(t, 3, file.StmtCount)
+		assert.Equal(t, 2, file.StmtCoveredCount)
+	})
+
+	t.Run("should sum the counts of the same file spelled with both separators", func(t *testing.T) {
+		content := "mode: count\nC:\\app\\x.go:1.1,2.1 2 3\nC:\\app\\x.go:3.1,4.1 1 0\nC:/app/x.go:1.1,2.1 2 4\n"
+		assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+		gp := NewGoProject("C:/app", nil, nil)
+		assert.NoError(t, gp.Parse(input))
+		assert.Len(t, gp.Root().Files, 1)
+		file := gp.Root().Files[0]
+		assert.Len(t, file.Profile, 2)
+		assert.Equal(t, []int{7, 0}, []int{file.Profile[0].Count, file.Profile[1].Count})
+		assert.Equal(t, 3, file.StmtCount)
+		assert.Equal(t, 2, gp.Root().StmtCoveredCount)
+	})
+}
+
+func TestGoProject_ParseScoreExcludes(t *testing.T) {
+	temp := t.TempDir()
+	input := filepath.Join(temp, "cover.prof")
+	content := "mode: set\n" +
+		temp + "/x.go:1.1,2.1 2 1\n" +
+		temp + "/x.pb.go:1.1,2.1 3 0\n" +
+		temp + "/mocks/m.go:1.1,2.1 4 0\n"
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+	gp := NewGoProject(temp, nil, nil)
+	gp.ScoreExcludes = []string{"*.pb.go", temp + "/mocks"}
+	assert.NoError(t, gp.Parse(input))
+
+	unscored := make(map[string]bool)
+	for _, file := range gp.Root().AllFiles() {
+		unscored[path.Base(file.RelPkgPath)] = file.Unscored
+	}
+	assert.Equal(t, map[string]bool{"x.go": false, "x.pb.go": true, "m.go": true}, unscored)
+
+	root := gp.Root()
+	assert.Equal(t, 2, root.StmtCount)
+	assert.Equal(t, 2, root.StmtCoveredCount)
+	assert.NoError(t, gp.CheckThresholds(100, nil))
+	assert.NoError(t, gp.CheckZeroCoverage())
+	assert.Nil(t, root.WorstFile())
+}
+
+func TestGoProject_ParseGoOnly(t *testing.T) {
+	temp := t.TempDir()
+	input := filepath.Join(temp, "cover.prof")
+	content := "mode: set\n" +
+		temp + "/x.go:3.14,5.2 2 1\n" +
+		temp + "/add.c:3.24,5.2 3 0\n" +
+		temp + "/y_amd64.s:3.1,4.5 4 0\n"
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+	for goOnly, want := range map[bool]int{false: 9, true: 2} {
+		gp := NewGoProject(temp, nil, nil)
+		gp.GoOnly = goOnly
+		assert.NoError(t, gp.Parse(input))
+		assert.Len(t, gp.Root().AllFiles(), 3)
+		assert.Equal(t, want, gp.Root().StmtCount)
+	}
+}
+
+func TestGoProject_ParseSameFile(t *testing.T) {
+	temp := t.TempDir()
+	real := filepath.Join(temp, "real")
+	assert.NoError(t, os.Mkdir(real, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(real, "x.go"), []byte("package x\n"), 0o644))
+	assert.NoError(t, os.Symlink(real, filepath.Join(temp, "link")))
+
+	newProject := func(mode string) *GoProject {
+		input := filepath.Join(temp, mode+".prof")
+		content := fmt.Sprintf("mode: %s\n%s:1.1,2.1 2 1\n%s:3.1,4.1 3 0\n%s:1.1,2.1 2 3\n%s:3.1,4.1 3 0\n%s:5.1,6.1 4 1\n",
+			mode, filepath.Join(real, "x.go"), filepath.Join(real, "x.go"),
+			filepath.Join(temp, "link", "x.go"), filepath.Join(temp, "link", "x.go"), filepath.Join(temp, "link", "x.go"))
+		assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+
+		var logs strings.Builder
+		gp := NewGoProject(temp, nil, nil)
+		gp.Logger = NewLogger(&logs, LogNormal)
+		assert.NoError(t, gp.Parse(input))
+		assert.Contains(t, logs.String(), "are the same file, merging their profiles")
+		return gp
+	}
+
+	t.Run("should merge the profiles of the same file", func(t *testing.T) {
+		gp := newProject("count")
+		files := gp.Root().AllFiles()
+		assert.Len(t, files, 1)
+		assert.Equal(t, 9, files[0].StmtCount)
+		assert.Equal(t, 6, files[0].StmtCoveredCount)
+		assert.Equal(t, []int{4, 0, 1}, []int{files[0].Profile[0].Count, files[0].Profile[1].Count, files[0].Profile[2].Count})
+		assert.Equal(t, 9, gp.Root().StmtCount)
+		merged, ok := gp.relPath(filepath.Join(real, "x.go"))
+		assert.True(t, ok)
+		assert.NotContains(t, gp.Dirs, path.Dir(merged))
+	})
+
+	t.Run("should not add up set mode counts", func(t *testing.T) {
+		files := newProject("set").Root().AllFiles()
+		assert.Len(t, files, 1)
+		assert.Equal(t, 3, files[0].Profile[0].Count)
+	})
+}
+
+func TestGoProject_ParseStale(t *testing.T) {
+	temp := t.TempDir()
+	source := filepath.Join(temp, "x.go")
+	assert.NoError(t, os.WriteFile(source, []byte("package x\n"), 0o644))
+	input := filepath.Join(temp, "cover.prof")
+	assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+
+	t.Run("should warn when the profile is stale", func(t *testing.T) {
+		var logs strings.Builder
+		gp := NewGoProject(temp, nil, nil)
+		gp.Logger = NewLogger(&logs, LogNormal)
+		assert.NoError(t, gp.Parse(input))
+		assert.Contains(t, logs.String(), "profile may be stale, 1 file was edited since it was generated: "+source)
+		assert.Equal(t, []string{"profile may be stale, 1 block ends past the last line of 1 file: " + source}, gp.Warnings)
+	})
+
+	t.Run("should fail when the profile is stale in strict mode", func(t *testing.T) {
+		gp := NewGoProject(temp, nil, nil)
+		gp.Strict = true
+		assert.EqualError(t, gp.Parse(input), "profile is stale, 1 file was edited since it was generated: "+source)
+	})
+}
+
+func TestReadSource(t *testing.T) {
+	temp := t.TempDir()
+	write := func(name, src string) string {
+		filename := filepath.Join(temp, name)
+		assert.NoError(t, os.WriteFile(filename, []byte(src), 0o644))
+		return filename
+	}
+
+	t.Run("should count lines and find functions of go files", func(t *testing.T) {
+		file := &GoFile{GoListItem: NewGoListItem("x/x.go"), ABSPath: write("x.go", "package x\n\n// f does nothing.\nfunc f() {}\n")}
+		file.ReadSource(nil, nil)
+		assert.Equal(t, 4, file.LineCount)
+		assert.Len(t, file.Funcs, 1)
+	})
+
+	t.Run("should count a last line without newline", func(t *testing.T) {
+		file := &GoFile{GoListItem: NewGoListItem("x/x.tmpl"), ABSPath: write("x.tmpl", "a\nb")}
+		file.ReadSource(nil, nil)
+		assert.Equal(t, 2, file.LineCount)
+		assert.Nil(t, file.Funcs)
+	})
+
+	t.Run("should aggregate line counts", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{LineCount: 10}})
+		gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: &GoListItem{LineCount: 5}})
+		gp.Root().Aggregate()
+		assert.Equal(t, 15, gp.Root().LineCount)
+	})
+
+	t.Run("should mark files whose profile ends past the last line as stale", func(t *testing.T) {
+		file := &GoFile{GoListItem: NewGoListItem("x/x.go"), ABSPath: write("x.go", "package x\n\nfunc f() {}\n")}
+		file.Profile = []cover.ProfileBlock{{StartLine: 3, StartCol: 10, EndLine: 3, EndCol: 12}}
+		file.ReadSource(nil, nil)
+		assert.False(t, file.Stale)
+
+		assert.Empty(t, file.OutOfRange)
+
+		file.Profile = append(file.Profile,
+			cover.ProfileBlock{StartLine: 5, StartCol: 10, EndLine: 7, EndCol: 2},
+			cover.ProfileBlock{StartLine: 8, StartCol: 1, EndLine: 9, EndCol: 2})
+		file.ReadSource(nil, nil)
+		assert.True(t, file.Stale)
+		assert.Equal(t, file.Profile[1:], file.OutOfRange)
+	})
+
+	t.Run("should leave line count empty when cannot read file", func(t *testing.T) {
+		file := &GoFile{GoListItem: NewGoListItem("x/y.go"), ABSPath: "not-exist.go"}
+		file.ReadSource(nil, nil)
+		assert.Equal(t, 0, file.LineCount)
+	})
+}
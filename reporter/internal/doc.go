@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// componentTagRe matches the comment lines tagging the component of a package, like "component: billing".
+var componentTagRe = regexp.MustCompile(`^component:\s*(\S.*?)\s*$`)
+
+// ReadDoc reads the package comment of the directory from the Go files of its package, and its component tag:
+// a "// component: name" comment line above a package clause, left out of the package comment.
+// Directories which aren't Go packages, or which can't be read, are left without doc nor component.
+func (dir *GoDir) ReadDoc() {
+	var pkgDir, pkgName string
+	fset := token.NewFileSet()
+	for _, file := range dir.Files {
+		if filepath.Ext(file.ABSPath) != ".go" {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file.ABSPath, nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		pkgDir, pkgName = filepath.Dir(file.ABSPath), parsed.Name.Name
+		break
+	}
+	if pkgDir == "" {
+		return
+	}
+
+	entries, err := os.ReadDir(pkgDir)
+	if err != nil {
+		return
+	}
+	// The package comment may lie in any file of the package, such as a doc.go file without statements.
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, filepath.Join(pkgDir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || parsed.Name.Name != pkgName {
+			continue
+		}
+		files = append(files, parsed)
+		if dir.Component == "" {
+			dir.Component = findComponentTag(parsed)
+		}
+	}
+	pkg, err := doc.NewFromFiles(fset, files, dir.RelPkgPath)
+	if err != nil {
+		return
+	}
+	var lines []string
+	for _, line := range strings.Split(pkg.Doc, "\n") {
+		if !componentTagRe.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	dir.Doc = strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// findComponentTag returns the component tagged by the comments of the parsed file, if any.
+func findComponentTag(file *ast.File) string {
+	for _, group := range file.Comments {
+		for _, line := range strings.Split(group.Text(), "\n") {
+			if match := componentTagRe.FindStringSubmatch(line); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
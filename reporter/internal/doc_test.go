@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadDoc(t *testing.T) {
+	temp := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(temp, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+		assert.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+		return filename
+	}
+	newDir := func(relPkgPath string, absPaths ...string) *GoDir {
+		dir := &GoDir{GoListItem: NewGoListItem(relPkgPath)}
+		for _, absPath := range absPaths {
+			dir.AddFile(&GoFile{GoListItem: NewGoListItem(filepath.Join(relPkgPath, filepath.Base(absPath))), ABSPath: absPath})
+		}
+		return dir
+	}
+
+	t.Run("should read the package comment of another file of the package", func(t *testing.T) {
+		write("x/doc.go", "// Package x does things.\n//\n// It does them well.\npackage x\n")
+		write("x/x_test.go", "// Package x_test tests things.\npackage x_test\n")
+		dir := newDir("x", write("x/x.go", "package x\n\nfunc X() {}\n"))
+		dir.ReadDoc()
+		assert.Equal(t, "Package x does things.\n\nIt does them well.", dir.Doc)
+	})
+
+	t.Run("should leave undocumented packages without doc", func(t *testing.T) {
+		dir := newDir("y", write("y/y.go", "package y\n"))
+		dir.ReadDoc()
+		assert.Empty(t, dir.Doc)
+	})
+
+	t.Run("should read the component tag of the package", func(t *testing.T) {
+		write("c/doc.go", "// Package c bills things.\n//\n// component: billing\npackage c\n")
+		dir := newDir("c", write("c/c.go", "package c\n"))
+		dir.ReadDoc()
+		assert.Equal(t, "billing", dir.Component)
+		assert.Equal(t, "Package c bills things.", dir.Doc)
+
+		dir = newDir("d", write("d/d.go", "// component: payments team\n\npackage d\n"))
+		dir.ReadDoc()
+		assert.Equal(t, "payments team", dir.Component)
+		assert.Empty(t, dir.Doc)
+	})
+
+	t.Run("should leave directories which aren't Go packages without doc", func(t *testing.T) {
+		dir := newDir("z", write("z/z.txt", "// Package z\n"))
+		dir.ReadDoc()
+		assert.Empty(t, dir.Doc)
+		newDir("empty").ReadDoc()
+	})
+}
@@ -8,12 +8,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	"github.com/drappier-charles/covreport/reporter/config"
 	"golang.org/x/tools/cover"
 )
 
@@ -27,12 +29,14 @@ type Pkg struct {
 }
 
 // findPkgs finds the location of every package we care about by running go list.
-func findPkgs(profiles []*cover.Profile) (map[string]*Pkg, error) {
+// Packages go list can't locate are looked up in the given modules of a workspace, then in the module
+// of the working directory. The fallback is traced by the debug messages of the Logger.
+func findPkgs(profiles []*cover.Profile, modules []*Module, logger *Logger) (map[string]*Pkg, error) {
 	// Run go list to find the location of every package we care about.
 	pkgs := make(map[string]*Pkg)
 	var list []string
 	for _, profile := range profiles {
-		if strings.HasPrefix(profile.FileName, ".") || filepath.IsAbs(profile.FileName) {
+		if strings.HasPrefix(profile.FileName, ".") || isAbsPath(profile.FileName) {
 			// Ignore relative or absolute path.
 			continue
 		}
@@ -55,6 +59,13 @@ func findPkgs(profiles []*cover.Profile) (map[string]*Pkg, error) {
 	cmd.Stderr = &stderr
 	stdout, err := cmd.Output()
 	if err != nil {
+		// go list fails outside of a module, e.g. when run from a directory of a module
+		// the packages don't belong to: fall back on the nearest go.mod if it knows them all.
+		logger.Debugf("go list failed, resolving packages from the modules: %v", err)
+		resolveModulesPkgs(pkgs, modules)
+		if resolveModulePkgs(pkgs, ".") {
+			return pkgs, nil
+		}
 		return nil, fmt.Errorf("cannot run go list: %v\n%s", err, stderr.Bytes())
 	}
 	dec := json.NewDecoder(bytes.NewReader(stdout))
@@ -69,12 +80,92 @@ func findPkgs(profiles []*cover.Profile) (map[string]*Pkg, error) {
 		}
 		pkgs[pkg.ImportPath] = &pkg
 	}
+	resolveModulesPkgs(pkgs, modules)
+	resolveModulePkgs(pkgs, ".")
 	return pkgs, nil
 }
 
+// resolveModulePkgs resolves the directory of the packages go list didn't locate
+// from the module of the nearest go.mod above dir.
+// It reports whether every package has a directory afterwards.
+func resolveModulePkgs(pkgs map[string]*Pkg, dir string) bool {
+	var modules []*Module
+	if modPath, modDir, err := findModule(dir); err == nil {
+		modules = append(modules, &Module{Path: modPath, Dir: modDir})
+	}
+	return resolveModulesPkgs(pkgs, modules)
+}
+
+// resolveModulesPkgs resolves the directory of the packages go list didn't locate from the given modules.
+// It reports whether every package has a directory afterwards.
+func resolveModulesPkgs(pkgs map[string]*Pkg, modules []*Module) bool {
+	resolved := true
+PKG_LOOP:
+	for importPath, pkg := range pkgs {
+		if pkg != nil && pkg.Dir != "" {
+			continue
+		}
+		for _, module := range modules {
+			if rel, ok := trimModulePath(importPath, module.Path); ok {
+				pkgDir := filepath.Join(module.Dir, filepath.FromSlash(rel))
+				if info, err := os.Stat(pkgDir); err == nil && info.IsDir() {
+					pkgs[importPath] = &Pkg{ImportPath: importPath, Dir: pkgDir}
+					continue PKG_LOOP
+				}
+			}
+		}
+		resolved = false
+	}
+	return resolved
+}
+
+// trimModulePath returns the path of the package relative to the root of the module.
+func trimModulePath(importPath, modPath string) (string, bool) {
+	if importPath == modPath {
+		return ".", true
+	}
+	rel := strings.TrimPrefix(importPath, modPath+"/")
+	return rel, rel != importPath
+}
+
+// findModule walks up from dir to the nearest go.mod, and returns the module path it declares
+// along with the absolute directory of the module.
+func findModule(dir string) (modPath, modDir string, err error) {
+	modDir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err == nil {
+			modPath, err := parseModulePath(data)
+			return modPath, modDir, err
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", "", err
+		}
+		parent := filepath.Dir(modDir)
+		if parent == modDir {
+			return "", "", errors.New("go.mod not found")
+		}
+		modDir = parent
+	}
+}
+
+// parseModulePath returns the module path declared by the content of a go.mod file.
+func parseModulePath(data []byte) (string, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`"), nil
+		}
+	}
+	return "", errors.New("module directive not found in go.mod")
+}
+
 // findFile finds the location of the named file in GOROOT, GOPATH etc.
 func findFile(pkgs map[string]*Pkg, file string) (string, error) {
-	if strings.HasPrefix(file, ".") || filepath.IsAbs(file) {
+	if strings.HasPrefix(file, ".") || isAbsPath(file) {
 		// Ignore relative or absolute path.
 		return file, nil
 	}
@@ -89,3 +180,31 @@ func findFile(pkgs map[string]*Pkg, file string) (string, error) {
 	}
 	return "", fmt.Errorf("did not find package for %s in go list output", file)
 }
+
+// normalizePath returns the file name of a profile with forward slashes,
+// so the profiles generated on Windows are placed in the tree like the others on any OS.
+func normalizePath(name string) string {
+	return strings.ReplaceAll(name, "\\", "/")
+}
+
+// remapPath rewrites the leading path of the normalized file name of a profile with the first remapping
+// whose From is a prefix of it, on a path boundary. Names no remapping matches are returned as is.
+func remapPath(name string, remaps []config.PathRemap) string {
+	for _, remap := range remaps {
+		from := normalizePath(remap.From)
+		if matchPathPrefix(name, from) {
+			return strings.TrimSuffix(normalizePath(remap.To), "/") + strings.TrimPrefix(name, strings.TrimSuffix(from, "/"))
+		}
+	}
+	return name
+}
+
+// isAbsPath reports whether the normalized file name of a profile is absolute,
+// on this OS or on Windows, starting with a drive letter like C:/.
+func isAbsPath(name string) bool {
+	if filepath.IsAbs(name) {
+		return true
+	}
+	return len(name) >= 3 && name[1] == ':' && name[2] == '/' &&
+		('a' <= name[0] && name[0] <= 'z' || 'A' <= name[0] && name[0] <= 'Z')
+}
@@ -2,10 +2,12 @@ package internal
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/drappier-charles/covreport/reporter/config"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/tools/cover"
 )
@@ -24,7 +26,7 @@ func TestFind(t *testing.T) {
 			{FileName: curFileURI},
 		}
 
-		pkgs, err = findPkgs(profiles)
+		pkgs, err = findPkgs(profiles, nil, nil)
 		assert.NoError(t, err)
 
 		pkg := pkgs[curPkg]
@@ -39,3 +41,65 @@ func TestFind(t *testing.T) {
 		assert.Equal(t, curFilename, filename)
 	})
 }
+
+func TestFindModule(t *testing.T) {
+	temp := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(temp, "go.mod"), []byte("// comment\nmodule \"example.com/m\"\n\ngo 1.21\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(temp, "a", "b"), 0o755))
+
+	t.Run("should find the nearest go.mod above dir", func(t *testing.T) {
+		modPath, modDir, err := findModule(filepath.Join(temp, "a", "b"))
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com/m", modPath)
+		assert.Equal(t, temp, modDir)
+	})
+
+	t.Run("should resolve packages of the module go list missed", func(t *testing.T) {
+		pkgs := map[string]*Pkg{
+			"example.com/m":     nil,
+			"example.com/m/a/b": {ImportPath: "example.com/m/a/b", Error: &struct{ Err string }{"not found"}},
+		}
+		assert.True(t, resolveModulePkgs(pkgs, filepath.Join(temp, "a")))
+		assert.Equal(t, temp, pkgs["example.com/m"].Dir)
+		assert.Equal(t, filepath.Join(temp, "a", "b"), pkgs["example.com/m/a/b"].Dir)
+
+		filename, err := findFile(pkgs, "example.com/m/a/b/x.go")
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(temp, "a", "b", "x.go"), filename)
+	})
+
+	t.Run("should leave packages outside of the module unresolved", func(t *testing.T) {
+		pkgs := map[string]*Pkg{
+			"example.com/other": nil,
+			"example.com/m/c":   nil,
+			"example.com/mm":    nil,
+		}
+		assert.False(t, resolveModulePkgs(pkgs, temp))
+		assert.Nil(t, pkgs["example.com/other"])
+		assert.Nil(t, pkgs["example.com/m/c"])
+		assert.Nil(t, pkgs["example.com/mm"])
+	})
+}
+
+func TestIsAbsPath(t *testing.T) {
+	assert.True(t, isAbsPath("C:/Users/me/app/x.go"))
+	assert.True(t, isAbsPath("d:/app/x.go"))
+	assert.Equal(t, filepath.IsAbs("/app/x.go"), isAbsPath("/app/x.go"))
+	assert.False(t, isAbsPath("github.com/me/app/x.go"))
+	assert.False(t, isAbsPath("C:x.go"))
+	assert.Equal(t, "C:/Users/me/app/x.go", normalizePath(`C:\Users\me\app\x.go`))
+}
+
+func TestRemapPath(t *testing.T) {
+	remaps := []config.PathRemap{
+		{From: "/workspace/app/", To: "/home/me/app"},
+		{From: "/workspace", To: "/srv/"},
+		{From: `C:\build`, To: "."},
+	}
+	assert.Equal(t, "/home/me/app/pkg/x.go", remapPath("/workspace/app/pkg/x.go", remaps))
+	assert.Equal(t, "/srv/lib/y.go", remapPath("/workspace/lib/y.go", remaps))
+	assert.Equal(t, "/workspace2/y.go", remapPath("/workspace2/y.go", remaps))
+	assert.Equal(t, "./x.go", remapPath("C:/build/x.go", remaps))
+	assert.Equal(t, "github.com/me/app/x.go", remapPath("github.com/me/app/x.go", remaps))
+	assert.Equal(t, "/workspace/x.go", remapPath("/workspace/x.go", nil))
+}
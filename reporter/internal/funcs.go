@@ -0,0 +1,100 @@
+// This code is adapted from the Go standard library's cover tool:
+// https://github.com/golang/go/blob/master/src/cmd/cover/func.go
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	"golang.org/x/tools/cover"
+)
+
+// GoFunc is a function or method declared in a GoFile, with the coverage of its body.
+type GoFunc struct {
+	*GoListItem
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+}
+
+// parseFuncs finds the functions of the given source of the file, and counts its branches, see countBranches.
+func (file *GoFile) parseFuncs(src []byte) {
+	fset := token.NewFileSet()
+	// A partial syntax tree is still useful when the source doesn't parse.
+	parsed, _ := parser.ParseFile(fset, file.ABSPath, src, 0)
+	if parsed == nil {
+		return
+	}
+
+	file.BranchCount, file.BranchCoveredCount = countBranches(fset, parsed, file.Profile)
+	file.Funcs = nil
+	for _, decl := range parsed.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		name := funcName(fn)
+		start := fset.Position(fn.Pos())
+		end := fset.Position(fn.End())
+		f := &GoFunc{
+			GoListItem: &GoListItem{
+				RelPkgPath: file.RelPkgPath,
+				ID:         itemID(file.RelPkgPath + "#" + name),
+				Title:      name,
+				LineCount:  end.Line - start.Line + 1,
+			},
+			StartLine: start.Line,
+			StartCol:  start.Column,
+			EndLine:   end.Line,
+			EndCol:    end.Column,
+		}
+		f.count(file.Profile)
+		file.Funcs = append(file.Funcs, f)
+	}
+}
+
+// count sums the statements of the profile blocks lying within the function.
+func (f *GoFunc) count(blocks []cover.ProfileBlock) {
+	for _, b := range blocks {
+		if b.StartLine > f.EndLine || (b.StartLine == f.EndLine && b.StartCol >= f.EndCol) {
+			// Block starts after the function.
+			continue
+		}
+		if b.EndLine < f.StartLine || (b.EndLine == f.StartLine && b.EndCol <= f.StartCol) {
+			// Block ends before the function.
+			continue
+		}
+		f.StmtCount += b.NumStmt
+		if b.Count > 0 {
+			f.StmtCoveredCount += b.NumStmt
+		}
+	}
+}
+
+// funcName returns the name of the function, qualified by its receiver type for methods.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	return fmt.Sprintf("(%s).%s", recvName(fn.Recv.List[0].Type), fn.Name.Name)
+}
+
+// recvName returns the type name of a method receiver, such as "*T" or "T".
+func recvName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + recvName(t.X)
+	case *ast.IndexExpr:
+		return recvName(t.X)
+	case *ast.IndexListExpr:
+		return recvName(t.X)
+	case *ast.ParenExpr:
+		return recvName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
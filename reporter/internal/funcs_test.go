@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestGoFile_parseFuncs(t *testing.T) {
+	src := `package x
+
+type T[K any] struct{}
+
+func plain() {
+	println()
+}
+
+func (t *T[K]) method() {
+	println()
+	println()
+}
+
+func (T[K]) value() {}
+`
+
+	t.Run("should attribute blocks to enclosing functions", func(t *testing.T) {
+		file := &GoFile{
+			GoListItem: NewGoListItem("x/x.go"),
+			ABSPath:    "x.go",
+			Profile: []cover.ProfileBlock{
+				{StartLine: 5, StartCol: 15, EndLine: 7, EndCol: 2, NumStmt: 1, Count: 1},
+				{StartLine: 9, StartCol: 25, EndLine: 12, EndCol: 2, NumStmt: 2, Count: 0},
+			},
+		}
+		file.parseFuncs([]byte(src))
+
+		assert.Len(t, file.Funcs, 3)
+		assert.Equal(t, "plain", file.Funcs[0].Title)
+		assert.Equal(t, 1, file.Funcs[0].StmtCount)
+		assert.Equal(t, 1, file.Funcs[0].StmtCoveredCount)
+		assert.Equal(t, "(*T).method", file.Funcs[1].Title)
+		assert.Equal(t, 2, file.Funcs[1].StmtCount)
+		assert.Equal(t, 0, file.Funcs[1].StmtCoveredCount)
+		assert.Equal(t, 9, file.Funcs[1].StartLine)
+		assert.Equal(t, 12, file.Funcs[1].EndLine)
+		assert.Equal(t, 4, file.Funcs[1].LineCount)
+		assert.Equal(t, "(T).value", file.Funcs[2].Title)
+		assert.Equal(t, 0, file.Funcs[2].StmtCount)
+	})
+
+}
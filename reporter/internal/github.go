@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReportGitHubActions writes a GitHub Actions warning command for every uncovered profile block.
+// File paths are made relative to the working directory, which is the repository root in workflows.
+func (gp *GoProject) ReportGitHubActions(wr io.Writer) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	var count, skipped int
+	for _, file := range gp.Root().AllFiles() {
+		path := file.ABSPath
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = filepath.ToSlash(rel)
+		}
+
+		for _, block := range file.Profile {
+			if block.Count > 0 {
+				continue
+			}
+			if gp.MaxAnnotations > 0 && count >= gp.MaxAnnotations {
+				skipped++
+				continue
+			}
+			count++
+			if _, err := fmt.Fprintf(wr, "::warning file=%s,line=%d,endLine=%d::not covered\n", escapeWorkflowProperty(path), block.StartLine, block.EndLine); err != nil {
+				return err
+			}
+		}
+	}
+
+	if skipped > 0 {
+		_, err = fmt.Fprintf(wr, "::notice::%d more uncovered blocks were not annotated\n", skipped)
+	}
+	return err
+}
+
+// escapeWorkflowProperty escapes a value used as a property of a workflow command.
+func escapeWorkflowProperty(value string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	).Replace(value)
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestReportGitHubActions(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	newProject := func() *GoProject {
+		gp := NewGoProject(".", nil, nil)
+		gp.SafeDir("a").AddFile(&GoFile{
+			GoListItem: NewGoListItem("a/x.go"),
+			ABSPath:    filepath.Join(wd, "a", "x.go"),
+			Profile: []cover.ProfileBlock{
+				{StartLine: 1, EndLine: 2, Count: 0},
+				{StartLine: 3, EndLine: 4, Count: 1},
+				{StartLine: 5, EndLine: 9, Count: 0},
+			},
+		})
+		return gp
+	}
+
+	t.Run("should annotate uncovered blocks", func(t *testing.T) {
+		var buf strings.Builder
+		err := newProject().ReportGitHubActions(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "::warning file=a/x.go,line=1,endLine=2::not covered\n::warning file=a/x.go,line=5,endLine=9::not covered\n", buf.String())
+	})
+
+	t.Run("should cap the number of annotations", func(t *testing.T) {
+		gp := newProject()
+		gp.MaxAnnotations = 1
+		var buf strings.Builder
+		err := gp.ReportGitHubActions(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "::warning file=a/x.go,line=1,endLine=2::not covered\n::notice::1 more uncovered blocks were not annotated\n", buf.String())
+	})
+}
+
+func TestEscapeWorkflowProperty(t *testing.T) {
+	assert.Equal(t, "a%2Cb%3Ac%25d%0A", escapeWorkflowProperty("a,b:c%d\n"))
+}
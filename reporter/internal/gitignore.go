@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// GitIgnore matches files against the untracked files git ignores in a repository, listed once by git when loaded,
+// so that .gitignore files, .git/info/exclude and core.excludesFile apply as git applies them.
+// Like git, tracked files are never ignored, whatever the patterns matching them.
+// It is safe for concurrent use.
+type GitIgnore struct {
+	// Root is the absolute directory of the repository.
+	Root string
+	// ignored holds the paths of the ignored files and directories, relative to Root, those of the directories
+	// ending with "/", git not listing the files under them.
+	ignored map[string]bool
+}
+
+// LoadGitIgnore returns a GitIgnore for the git repository holding dir, listing its ignored files with
+// "git ls-files --others --ignored --exclude-standard".
+func LoadGitIgnore(dir string) (*GitIgnore, error) {
+	top, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.FromSlash(strings.TrimSpace(string(top)))
+
+	out, err := runGit(root, "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory")
+	if err != nil {
+		return nil, err
+	}
+	gi := &GitIgnore{Root: root, ignored: make(map[string]bool)}
+	for _, name := range bytes.Split(out, []byte{0}) {
+		if len(name) > 0 {
+			gi.ignored[string(name)] = true
+		}
+	}
+	return gi, nil
+}
+
+// Match reports whether the file of the given path is ignored, or one of the directories above it.
+// Files outside of the repository are never ignored, nor by a nil GitIgnore.
+func (gi *GitIgnore) Match(path string) bool {
+	if gi == nil {
+		return false
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, ok := gi.rel(path)
+	if !ok {
+		// git names the root by its real path, which may not be the one of the file.
+		if path, err = filepath.EvalSymlinks(path); err != nil {
+			return false
+		}
+		if rel, ok = gi.rel(path); !ok {
+			return false
+		}
+	}
+
+	if gi.ignored[rel] {
+		return true
+	}
+	for dir := rel; ; {
+		i := strings.LastIndexByte(dir, '/')
+		if i < 0 {
+			return false
+		}
+		dir = dir[:i]
+		if gi.ignored[dir+"/"] {
+			return true
+		}
+	}
+}
+
+// rel returns the slash-separated path of the absolute path relative to the root, if inside the repository.
+func (gi *GitIgnore) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(gi.Root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitIgnore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	temp, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+	writeFile := func(name, content string) {
+		name = filepath.Join(temp, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		assert.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = temp
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	writeFile(".git/info/exclude", "local.go\n")
+	writeFile(".gitignore", "# generated code\n*_gen.go\n!keep_gen.go\n/build/\ndocs/*.go\nvendor/\n**/mocks/**\n\\#hash.go\n")
+	writeFile("app/.gitignore", "tmp.go\n!/local.go\n")
+
+	tests := []struct {
+		Path    string
+		Ignored bool
+	}{
+		{"app/x.go", false},
+		{"app/x_gen.go", true},
+		{"app/deep/y_gen.go", true},
+		{"app/keep_gen.go", false},
+		{"app/tracked_gen.go", false},
+		{"build/x.go", true},
+		{"app/build/x.go", false},
+		{"docs/x.go", true},
+		{"docs/sub/x.go", false},
+		{"app/vendor/x.go", true},
+		{"app/mocks/x.go", true},
+		{"app/mocks/sub/x.go", true},
+		{"#hash.go", true},
+		{"local.go", true},
+		{"app/local.go", false},
+		{"app/tmp.go", true},
+		{"tmp.go", false},
+	}
+	for _, tc := range tests {
+		writeFile(tc.Path, "package x\n")
+	}
+	git("add", "-f", "app/tracked_gen.go")
+
+	gi, err := LoadGitIgnore(filepath.Join(temp, "app"))
+	assert.NoError(t, err)
+	assert.Equal(t, temp, gi.Root)
+	for _, tc := range tests {
+		assert.Equal(t, tc.Ignored, gi.Match(filepath.Join(temp, filepath.FromSlash(tc.Path))), tc.Path)
+	}
+
+	t.Run("should not match files outside of the repository", func(t *testing.T) {
+		assert.False(t, gi.Match(filepath.Join(filepath.Dir(temp), "x_gen.go")))
+	})
+
+	t.Run("should not match when nil", func(t *testing.T) {
+		var gi *GitIgnore
+		assert.False(t, gi.Match(filepath.Join(temp, "app", "x_gen.go")))
+	})
+
+	t.Run("should return error outside of a repository", func(t *testing.T) {
+		_, err := LoadGitIgnore(t.TempDir())
+		assert.ErrorContains(t, err, "cannot run git rev-parse")
+	})
+
+	t.Run("should leave ignored files out of the report", func(t *testing.T) {
+		writeFile("app/gen/y_gen.go", "package gen\n")
+		writeFile("go.mod", "module example.com/m\n")
+		writeFile("cover.prof", "mode: set\nexample.com/m/app/x.go:1.1,1.12 1 1\nexample.com/m/app/x_gen.go:1.1,1.12 1 0\n"+
+			"example.com/m/app/gen/y_gen.go:1.1,1.12 1 0\n")
+		gi, err := LoadGitIgnore(temp)
+		assert.NoError(t, err)
+		wd, err := os.Getwd()
+		assert.NoError(t, err)
+		assert.NoError(t, os.Chdir(temp))
+		defer os.Chdir(wd)
+
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.GitIgnore = gi
+		assert.NoError(t, gp.Parse("cover.prof"))
+		files := gp.Root().AllFiles()
+		assert.Len(t, files, 1)
+		assert.Equal(t, "example.com/m/app/x.go", files[0].RelPkgPath)
+		assert.NotContains(t, gp.Dirs, "example.com/m/app/gen")
+	})
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+// rootGroup names the group of the files lying directly in the initial directory when grouping by top directory.
+const rootGroup = "."
+
+// noExtGroup names the group of the files without extension when grouping by extension.
+const noExtGroup = "(none)"
+
+// unownedGroup names the group of the files without code owners when grouping by owner.
+const unownedGroup = "(unowned)"
+
+// unassignedGroup names the group of the files of packages without component tag when grouping by component.
+const unassignedGroup = "(unassigned)"
+
+// Groups rolls the coverage of the files under the initial directory up into a flat list of groups, sorted by name:
+// by top directory, the first path segment below the initial directory, by file extension,
+// by code owners, files owned by several owners making a group of their own, or by the component tags of packages.
+// The groups are items titled by their name, aggregating the statements of their files.
+func (gp *GoProject) Groups(groupBy string) ([]*GoListItem, error) {
+	initialDir := gp.InitialDir()
+
+	var key func(file *GoFile) string
+	switch groupBy {
+	case config.GroupByTopDir:
+		key = func(file *GoFile) string {
+			rel := strings.TrimPrefix(file.RelPkgPath, initialDir.RelPkgPath+"/")
+			if i := strings.Index(rel, "/"); i >= 0 {
+				return rel[:i]
+			}
+			return rootGroup
+		}
+	case config.GroupByExt:
+		key = func(file *GoFile) string {
+			if ext := path.Ext(file.RelPkgPath); ext != "" {
+				return ext
+			}
+			return noExtGroup
+		}
+	case config.GroupByOwner:
+		key = func(file *GoFile) string {
+			if len(file.Owners) > 0 {
+				return strings.Join(file.Owners, " ")
+			}
+			return unownedGroup
+		}
+	case config.GroupByComponent:
+		key = func(file *GoFile) string {
+			if dir := gp.Dirs[path.Dir(file.RelPkgPath)]; dir != nil && dir.Component != "" {
+				return dir.Component
+			}
+			return unassignedGroup
+		}
+	default:
+		return nil, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+
+	groups := make(map[string]*GoListItem)
+	for _, file := range initialDir.AllFiles() {
+		if file.Unscored {
+			continue
+		}
+		name := key(file)
+		group, ok := groups[name]
+		if !ok {
+			group = &GoListItem{RelPkgPath: name, Title: name}
+			groups[name] = group
+		}
+		group.StmtCount += file.StmtCount
+		group.StmtCoveredCount += file.StmtCoveredCount
+		group.LineCount += file.LineCount
+	}
+
+	result := make([]*GoListItem, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Title < result[j].Title
+	})
+	return result, nil
+}
+
+// ReportGroups writes the groups of the GoProject as an aligned table to the provided io.Writer, see Groups.
+func (gp *GoProject) ReportGroups(wr io.Writer, groupBy string) error {
+	groups, err := gp.Groups(groupBy)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(wr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tCOVERAGE\tSTATEMENTS")
+	for _, group := range groups {
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\n", group.Title, FormatPercent(group.Percent(), group.StmtCount, gp.Precision), group.StmtCoveredCount, group.StmtCount)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroups(t *testing.T) {
+	newProject := func() *GoProject {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.SafeDir("app/cmd/tool").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/cmd/tool/main.go", StmtCount: 10, StmtCoveredCount: 2}})
+		gp.SafeDir("app/internal/a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/internal/a/x.go", StmtCount: 10, StmtCoveredCount: 9}})
+		gp.SafeDir("app/internal/b").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/internal/b/y.s", StmtCount: 10, StmtCoveredCount: 5}})
+		gp.SafeDir("app").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/doc.go"}})
+		gp.SafeDir("app").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/Makefile", StmtCount: 2, StmtCoveredCount: 1}})
+		gp.Root().Aggregate()
+		return gp
+	}
+	summarize := func(groups []*GoListItem) []string {
+		var result []string
+		for _, group := range groups {
+			result = append(result, group.Title+" "+FormatPercent(group.Percent(), group.StmtCount, 1))
+		}
+		return result
+	}
+
+	t.Run("should group by the first segment below the initial directory", func(t *testing.T) {
+		groups, err := newProject().Groups(config.GroupByTopDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{". 50.0%", "cmd 20.0%", "internal 70.0%"}, summarize(groups))
+	})
+
+	t.Run("should group by extension", func(t *testing.T) {
+		groups, err := newProject().Groups(config.GroupByExt)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"(none) 50.0%", ".go 55.0%", ".s 50.0%"}, summarize(groups))
+	})
+
+	t.Run("should group by owners", func(t *testing.T) {
+		gp := newProject()
+		for _, file := range gp.Root().AllFiles() {
+			switch {
+			case strings.HasPrefix(file.RelPkgPath, "app/internal/"):
+				file.Owners = []string{"@org/core"}
+			case strings.HasPrefix(file.RelPkgPath, "app/cmd/"):
+				file.Owners = []string{"@org/cli", "@alice"}
+			}
+		}
+		groups, err := gp.Groups(config.GroupByOwner)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"(unowned) 50.0%", "@org/cli @alice 20.0%", "@org/core 70.0%"}, summarize(groups))
+	})
+
+	t.Run("should group by the component tags of packages", func(t *testing.T) {
+		gp := newProject()
+		gp.Dirs["app/internal/a"].Component = "billing"
+		gp.Dirs["app/internal/b"].Component = "billing"
+		gp.Dirs["app/cmd/tool"].Component = "cli"
+		groups, err := gp.Groups(config.GroupByComponent)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"(unassigned) 50.0%", "billing 70.0%", "cli 20.0%"}, summarize(groups))
+	})
+
+	t.Run("should leave out the unscored files", func(t *testing.T) {
+		gp := newProject()
+		gp.SafeDir("app/gen").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/gen/z.go", StmtCount: 10, Unscored: true}})
+		gp.Root().Aggregate()
+		groups, err := gp.Groups(config.GroupByTopDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{". 50.0%", "cmd 20.0%", "internal 70.0%"}, summarize(groups))
+	})
+
+	t.Run("should return error with unknown grouping", func(t *testing.T) {
+		_, err := newProject().Groups("depth")
+		assert.EqualError(t, err, `unknown grouping "depth"`)
+	})
+
+	t.Run("should write an aligned table", func(t *testing.T) {
+		var buf strings.Builder
+		assert.NoError(t, newProject().ReportGroups(&buf, config.GroupByTopDir))
+		assert.Equal(t, strings.Join([]string{
+			"GROUP     COVERAGE  STATEMENTS",
+			".         50.0%     1/2",
+			"cmd       20.0%     2/10",
+			"internal  70.0%     14/20",
+			"",
+		}, "\n"), buf.String())
+	})
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// Highlighter writes HTML-escaped Go source wrapped in token spans, one line at a time.
+// It keeps track of raw strings and block comments spanning several lines.
+// A nil Highlighter writes plain escaped code.
+type Highlighter struct {
+	// open is the class of the multi-line token left open by the previous line, if any.
+	open string
+}
+
+const (
+	classKeyword = "tok-keyword"
+	classString  = "tok-string"
+	classComment = "tok-comment"
+)
+
+// NewHighlighter returns a Highlighter positioned at the start of a Go source file.
+func NewHighlighter() *Highlighter {
+	return &Highlighter{}
+}
+
+// WriteCode writes the given line of source to dst, highlighting keywords, strings and comments.
+func (h *Highlighter) WriteCode(dst *bufio.Writer, line string) error {
+	if h == nil {
+		return WriteHTMLEscapedCode(dst, line)
+	}
+
+	if h.open != "" {
+		end := h.closingIndex(line)
+		if end < 0 {
+			return writeSpan(dst, h.open, line)
+		}
+		if err := writeSpan(dst, h.open, line[:end]); err != nil {
+			return err
+		}
+		h.open = ""
+		line = line[end:]
+	}
+
+	src := []byte(line)
+	file := token.NewFileSet().AddFile("", -1, len(src))
+	var s scanner.Scanner
+	s.Init(file, src, func(token.Position, string) {}, scanner.ScanComments)
+
+	cursor := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// Automatically inserted semicolon, not present in the source.
+			continue
+		}
+
+		start := file.Offset(pos)
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		if err := WriteHTMLEscapedCode(dst, line[cursor:start]); err != nil {
+			return err
+		}
+		cursor = start + len(text)
+
+		var class string
+		switch {
+		case tok.IsKeyword():
+			class = classKeyword
+		case tok == token.STRING || tok == token.CHAR:
+			class = classString
+			if strings.HasPrefix(text, "`") && (len(text) == 1 || !strings.HasSuffix(text, "`")) {
+				h.open = classString
+			}
+		case tok == token.COMMENT:
+			class = classComment
+			if strings.HasPrefix(text, "/*") && (len(text) < 4 || !strings.HasSuffix(text, "*/")) {
+				h.open = classComment
+			}
+		}
+		if err := writeSpan(dst, class, text); err != nil {
+			return err
+		}
+	}
+	return WriteHTMLEscapedCode(dst, line[cursor:])
+}
+
+// closingIndex returns the index just past the delimiter closing the open token, or -1.
+func (h *Highlighter) closingIndex(line string) int {
+	delim := "`"
+	if h.open == classComment {
+		delim = "*/"
+	}
+	idx := strings.Index(line, delim)
+	if idx < 0 {
+		return -1
+	}
+	return idx + len(delim)
+}
+
+// writeSpan writes the HTML-escaped text wrapped in a span with the given class.
+// Text without a class is written as is. Tabs of string and rune literals are kept, see writeHTMLEscapedLiteral.
+func writeSpan(dst *bufio.Writer, class, text string) error {
+	if class == "" || text == "" {
+		return WriteHTMLEscapedCode(dst, text)
+	}
+	if _, err := fmt.Fprintf(dst, "<span class=\"%s\">", class); err != nil {
+		return err
+	}
+	write := WriteHTMLEscapedCode
+	if class == classString {
+		write = writeHTMLEscapedLiteral
+	}
+	if err := write(dst, text); err != nil {
+		return err
+	}
+	_, err := dst.WriteString("</span>")
+	return err
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlighter(t *testing.T) {
+	highlight := func(hl *Highlighter, lines ...string) []string {
+		var result []string
+		for _, line := range lines {
+			var buf strings.Builder
+			dst := bufio.NewWriter(&buf)
+			err := hl.WriteCode(dst, line)
+			assert.NoError(t, err)
+			assert.NoError(t, dst.Flush())
+			result = append(result, buf.String())
+		}
+		return result
+	}
+
+	t.Run("should escape without highlighting when nil", func(t *testing.T) {
+		result := highlight(nil, `if a < b { return "x" }`)
+		assert.Equal(t, []string{`if a &lt; b { return "x" }`}, result)
+	})
+
+	t.Run("should wrap keywords, strings and comments", func(t *testing.T) {
+		result := highlight(NewHighlighter(), `if a < b { return "<x>" } // done`)
+		assert.Equal(t, []string{
+			`<span class="tok-keyword">if</span> a &lt; b { <span class="tok-keyword">return</span> <span class="tok-string">"&lt;x&gt;"</span> } <span class="tok-comment">// done</span>`,
+		}, result)
+	})
+
+	t.Run("should carry raw strings across lines", func(t *testing.T) {
+		result := highlight(NewHighlighter(), "s := `a", "if b", "c` + d")
+		assert.Equal(t, []string{
+			"s := <span class=\"tok-string\">`a</span>",
+			`<span class="tok-string">if b</span>`,
+			"<span class=\"tok-string\">c`</span> + d",
+		}, result)
+	})
+
+	t.Run("should carry block comments across lines", func(t *testing.T) {
+		result := highlight(NewHighlighter(), "x /* a", "func", "b */ go")
+		assert.Equal(t, []string{
+			`x <span class="tok-comment">/* a</span>`,
+			`<span class="tok-comment">func</span>`,
+			`<span class="tok-comment">b */</span> <span class="tok-keyword">go</span>`,
+		}, result)
+	})
+	t.Run("should keep tabs of string literals and expand indentation tabs", func(t *testing.T) {
+		result := highlight(NewHighlighter(), "\ts := \"a\tb\"\t+ '\t'", "\tr := `x", "\ty`")
+		assert.Equal(t, []string{
+			`    s := <span class="tok-string">"a<span class="tab">	</span>b"</span>    + <span class="tok-string">'<span class="tab">	</span>'</span>`,
+			"    r := <span class=\"tok-string\">`x</span>",
+			"<span class=\"tok-string\"><span class=\"tab\">	</span>y`</span>",
+		}, result)
+	})
+}
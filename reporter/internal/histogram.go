@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+// histogramBuckets is the number of coverage buckets of the histogram, 10% wide each.
+const histogramBuckets = 10
+
+// FileHistogram counts the files with statements by coverage bucket: 0-10%, 10-20%, ..., 90-100%.
+// Each bucket includes its lower bound, the last one including 100% as well.
+func FileHistogram(files []*GoFile) []int {
+	counts := make([]int, histogramBuckets)
+	for _, file := range files {
+		if file.StmtCount == 0 {
+			continue
+		}
+		bucket := int(math.Floor(file.Percent() / 100 * histogramBuckets))
+		counts[min(bucket, histogramBuckets-1)]++
+	}
+	return counts
+}
+
+// TemplateHistogramBar represents a bar of the histogram of file coverage.
+type TemplateHistogramBar struct {
+	Label     string
+	Count     int
+	ClassName string
+	// Height is the height of the bar, in percent of the highest bar.
+	Height string
+}
+
+// newHistogram returns the bars of the histogram of file coverage of the given files,
+// colored after the cutlines band of their lower bound.
+func (td *TemplateData) newHistogram(files []*GoFile) []*TemplateHistogramBar {
+	counts := FileHistogram(files)
+	highest := 0
+	for _, count := range counts {
+		highest = max(highest, count)
+	}
+	if highest == 0 {
+		return nil
+	}
+
+	bars := make([]*TemplateHistogramBar, len(counts))
+	width := 100 / histogramBuckets
+	for i, count := range counts {
+		bars[i] = &TemplateHistogramBar{
+			Label:     fmt.Sprintf("%d-%d%%", i*width, (i+1)*width),
+			Count:     count,
+			ClassName: td.Cutlines.Classify(float64(i * width)),
+			Height:    fmt.Sprintf("%.1f", float64(count)/float64(highest)*100),
+		}
+	}
+	return bars
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileHistogram(t *testing.T) {
+	newFile := func(covered, total int) *GoFile {
+		return &GoFile{GoListItem: &GoListItem{StmtCoveredCount: covered, StmtCount: total}}
+	}
+	files := []*GoFile{
+		newFile(0, 10), newFile(0, 3), newFile(1, 10), newFile(5, 10),
+		newFile(9, 10), newFile(10, 10), newFile(0, 0),
+	}
+	assert.Equal(t, []int{2, 1, 0, 0, 0, 1, 0, 0, 0, 2}, FileHistogram(files))
+}
+
+func TestReportHistogram(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+	gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+	addFile := func(relPkgPath string, covered, total int) {
+		file := &GoFile{GoListItem: NewGoListItem(relPkgPath), ABSPath: absPath}
+		file.StmtCoveredCount, file.StmtCount = covered, total
+		gp.SafeDir(filepath.Dir(relPkgPath)).AddFile(file)
+	}
+	addFile("a/x.go", 1, 2)
+	addFile("a/b/y.go", 1, 2)
+	addFile("a/b/z.go", 2, 2)
+
+	td := &TemplateData{InitialID: gp.SafeDir("a").ID, Cutlines: gp.Cutlines}
+	td.AddDir(gp.SafeDir("a"), nil)
+
+	histogram := td.Views[0].Histogram
+	assert.Len(t, histogram, 10)
+	assert.Equal(t, "50-60%", histogram[5].Label)
+	assert.Equal(t, 2, histogram[5].Count)
+	assert.Equal(t, "100.0", histogram[5].Height)
+	assert.Equal(t, "warning", histogram[5].ClassName)
+	assert.Equal(t, "50.0", histogram[9].Height)
+	assert.Equal(t, "safe", histogram[9].ClassName)
+	assert.Equal(t, "danger", histogram[0].ClassName)
+	for _, view := range td.Views[1:] {
+		assert.Nil(t, view.Histogram, "only the initial view has a histogram")
+	}
+
+	var buf strings.Builder
+	assert.NoError(t, gp.Report(&buf))
+	assert.Equal(t, 1, strings.Count(buf.String(), `<div class="histogram"`))
+	assert.Contains(t, buf.String(), `title="50-60%: 2 files"`)
+}
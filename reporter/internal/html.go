@@ -2,165 +2,713 @@ package internal
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"go/doc"
 	"io"
-	"os"
+	"math"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/drappier-charles/covreport/reporter/config"
+	"golang.org/x/tools/cover"
 )
 
 // Report generates an HTML report of the GoProject and writes it to the provided io.Writer.
 // The report includes a directory tree of the project's files and directories, along with coverage information.
 func (gp *GoProject) Report(wr io.Writer) error {
-	tmpl := template.Must(template.New("html").Parse(templateHTML))
+	data, err := gp.newTemplateData()
+	if err != nil {
+		return err
+	}
+
+	switch gp.Layout {
+	case "", config.LayoutDrilldown:
+	case config.LayoutTree:
+		data.AddTree(gp.InitialDir())
+	case config.LayoutFlat:
+		data.AddFlat(gp.InitialDir())
+	default:
+		return fmt.Errorf("unknown layout %q", gp.Layout)
+	}
+
+	gp.Progress.Start("rendering files", len(data.fileViews()))
+	defer gp.Progress.Finish()
+	return gp.executeTemplate(wr, data)
+}
+
+// newTemplateData returns the template data of the views of the directories and files of the report.
+func (gp *GoProject) newTemplateData() (*TemplateData, error) {
+	initialDir := gp.InitialDir()
+
+	switch gp.Sort {
+	case "", config.SortWorst, config.SortBest, config.SortName, config.SortUncovered:
+	default:
+		return nil, fmt.Errorf("unknown sort %q", gp.Sort)
+	}
+
+	switch gp.PathStyle {
+	case "", config.PathStyleBase, config.PathStyleFull:
+	default:
+		return nil, fmt.Errorf("unknown path style %q", gp.PathStyle)
+	}
+
+	root := gp.Root()
+	data := &TemplateData{
+		Title:     gp.Title,
+		InitialID: initialDir.ID,
+		PathStyle: gp.PathStyle,
+		Cutlines:  gp.Cutlines,
+		Sort:      gp.Sort,
+		Collapse:  gp.Collapse,
+		Precision: gp.Precision,
+		Generated: time.Now().Format(time.RFC3339),
+		Version:   Version(),
+		Removed:   gp.Removed,
+		Warnings:  gp.Warnings,
 
-	initialDir := gp.Root()
-	if gp.RootPath == "." {
-		for len(initialDir.SubDirs) == 1 && len(initialDir.Files) == 0 {
-			initialDir = initialDir.SubDirs[0]
+		Colors:          gp.Colors,
+		LineBackgrounds: gp.LineBackgrounds,
+		Minify:          gp.Minify,
+		LiveReload:      gp.LiveReload,
+		StrictLines:     gp.StrictLines,
+		LinkUncovered:   gp.LinkUncovered,
+		progress:        gp.Progress,
+		sources:         gp.Sources,
+		Summary: &TemplateSummaryData{
+			Percent:        FormatPercent(root.Percent(), root.StmtCount, gp.Precision),
+			NumStmtCovered: root.StmtCoveredCount,
+			NumStmt:        root.StmtCount,
+			Hidden:         gp.Hidden,
+		},
+	}
+	data.Summary.Delta, data.Summary.DeltaClass = FormatDelta(root.GoListItem, gp.Precision)
+	data.Summary.Branches = NewTemplateBranchData(root.GoListItem, gp.Precision)
+	if gp.Changed != nil {
+		data.Summary.Diff = &TemplateSummaryData{
+			Percent:        FormatPercent(root.DiffPercent(), root.DiffStmtCount, gp.Precision),
+			NumStmtCovered: root.DiffStmtCoveredCount,
+			NumStmt:        root.DiffStmtCount,
+		}
+	}
+	if gp.SelfContained {
+		profile, err := json.Marshal(NewJSONProfiles(gp.Profiles))
+		if err != nil {
+			return nil, err
+		}
+		data.Profile = string(profile)
+	}
+	if worst := initialDir.WorstFile(); worst != nil {
+		data.Worst = &TemplateLinkData{ID: worst.ID, Title: worst.RelPkgPath}
+	}
+	data.AddDir(initialDir, nil)
+	if gp.Sidebar {
+		for _, view := range data.Views {
+			if view.IsDir {
+				data.Sidebar = append(data.Sidebar, view)
+			}
 		}
 	}
+	return data, nil
+}
 
-	data := &TemplateData{InitialID: initialDir.ID, Cutlines: gp.Cutlines}
-	if err := data.AddDir(initialDir, nil); err != nil {
+// executeTemplate renders the template data as HTML to the provided io.Writer, minified when set.
+// The lines of the file views are written as the template reaches them, see lineRenderer,
+// so the report is streamed rather than built in memory.
+func (gp *GoProject) executeTemplate(wr io.Writer, data *TemplateData) error {
+	out := bufio.NewWriter(wr)
+	var dst io.Writer = out
+	if gp.Minify {
+		dst = &minifyWriter{w: out, lineStart: true}
+	}
+	lines := data.renderLinesAhead()
+	defer lines.stop()
+	tmpl := template.Must(template.New("html").Funcs(template.FuncMap{
+		"lines": func(view *TemplateViewData) (string, error) {
+			return "", lines.write(dst, view)
+		},
+	}).Parse(templateHTML))
+	if err := tmpl.Execute(dst, data); err != nil {
+		if lines.err != nil {
+			return lines.err
+		}
 		return err
 	}
+	return out.Flush()
+}
 
-	return tmpl.Execute(wr, data)
+// minifyWriter strips the indentation and the blank lines of the HTML written to it.
+// Line breaks are kept, as they may separate inline elements or JavaScript statements.
+// The report has no multi-line preformatted text, code being rendered one line per element, so this doesn't change its appearance.
+type minifyWriter struct {
+	w io.Writer
+	// lineStart tells that the indentation of the line is being written, and newline that a line break
+	// is pending, written before the next non-blank line.
+	lineStart bool
+	newline   bool
+	written   bool
+	buf       []byte
 }
 
-// AddDir adds a directory to the template data.
-func (td *TemplateData) AddDir(dir *GoDir, links []*TemplateLinkData) error {
-	var title string
-	if td.InitialID == dir.ID {
-		if dir.RelPkgPath == "." {
-			title = "root"
-		} else {
-			title = dir.RelPkgPath
+// Write writes the minified HTML to the underlying writer.
+func (m *minifyWriter) Write(p []byte) (int, error) {
+	m.buf = m.buf[:0]
+	for _, b := range p {
+		switch {
+		case b == '\n':
+			m.newline = m.newline || !m.lineStart
+			m.lineStart = true
+		case m.lineStart && (b == ' ' || b == '\t'):
+		default:
+			if m.newline && m.written {
+				m.buf = append(m.buf, '\n')
+			}
+			m.buf = append(m.buf, b)
+			m.lineStart, m.newline, m.written = false, false, true
 		}
-	} else {
-		title = dir.Title
 	}
+	if _, err := m.w.Write(m.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
 
+// AddDir adds a directory and everything under it to the template data.
+// The lines of the file views are rendered when the template is executed.
+func (td *TemplateData) AddDir(dir *GoDir, links []*TemplateLinkData) {
+	title := td.dirTitle(dir)
 	view := &TemplateViewData{
-		ID:             dir.ID,
-		Links:          append(links, &TemplateLinkData{ID: dir.ID, Title: title}),
-		NumStmtCovered: dir.StmtCoveredCount,
-		NumStmt:        dir.StmtCount,
-		IsDir:          true,
-		Percent:        fmt.Sprintf("%.1f%%", dir.Percent()),
+		ID:               dir.ID,
+		Links:            append(links, &TemplateLinkData{ID: dir.ID, Title: title}),
+		NumStmtCovered:   dir.StmtCoveredCount,
+		NumStmt:          dir.StmtCount,
+		NumStmtUncovered: dir.StmtCount - dir.StmtCoveredCount,
+		NumLines:         dir.LineCount,
+		IsDir:            true,
+		Percent:          FormatPercent(dir.Percent(), dir.StmtCount, td.Precision),
+		ClassName:        td.newListItem(dir.GoListItem).ClassName,
+	}
+	view.Delta, view.DeltaClass = FormatDelta(dir.GoListItem, td.Precision)
+	view.Branches = NewTemplateBranchData(dir.GoListItem, td.Precision)
+	view.Owners = strings.Join(dir.Owners, " ")
+	if dir.Doc != "" {
+		view.Doc, view.Synopsis = dir.Doc, new(doc.Package).Synopsis(dir.Doc)
+	}
+	view.Tests = dir.Tests
+	if dir.ID == td.InitialID {
+		view.Histogram = td.newHistogram(dir.AllFiles())
+		view.Worst = td.Worst
+		view.Legend = &TemplateLegendData{Bands: td.newLegendBands()}
 	}
 	td.Views = append(td.Views, view)
 
 	view.Items = make([]*TemplateListItemData, 0, len(dir.SubDirs)+len(dir.Files))
 	for _, subDir := range dir.SubDirs {
-		if err := td.AddDir(subDir, view.Links); err != nil {
-			return err
-		}
-		view.Items = append(view.Items, NewTemplateListItemData(subDir.GoListItem, td.Cutlines))
+		td.AddDir(subDir, view.Links)
+		view.Items = append(view.Items, td.newPathItem(subDir.GoListItem))
 	}
+	fileViews := make(map[string]*TemplateViewData, len(dir.Files))
 	for _, file := range dir.Files {
-		if err := td.AddFile(file, view.Links); err != nil {
-			return err
+		td.AddFile(file, view.Links)
+		fileViews[file.ID] = td.Views[len(td.Views)-1]
+		item := td.newPathItem(file.GoListItem)
+		if td.LinkUncovered {
+			item.FirstUncoveredLine = file.FirstUncoveredLine()
+		}
+		view.Items = append(view.Items, item)
+	}
+	SortListItems(view.Items, td.Sort)
+	for i, item := range view.Items {
+		fileView, ok := fileViews[item.ID]
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			fileView.Prev = view.Items[i-1].ID
+		}
+		if i < len(view.Items)-1 {
+			fileView.Next = view.Items[i+1].ID
 		}
-		view.Items = append(view.Items, NewTemplateListItemData(file.GoListItem, td.Cutlines))
 	}
-	return nil
 }
 
-// AddFile adds a Go file to the template data with the given links and returns an error if any.
-// The method also generates the HTML-escaped lines of code for the file and adds them to the view data.
-func (td *TemplateData) AddFile(file *GoFile, links []*TemplateLinkData) error {
-	src, err := os.ReadFile(file.ABSPath)
-	if err != nil {
-		return fmt.Errorf("can't read %q: %v", file.RelPkgPath, err)
+// fileViews returns the views of the template data showing the lines of a file.
+func (td *TemplateData) fileViews() []*TemplateViewData {
+	var views []*TemplateViewData
+	for _, view := range td.Views {
+		if view.file != nil {
+			views = append(views, view)
+		}
+	}
+	return views
+}
+
+// lineRenderer renders the lines of the file views concurrently, ahead of the template writing them in view order.
+// At most GOMAXPROCS files are rendered and not yet written at once, so the memory is bounded by a few files
+// rather than growing with the report.
+type lineRenderer struct {
+	rendered map[*TemplateViewData]chan *renderedLines
+	// window holds a token per file rendered and not yet written.
+	window chan struct{}
+	done   chan struct{}
+	// err is the error of the first file view which couldn't be rendered.
+	err error
+}
+
+// renderedLines are the rendered lines of a file view, or the error rendering them.
+type renderedLines struct {
+	buf bytes.Buffer
+	err error
+}
+
+// renderLinesAhead starts rendering the lines of the file views, in view order.
+// The lineRenderer must be stopped once the template is executed.
+func (td *TemplateData) renderLinesAhead() *lineRenderer {
+	views := td.fileViews()
+	r := &lineRenderer{
+		rendered: make(map[*TemplateViewData]chan *renderedLines, len(views)),
+		window:   make(chan struct{}, runtime.GOMAXPROCS(0)),
+		done:     make(chan struct{}),
+	}
+	for _, view := range views {
+		r.rendered[view] = make(chan *renderedLines, 1)
+	}
+	go func() {
+		for _, view := range views {
+			select {
+			case r.window <- struct{}{}:
+			case <-r.done:
+				return
+			}
+			go func(view *TemplateViewData) {
+				lines := &renderedLines{}
+				lines.err = td.RenderLines(&lines.buf, view.file)
+				td.progress.Step()
+				r.rendered[view] <- lines
+			}(view)
+		}
+	}()
+	return r
+}
+
+// write writes the lines of the file view to the writer once rendered, making room for the next file.
+func (r *lineRenderer) write(wr io.Writer, view *TemplateViewData) error {
+	rendered, ok := r.rendered[view]
+	if !ok {
+		return nil
+	}
+	lines := <-rendered
+	<-r.window
+	if lines.err != nil {
+		r.err = lines.err
+		return lines.err
+	}
+	_, err := lines.buf.WriteTo(wr)
+	return err
+}
+
+// stop stops rendering the file views the template didn't reach, when it failed.
+func (r *lineRenderer) stop() {
+	close(r.done)
+}
+
+// SortListItems orders the items of a directory view, directories and files together.
+// Items without statements come last when sorting by coverage. An empty order keeps the items as they are.
+func SortListItems(items []*TemplateListItemData, order string) {
+	byName := func(a, b *TemplateListItemData) bool {
+		return a.Title < b.Title
+	}
+
+	var less func(a, b *TemplateListItemData) bool
+	switch order {
+	case config.SortName:
+		less = byName
+	case config.SortUncovered:
+		less = func(a, b *TemplateListItemData) bool {
+			if a.NumStmtUncovered == b.NumStmtUncovered {
+				return byName(a, b)
+			}
+			return a.NumStmtUncovered > b.NumStmtUncovered
+		}
+	case config.SortWorst, config.SortBest:
+		less = func(a, b *TemplateListItemData) bool {
+			if (a.item.StmtCount == 0) != (b.item.StmtCount == 0) {
+				return b.item.StmtCount == 0
+			}
+			pa, pb := a.item.Percent(), b.item.Percent()
+			if pa == pb {
+				return byName(a, b)
+			}
+			if order == config.SortBest {
+				return pa > pb
+			}
+			return pa < pb
+		}
+	default:
+		return
 	}
 
+	sort.SliceStable(items, func(i, j int) bool {
+		return less(items[i], items[j])
+	})
+}
+
+// dirTitle returns the title of the directory, showing the full path for the initial one.
+func (td *TemplateData) dirTitle(dir *GoDir) string {
+	if td.InitialID != dir.ID {
+		return td.pathTitle(dir.GoListItem)
+	}
+	if dir.RelPkgPath == "." {
+		return "root"
+	}
+	return dir.RelPkgPath
+}
+
+// AddTree adds a single view rendering the whole hierarchy under dir as a collapsible tree.
+// The tree view becomes the initial view of the report.
+func (td *TemplateData) AddTree(dir *GoDir) {
+	root := td.newTreeNode(dir)
+	root.Title = td.dirTitle(dir)
+
+	td.Views = append(td.Views, &TemplateViewData{
+		ID:               TreeViewID,
+		Links:            []*TemplateLinkData{{ID: TreeViewID, Title: "tree"}},
+		NumStmtCovered:   dir.StmtCoveredCount,
+		NumStmt:          dir.StmtCount,
+		NumStmtUncovered: dir.StmtCount - dir.StmtCoveredCount,
+		IsDir:            true,
+		Percent:          FormatPercent(dir.Percent(), dir.StmtCount, td.Precision),
+		Tree:             root,
+		Legend:           &TemplateLegendData{Bands: td.newLegendBands()},
+	})
+	td.InitialID = TreeViewID
+}
+
+// AddFlat adds a single view listing every file under dir with its full path below dir, sorted as directory items,
+// names breaking ties. The flat view becomes the initial view of the report.
+func (td *TemplateData) AddFlat(dir *GoDir) {
+	files := dir.AllFiles()
+	items := make([]*TemplateListItemData, 0, len(files))
+	for _, file := range files {
+		item := td.newListItem(file.GoListItem)
+		item.Title = strings.TrimPrefix(file.RelPkgPath, dir.RelPkgPath+"/")
+		if td.LinkUncovered {
+			item.FirstUncoveredLine = file.FirstUncoveredLine()
+		}
+		items = append(items, item)
+	}
+	SortListItems(items, config.SortName)
+	SortListItems(items, td.Sort)
+
+	td.Views = append(td.Views, &TemplateViewData{
+		ID:               FlatViewID,
+		Links:            []*TemplateLinkData{{ID: FlatViewID, Title: "files"}},
+		NumStmtCovered:   dir.StmtCoveredCount,
+		NumStmt:          dir.StmtCount,
+		NumStmtUncovered: dir.StmtCount - dir.StmtCoveredCount,
+		NumLines:         dir.LineCount,
+		IsDir:            true,
+		Percent:          FormatPercent(dir.Percent(), dir.StmtCount, td.Precision),
+		Items:            items,
+		Legend:           &TemplateLegendData{Bands: td.newLegendBands()},
+	})
+	td.InitialID = FlatViewID
+}
+
+// newTreeNode returns the tree node of the directory with its subdirectories and files as children.
+func (td *TemplateData) newTreeNode(dir *GoDir) *TemplateTreeNode {
+	node := &TemplateTreeNode{
+		TemplateListItemData: td.newPathItem(dir.GoListItem),
+		IsDir:                true,
+		Children:             make([]*TemplateTreeNode, 0, len(dir.SubDirs)+len(dir.Files)),
+	}
+	for _, subDir := range dir.SubDirs {
+		node.Children = append(node.Children, td.newTreeNode(subDir))
+	}
+	for _, file := range dir.Files {
+		node.Children = append(node.Children, &TemplateTreeNode{
+			TemplateListItemData: td.newPathItem(file.GoListItem),
+		})
+	}
+	return node
+}
+
+// AddFile adds the view of a file to the template data with the given links.
+// Its HTML-escaped lines of code are rendered when the template is executed.
+func (td *TemplateData) AddFile(file *GoFile, links []*TemplateLinkData) {
 	id := file.ID
-	title := file.Title
+	title := td.pathTitle(file.GoListItem)
 	view := &TemplateViewData{
-		ID:             id,
-		Links:          append(links, &TemplateLinkData{ID: id, Title: title}),
-		NumStmtCovered: file.StmtCoveredCount,
-		NumStmt:        file.StmtCount,
-		Percent:        fmt.Sprintf("%.1f%%", file.Percent()),
+		ID:               id,
+		Path:             file.RelPkgPath,
+		Note:             file.Note,
+		Unscored:         file.Unscored,
+		Links:            append(links, &TemplateLinkData{ID: id, Title: title}),
+		NumStmtCovered:   file.StmtCoveredCount,
+		NumStmt:          file.StmtCount,
+		NumStmtUncovered: file.StmtCount - file.StmtCoveredCount,
+		NumLines:         file.LineCount,
+		Percent:          FormatPercent(file.Percent(), file.StmtCount, td.Precision),
+		file:             file,
+	}
+	view.Delta, view.DeltaClass = FormatDelta(file.GoListItem, td.Precision)
+	view.Branches = NewTemplateBranchData(file.GoListItem, td.Precision)
+	view.OutOfRange = describeOutOfRange(file.OutOfRange)
+	if language := FileLanguage(file.RelPkgPath); language != LanguageGo {
+		view.Language = language
 	}
+	view.Owners = strings.Join(file.Owners, " ")
+	item := td.newListItem(file.GoListItem)
+	view.Progress, view.ClassName = item.Progress, item.ClassName
 	td.Views = append(td.Views, view)
+	for _, f := range file.Funcs {
+		view.Funcs = append(view.Funcs, td.newListItem(f.GoListItem))
+	}
+}
+
+// describeOutOfRange describes the blocks of the profile past the end of a file, with their positions,
+// returning an empty string without blocks.
+func describeOutOfRange(blocks []cover.ProfileBlock) string {
+	if len(blocks) == 0 {
+		return ""
+	}
+	positions := make([]string, len(blocks))
+	for i, block := range blocks {
+		positions[i] = fmt.Sprintf("%d.%d,%d.%d", block.StartLine, block.StartCol, block.EndLine, block.EndCol)
+	}
+	return fmt.Sprintf("%d profile blocks past the last line, the profile may be stale: %s", len(blocks), strings.Join(positions, " "))
+}
+
+// RenderLines writes the HTML-escaped lines of code of the file along with their coverage to the writer.
+// The source is streamed line by line rather than read at once, unless it is cached, and a newline ending it
+// is followed by an empty last line, as in editors.
+// It only reads the template data, so files can be rendered concurrently.
+func (td *TemplateData) RenderLines(wr io.Writer, file *GoFile) error {
+	src, err := td.sources.Open(file.ABSPath)
+	if err != nil {
+		return fmt.Errorf("can't read %q: %v", file.RelPkgPath, err)
+	}
+	defer src.Close()
+
 	numProfileBlock := len(file.Profile)
 	idxProfile := 0
 
-	var buf strings.Builder
-	dst := bufio.NewWriter(&buf)
-	for idx, line := range strings.Split(string(src), "\n") {
-		lineNumber := idx + 1
-		var count *int
+	var hl *Highlighter
+	if filepath.Ext(file.ABSPath) == ".go" {
+		hl = NewHighlighter()
+	}
+
+	var maxCount int
+	for _, block := range file.Profile {
+		maxCount = max(maxCount, block.Count)
+	}
 
-		if idxProfile < numProfileBlock {
-			profile := file.Profile[idxProfile]
-			if profile.EndLine < lineNumber {
+	var strictCounts map[int]int
+	if td.StrictLines {
+		strictCounts = StrictLineCounts(file.Profile)
+	}
+	lineBlocks := LineBlocks(file.Profile)
+
+	if td.Minify {
+		// Lines are grid items, the line breaks between them don't show.
+		wr = lineBreakStripper{wr}
+	}
+	rd := bufio.NewReader(src)
+	dst := bufio.NewWriter(wr)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := rd.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("can't read %q: %v", file.RelPkgPath, err)
+		}
+		last := err == io.EOF
+		line = strings.TrimSuffix(line, "\n")
+		ln := &HTMLLine{Number: lineNumber, Changed: file.Changed[lineNumber], MaxCount: maxCount, Blocks: lineBlocks[lineNumber]}
+
+		if strictCounts != nil {
+			if count, ok := strictCounts[lineNumber]; ok {
+				ln.Count = &count
+			}
+		} else {
+			// Skip the blocks ending before the line, several blocks possibly ending on the same line.
+			for idxProfile < numProfileBlock && file.Profile[idxProfile].EndLine < lineNumber {
 				idxProfile++
-				if idxProfile < numProfileBlock {
-					profile = file.Profile[idxProfile]
-				}
 			}
-			if profile.EndLine >= lineNumber && profile.StartLine <= lineNumber {
-				count = &file.Profile[idxProfile].Count
+			if idxProfile < numProfileBlock && file.Profile[idxProfile].StartLine <= lineNumber {
+				ln.Count = &file.Profile[idxProfile].Count
 			}
 		}
 
-		if err := WriteHTMLEscapedLine(dst, lineNumber, count, line); err != nil {
+		if err := WriteHTMLEscapedLine(dst, ln, line, hl); err != nil {
 			return err
 		}
+		if last {
+			break
+		}
 	}
-	if err := dst.Flush(); err != nil {
-		return err
+	return dst.Flush()
+}
+
+// lineBreakStripper is a writer dropping the line breaks written to it.
+type lineBreakStripper struct {
+	w io.Writer
+}
+
+// Write writes p without its line breaks to the underlying writer.
+func (s lineBreakStripper) Write(p []byte) (int, error) {
+	if _, err := s.w.Write(bytes.ReplaceAll(p, []byte("\n"), nil)); err != nil {
+		return 0, err
 	}
-	view.Lines = buf.String()
-	return nil
+	return len(p), nil
+}
+
+// pathTitle returns the title of a directory or a file in the configured path style.
+func (td *TemplateData) pathTitle(item *GoListItem) string {
+	if td.PathStyle == config.PathStyleFull {
+		return item.RelPkgPath
+	}
+	return item.Title
+}
+
+// newPathItem returns the list item data of a directory or a file, titled in the configured path style.
+func (td *TemplateData) newPathItem(item *GoListItem) *TemplateListItemData {
+	data := td.newListItem(item)
+	data.Title = td.pathTitle(item)
+	return data
+}
+
+// newListItem returns the list item data of the given GoListItem, using the settings of the template data.
+func (td *TemplateData) newListItem(item *GoListItem) *TemplateListItemData {
+	return NewTemplateListItemData(item, td.Cutlines, td.Precision)
 }
 
 // NewTemplateListItemData returns a new instance of TemplateListItemData based on the given GoListItem and Cutlines.
-func NewTemplateListItemData(item *GoListItem, cutlines *config.Cutlines) *TemplateListItemData {
+// The percentage is formatted with the given number of decimal places.
+func NewTemplateListItemData(item *GoListItem, cutlines *config.Cutlines, precision int) *TemplateListItemData {
 	var className string
 	percent := item.Percent()
 
 	if item.StmtCount > 0 {
-		if percent < cutlines.Warning {
-			className = "danger"
-		} else if percent < cutlines.Safe {
-			className = "warning"
-		} else {
-			className = "safe"
+		className = cutlines.Classify(percent)
+	}
+
+	result := &TemplateListItemData{
+		item:             item,
+		ClassName:        className,
+		ID:               item.ID,
+		Title:            item.Title,
+		Note:             item.Note,
+		Progress:         fmt.Sprintf("%.1f", percent),
+		Percent:          FormatPercent(percent, item.StmtCount, precision),
+		NumStmtCovered:   item.StmtCoveredCount,
+		NumStmt:          item.StmtCount,
+		NumStmtUncovered: item.StmtCount - item.StmtCoveredCount,
+		NumLines:         item.LineCount,
+	}
+	result.Delta, result.DeltaClass = FormatDelta(item, precision)
+	result.New = item.New
+	result.Unscored = item.Unscored
+	return result
+}
+
+// StrictLineCounts returns the counts of the lines overlapped by the profile blocks, for strict line coverage.
+// The count of a line is the lowest count of the blocks overlapping it, so a line is only covered
+// when every block overlapping it ran, even partially, such as the "} else {" line ending a block
+// and starting another.
+func StrictLineCounts(blocks []cover.ProfileBlock) map[int]int {
+	counts := make(map[int]int)
+	for _, block := range blocks {
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			if count, ok := counts[line]; !ok || block.Count < count {
+				counts[line] = block.Count
+			}
 		}
 	}
+	return counts
+}
 
-	return &TemplateListItemData{
-		ClassName:      className,
-		ID:             item.ID,
-		Title:          item.Title,
-		Progress:       fmt.Sprintf("%.1f", percent),
-		Percent:        fmt.Sprintf("%.1f%%", percent),
-		NumStmtCovered: item.StmtCoveredCount,
-		NumStmt:        item.StmtCount,
+// LineBlocks returns the profile blocks overlapping each line, in profile order.
+func LineBlocks(blocks []cover.ProfileBlock) map[int][]cover.ProfileBlock {
+	lines := make(map[int][]cover.ProfileBlock)
+	for _, block := range blocks {
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			lines[line] = append(lines[line], block)
+		}
 	}
+	return lines
+}
+
+// HTMLLine holds the coverage information of a single source line.
+type HTMLLine struct {
+	Number  int
+	Count   *int
+	Changed bool
+	// MaxCount is the highest count of the file, shading the covered count of the line by its magnitude
+	// so hot lines stand out. Lines reaching it, like every covered line in set mode, aren't shaded.
+	MaxCount int
+	// Blocks are the profile blocks overlapping the line, shown with their positions and counts
+	// in the tooltip of its covered count, to tell why it is colored so.
+	Blocks []cover.ProfileBlock
+}
+
+// blocksTitle returns the title attribute of the covered count of the line, listing its blocks, if any.
+func (ln *HTMLLine) blocksTitle() string {
+	if len(ln.Blocks) == 0 {
+		return ""
+	}
+	blocks := make([]string, len(ln.Blocks))
+	for i, block := range ln.Blocks {
+		blocks[i] = fmt.Sprintf("block %d.%d,%d.%d: %dx", block.StartLine, block.StartCol, block.EndLine, block.EndCol, block.Count)
+	}
+	return " title=\"" + strings.Join(blocks, "&#10;") + "\""
+}
+
+// countOpacity returns the opacity of the covered count of a line, from 0.4 for a single hit to 1 for the
+// highest count of the file, on a logarithmic scale as counts span orders of magnitude.
+func countOpacity(count, maxCount int) float64 {
+	if count >= maxCount {
+		return 1
+	}
+	return 0.4 + 0.6*math.Log(float64(count))/math.Log(float64(maxCount))
+}
+
+// FormatPercent formats a coverage percentage with the given number of decimal places,
+// or "N/A" when there are no statements to cover.
+func FormatPercent(percent float64, numStmt int, precision int) string {
+	if numStmt == 0 {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.*f%%", precision, percent)
 }
 
 // WriteHTMLEscapedLine writes an HTML-escaped line to the given bufio.Writer.
-func WriteHTMLEscapedLine(dst *bufio.Writer, lineNumber int, count *int, line string) error {
+// The code is syntax highlighted when a Highlighter is given.
+func WriteHTMLEscapedLine(dst *bufio.Writer, ln *HTMLLine, line string, hl *Highlighter) error {
+	var changed string
+	if ln.Changed {
+		changed = " changed"
+	}
+
+	title := ln.blocksTitle()
 	var err error
-	if count == nil {
-		_, err = fmt.Fprintf(dst, "<div class=\"line-number\">%d</div><div class=\"covered-count\"></div><pre class=\"line\">", lineNumber)
-	} else if *count == 0 {
-		_, err = fmt.Fprintf(dst, "<div class=\"line-number\">%d</div><div class=\"covered-count uncovered\"></div><pre class=\"line uncovered\">", lineNumber)
+	if ln.Count == nil {
+		_, err = fmt.Fprintf(dst, "<div class=\"line-number%s\">%d</div><div class=\"covered-count\"%s></div><pre class=\"line\">", changed, ln.Number, title)
+	} else if *ln.Count == 0 {
+		_, err = fmt.Fprintf(dst, "<div class=\"line-number%s\">%d</div><div class=\"covered-count uncovered\"%s></div><pre class=\"line uncovered%s\">", changed, ln.Number, title, changed)
+	} else if *ln.Count < ln.MaxCount {
+		_, err = fmt.Fprintf(dst, "<div class=\"line-number%s\">%d</div><div class=\"covered-count covered\"%s style=\"opacity: %.2f\">%dx</div><pre class=\"line covered\">", changed, ln.Number, title, countOpacity(*ln.Count, ln.MaxCount), *ln.Count)
 	} else {
-		_, err = fmt.Fprintf(dst, "<div class=\"line-number\">%d</div><div class=\"covered-count covered\">%dx</div><pre class=\"line covered\">", lineNumber, *count)
+		_, err = fmt.Fprintf(dst, "<div class=\"line-number%s\">%d</div><div class=\"covered-count covered\"%s>%dx</div><pre class=\"line covered\">", changed, ln.Number, title, *ln.Count)
 	}
 	if err != nil {
 		return err
 	}
-	if err := WriteHTMLEscapedCode(dst, line); err != nil {
+	if err := hl.WriteCode(dst, line); err != nil {
 		return err
 	}
 	_, err = fmt.Fprintf(dst, "</pre>\n")
@@ -168,10 +716,23 @@ func WriteHTMLEscapedLine(dst *bufio.Writer, lineNumber int, count *int, line st
 }
 
 // WriteHTMLEscapedCode writes the given line to the provided bufio.Writer, escaping HTML special characters.
+// Tabs, indenting the code or separating its tokens, are expanded to 4 spaces.
 func WriteHTMLEscapedCode(dst *bufio.Writer, line string) error {
+	return writeHTMLEscaped(dst, line, "    ")
+}
+
+// writeHTMLEscapedLiteral writes the given part of a string or rune literal to the provided bufio.Writer,
+// escaping HTML special characters. Tabs are part of the literal, so they are kept and marked rather than expanded.
+func writeHTMLEscapedLiteral(dst *bufio.Writer, text string) error {
+	return writeHTMLEscaped(dst, text, "<span class=\"tab\">\t</span>")
+}
+
+// writeHTMLEscaped writes the text to the provided bufio.Writer, escaping HTML special characters
+// and replacing tabs with the given HTML. Multi-byte characters are written as is.
+func writeHTMLEscaped(dst *bufio.Writer, text, tab string) error {
 	var err error
-	for i := range line {
-		switch b := line[i]; b {
+	for i := 0; i < len(text) && err == nil; i++ {
+		switch b := text[i]; b {
 		case '>':
 			_, err = dst.WriteString("&gt;")
 		case '<':
@@ -179,7 +740,7 @@ func WriteHTMLEscapedCode(dst *bufio.Writer, line string) error {
 		case '&':
 			_, err = dst.WriteString("&amp;")
 		case '\t':
-			_, err = dst.WriteString("    ")
+			_, err = dst.WriteString(tab)
 		default:
 			err = dst.WriteByte(b)
 		}
@@ -195,32 +756,230 @@ type TemplateLinkData struct {
 
 // TemplateListItemData represents the data structure for a single item in the HTML template list.
 type TemplateListItemData struct {
+	item *GoListItem
+
 	ClassName      string
 	ID             string
 	Title          string
+	Note           string
 	Progress       string
 	Percent        string
 	NumStmtCovered int
 	NumStmt        int
+	// NumStmtUncovered is NumStmt - NumStmtCovered.
+	NumStmtUncovered int
+	// NumLines is the number of lines of the source, including comments and blank lines.
+	NumLines int
+
+	// Delta is the change of coverage since the baseline of a comparison, and DeltaClass its direction.
+	Delta      string
+	DeltaClass string
+	// New tells that the item has no counterpart in the baseline of a comparison.
+	New bool
+	// Unscored tells that the file is left out of the totals of its directories.
+	Unscored bool
+
+	// FirstUncoveredLine is the line the item links to in its file view, 0 linking to the top of the view.
+	FirstUncoveredLine int
+}
+
+// TreeViewID is the ID of the view rendering the collapsible tree.
+const TreeViewID = "tree"
+
+// FlatViewID is the ID of the view listing every file of the flat layout.
+const FlatViewID = "files"
+
+// TemplateTreeNode represents a directory or a file in the collapsible tree view.
+type TemplateTreeNode struct {
+	*TemplateListItemData
+	IsDir    bool
+	Children []*TemplateTreeNode
 }
 
 // TemplateViewData represents the data needed to render a template view.
 type TemplateViewData struct {
 	ID             string
+	Note           string
 	Percent        string
 	NumStmtCovered int
 	NumStmt        int
-	Links          []*TemplateLinkData
-	Items          []*TemplateListItemData
-	Lines          string
-	IsDir          bool
+	// NumStmtUncovered is NumStmt - NumStmtCovered.
+	NumStmtUncovered int
+	// NumLines is the number of lines of the source, including comments and blank lines.
+	NumLines int
+	Links    []*TemplateLinkData
+	Items    []*TemplateListItemData
+	Funcs    []*TemplateListItemData
+	IsDir    bool
+	Tree     *TemplateTreeNode
+	// Delta is the change of coverage since the baseline of a comparison, and DeltaClass its direction.
+	Delta      string
+	DeltaClass string
+	// Path is the relative package path of the file of a file view, copied with the "copy path" button.
+	Path string
+	// Doc is the package comment of a directory view, and Synopsis its first sentence.
+	Doc      string
+	Synopsis string
+	// Histogram is the distribution of the coverage of the files under the initial directory view.
+	Histogram []*TemplateHistogramBar
+	// Legend explains the colors of the report on the initial directory view.
+	Legend *TemplateLegendData
+	// Worst links the initial directory view to the least covered file under it, if any.
+	Worst *TemplateLinkData
+	// Unscored tells that the file of a file view is left out of the totals of its directories.
+	Unscored bool
+	// OutOfRange describes the blocks of the profile past the end of the file of a file view, if any.
+	OutOfRange string
+	// Owners are the code owners of the directory or file of the view, space separated, if any.
+	Owners string
+	// Language is the language of the file of a file view when it isn't Go, such as the C files of cgo packages,
+	// empty for Go files and files of unknown languages.
+	Language string
+	// Progress is the value of the coverage bar of a file view.
+	Progress string
+	// ClassName is the cutlines class of the view.
+	ClassName string
+	// Branches is the approximate branch coverage of the view, nil without branches.
+	Branches *TemplateBranchData
+	// Tests are the results of the tests of the package of a directory view, nil without go test -json input.
+	Tests *TestResult
+	// Prev and Next are the IDs of the items around the file of a file view in the view of its directory, if any,
+	// linked from the file pages of split reports where the view of the directory isn't at hand.
+	Prev string
+	Next string
+
+	// file is the file of a file view, whose lines are rendered when the template reaches them, nil for directory views.
+	file *GoFile
+}
+
+// Ancestors returns the links of the breadcrumbs of the view to the views above it, all but the last one.
+func (view *TemplateViewData) Ancestors() []*TemplateLinkData {
+	if len(view.Links) == 0 {
+		return nil
+	}
+	return view.Links[:len(view.Links)-1]
+}
+
+// Title returns the title of the view, the last link of its breadcrumbs.
+func (view *TemplateViewData) Title() string {
+	if len(view.Links) == 0 {
+		return ""
+	}
+	return view.Links[len(view.Links)-1].Title
+}
+
+// TemplateSummaryData represents the overall project coverage shown in the report header.
+// Diff holds the coverage restricted to changed lines, if any.
+type TemplateSummaryData struct {
+	Percent        string
+	NumStmtCovered int
+	NumStmt        int
+	Diff           *TemplateSummaryData
+	// Delta is the change of coverage since the baseline of a comparison, and DeltaClass its direction.
+	Delta      string
+	DeltaClass string
+	// Hidden is the number of fully covered files left out of the report, still counted in the totals.
+	Hidden int
+	// Branches is the approximate branch coverage of the project, nil without branches.
+	Branches *TemplateBranchData
+}
+
+// TemplateBranchData represents the approximate branch coverage of a directory or a file, see countBranches.
+type TemplateBranchData struct {
+	Percent     string
+	NumCovered  int
+	NumBranches int
+}
+
+// NewTemplateBranchData returns the branch coverage of the item, with the given number of decimal places,
+// or nil when it has no branches.
+func NewTemplateBranchData(item *GoListItem, precision int) *TemplateBranchData {
+	if item.BranchCount == 0 {
+		return nil
+	}
+	return &TemplateBranchData{
+		Percent:     FormatPercent(item.BranchPercent(), item.BranchCount, precision),
+		NumCovered:  item.BranchCoveredCount,
+		NumBranches: item.BranchCount,
+	}
 }
 
 // TemplateData is a struct that holds data for generating HTML templates.
 type TemplateData struct {
+	// Title is the title of the report, naming the project, empty for the default one.
+	Title     string
 	Views     []*TemplateViewData
 	InitialID string
 	Cutlines  *config.Cutlines
+	// Colors overrides the colors of the coverage classes when set.
+	Colors    *config.Colors
+	Summary   *TemplateSummaryData
+	Sort      string
+	PathStyle string
+	Collapse  int
+	Precision int
+	Generated string
+	Version   string
+	// Removed lists the files of the baseline of a comparison missing from the report.
+	Removed []string
+	// Warnings are the problems of the input shown in the header, such as a stale profile.
+	Warnings []string
+	// Worst is the least covered file of the report, the one with the most uncovered statements among
+	// equally covered files, nil when every file is fully covered.
+	Worst *TemplateLinkData
+	// Profile is the JSON encoded input profile embedded in self-contained reports.
+	// json.Marshal escapes <, > and &, so it can't close the script element holding it.
+	Profile string
+	// LineBackgrounds overrides the backgrounds of the uncovered and covered lines when set.
+	LineBackgrounds *config.LineBackgrounds
+	// LiveReload reloads the report in the browser when it is regenerated, in watch mode.
+	LiveReload bool
+	// Minify renders the lines of code of file views without line breaks between them.
+	Minify bool
+	// StrictLines only shows lines as covered when every block overlapping them ran.
+	StrictLines bool
+	// LinkUncovered links the file items of directory views to the first uncovered line of the files.
+	LinkUncovered bool
+	// Sidebar lists the directory views in a navigation pane shown next to every view, nil hiding the pane.
+	// It is kept whole when the report is split into a page per view.
+	Sidebar []*TemplateViewData
+
+	// Pages maps the IDs of the views to their pages when the report is split into a page per view.
+	Pages map[string]string
+	// Page is the page being rendered, relative to the root of a split report.
+	Page string
+
+	// progress prints the progress of rendering the file views, nil printing nothing.
+	progress *Progress
+	// sources reads the source of the file views, nil reading it from the disk every time.
+	sources *SourceCache
+}
+
+// Href returns the link to the view of the given ID: a fragment in a single page report,
+// or the relative URL of its page in a split report.
+func (td *TemplateData) Href(id string) string {
+	page, ok := td.Pages[id]
+	if !ok {
+		return "#" + id
+	}
+	rel, err := filepath.Rel(filepath.Dir(td.Page), page)
+	if err != nil {
+		return "#" + id
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// LineHref returns the link to the given line of the view of the given ID, highlighting it.
+func (td *TemplateData) LineHref(id string, line int) string {
+	fragment := fmt.Sprintf("#%s-L%d", id, line)
+	if _, ok := td.Pages[id]; ok {
+		return td.Href(id) + fragment
+	}
+	return fragment
 }
 
 // templateHTML is the HTML template used to generate the coverage report.
@@ -230,12 +989,86 @@ const templateHTML = `
 <html>
 	<head>
 		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
-		<title>Go Coverage Report</title>
+		<meta name="generated" content="{{.Generated}}">
+		<meta name="generator" content="covreport {{html .Version}}">
+		<title>{{with .Title}}{{html .}}{{else}}Go Coverage Report{{end}}</title>
 		<style>
+			:root {
+				--tok-keyword: #c586c0;
+				--tok-string: #ce9178;
+				--tok-comment: #6a9955;
+				--danger-color: red;
+				--danger-bg: rgba(255, 0, 0, 0.4);
+				--warning-color: orange;
+				--warning-bg: rgba(255, 255, 0, 0.2);
+				--safe-color: green;
+				--safe-bg: rgba(0, 255, 0, 0.4);
+				--excellent-color: dodgerblue;
+				--excellent-bg: rgba(30, 144, 255, 0.4);
+				--covered-count-color: #00ff00;
+				--highlight-color: rgba(186, 104, 255, 0.35);
+				--uncovered-line-color: rgb(255, 0, 0);
+				--uncovered-line-opacity: 0.4;
+				--covered-line-color: rgb(0, 255, 0);
+				--covered-line-opacity: 0.4;
+			}
+			{{with .Colors}}
+			:root {
+				--danger-color: {{.Danger}};
+				--danger-bg: color-mix(in srgb, {{.Danger}} 40%, transparent);
+				--warning-color: {{.Warning}};
+				--warning-bg: color-mix(in srgb, {{.Warning}} 20%, transparent);
+				--safe-color: {{.Safe}};
+				--safe-bg: color-mix(in srgb, {{.Safe}} 40%, transparent);
+				--covered-count-color: {{.Safe}};
+				--uncovered-line-color: {{.Danger}};
+				--covered-line-color: {{.Safe}};
+				{{with .Excellent}}
+				--excellent-color: {{.}};
+				--excellent-bg: color-mix(in srgb, {{.}} 40%, transparent);
+				{{end}}
+			}
+			{{end}}
+			{{with .LineBackgrounds}}
+			:root {
+				{{with .Uncovered.Color}}--uncovered-line-color: {{.}};{{end}}
+				--uncovered-line-opacity: {{.Uncovered.Opacity}};
+				{{with .Covered.Color}}--covered-line-color: {{.}};{{end}}
+				--covered-line-opacity: {{.Covered.Opacity}};
+			}
+			{{end}}
 			body {
 				font-family: Menlo, monospace;
 				background-color: #1e1e1e;
 				color: #cfcfcf;
+				margin: 0;
+			}
+			.header {
+				position: sticky;
+				top: 0;
+				z-index: 1;
+				display: flex;
+				align-items: center;
+				gap: 1rem;
+				padding: 0.5rem 1rem;
+				background-color: #2a2a2a;
+				border-bottom: 1px solid #555;
+			}
+			.header .title {
+				margin: 0;
+				font-size: 1em;
+			}
+			.header .label {
+				opacity: 0.8;
+			}
+			.header .percent {
+				font-weight: bold;
+			}
+			.header .stmts {
+				border: 1px solid #555;
+				border-radius: 4px;
+				background-color: #3a3a3a;
+				padding: 2px 4px;
 			}
 			a {
 				text-decoration: none;
@@ -256,7 +1089,7 @@ const templateHTML = `
 				align-items: center;
 				flex-wrap: wrap;
 			}
-			.view .links a:not(:first-child):not(:last-child) {
+			.view .links a:not(:first-child) {
 				&::after {
 					content: "/";
 					color: #888;
@@ -275,8 +1108,19 @@ const templateHTML = `
 					color: #888;
 				}
 			}
-			.view .links span {
+			.view .links .copy-path {
+				margin-left: 1rem;
+				padding: 2px 4px;
+				font-family: inherit;
+				font-size: 1em;
+				cursor: pointer;
 				color: #cfcfcf;
+				background-color: #3a3a3a;
+				border: 1px solid #555;
+				border-radius: 4px;
+			}
+			.view .links .current {
+				color: #ffffff;
 				font-weight: bold;
 			}
 			.view .summary {
@@ -299,9 +1143,26 @@ const templateHTML = `
 				color: #cfcfcf;
 				padding: 2px 4px;
 			}
+			.view .summary .progress {
+				display: flex;
+			}
+			.view .summary .danger {
+				--accent-color: var(--danger-color);
+			}
+			.view .summary .warning {
+				--accent-color: var(--warning-color);
+			}
+			.view .summary .safe {
+				--accent-color: var(--safe-color);
+			}
+			.view .summary .excellent {
+				--accent-color: var(--excellent-color);
+			}
 			.lines {
+				--lines-background: #1e1e1e;
 				display: grid;
-				grid-template-columns: 3em 3em auto;
+				grid-template-columns: 3em 3em minmax(max-content, 1fr);
+				overflow-x: auto;
 				margin-bottom: 3rem;
 			}
 			.lines .wrapper {
@@ -312,14 +1173,21 @@ const templateHTML = `
 				display: flex;
 				justify-content: flex-end;
 				align-items: center;
-				margin-right: 4px;
+				border-right: 4px solid var(--lines-background);
+				background-clip: padding-box;
 				padding-right: 4px;
+				position: sticky;
+				z-index: 1;
 			}
 			.lines .line-number {
+				left: 0;
 				opacity: 0.6;
 				color: #cfcfcf;
+				background-color: var(--lines-background);
+				cursor: pointer;
 			}
 			.lines .covered-count {
+				left: 6em;
 				background-color: #3a3a3a;
 				color: #cfcfcf;
 			}
@@ -329,20 +1197,75 @@ const templateHTML = `
 				line-height: 1.5em;
 				height: 1.5em;
 				color: #cfcfcf;
+				tab-size: 4;
+			}
+			.lines .tok-keyword {
+				color: var(--tok-keyword);
+			}
+			.lines .tok-string {
+				color: var(--tok-string);
+			}
+			.lines .tok-comment {
+				color: var(--tok-comment);
+			}
+			.lines .tab {
+				text-decoration: underline dotted;
+			}
+			.lines .collapsed {
+				display: none;
+			}
+			.lines .expander {
+				grid-column: 1 / -1;
+				margin: 2px 0;
+				padding: 2px;
+				font-family: inherit;
+				font-size: 0.8em;
+				text-align: center;
+				cursor: pointer;
+				color: #cfcfcf;
+				background-color: #2a2a2a;
+				border: 1px dashed #555;
+			}
+			.lines .expander.uncovered {
+				border-color: #ff8080;
 			}
 			.lines .uncovered {
-				background-color: rgba(255, 0, 0, 0.4);
+				--line-tint: color-mix(in srgb, var(--uncovered-line-color) calc(var(--uncovered-line-opacity) * 100%), transparent);
+				background-color: var(--lines-background);
+				background-image: linear-gradient(var(--line-tint), var(--line-tint));
+			}
+			.lines .line-number.changed {
+				opacity: 1;
+				border-left: 3px solid #4d9fff;
+			}
+			.lines .line.uncovered.changed {
+				--line-tint: color-mix(in srgb, var(--danger-color) 60%, transparent);
+				outline: 1px dashed #ff8080;
 			}
 			.lines .covered-count.covered {
-				background-color: rgba(0, 255, 0, 0.4);
-				color: #00ff00;
+				--line-tint: color-mix(in srgb, var(--covered-line-color) calc(var(--covered-line-opacity) * 100%), transparent);
+				background-color: var(--lines-background);
+				background-image: linear-gradient(var(--line-tint), var(--line-tint));
+				color: var(--covered-count-color);
+			}
+			.lines .highlighted {
+				box-shadow: inset 0 0 0 100vmax var(--highlight-color);
+			}
+			.lines .line-number.highlighted {
+				opacity: 1;
 			}
 			.items {
 				margin: 0 1rem 3rem 1rem;
 				display: grid;
-				grid-template-columns: auto max-content max-content max-content;
+				grid-template-columns: auto max-content max-content max-content max-content max-content;
 				gap: 1px;
 			}
+			.items .wrapper.selected > * {
+				box-shadow: inset 0 2px 0 #4d9fff, inset 0 -2px 0 #4d9fff;
+			}
+			.items .uncovered-stmts, .items .num-lines {
+				text-align: right;
+			}
 			.items .wrapper > * {
 				padding: 8px 1rem;
 				&:not(:first-child) {
@@ -350,16 +1273,20 @@ const templateHTML = `
 				}
 			}
 			.items .wrapper.danger > * {
-				background-color: rgba(255, 0, 0, 0.4);
-				--accent-color: red;
+				background-color: var(--danger-bg);
+				--accent-color: var(--danger-color);
 			}
 			.items .wrapper.safe > * {
-				background-color: rgba(0, 255, 0, 0.4);
-				--accent-color: green;
+				background-color: var(--safe-bg);
+				--accent-color: var(--safe-color);
 			}
 			.items .wrapper.warning > * {
-				background-color: rgba(255, 255, 0, 0.2);
-				--accent-color: orange;
+				background-color: var(--warning-bg);
+				--accent-color: var(--warning-color);
+			}
+			.items .wrapper.excellent > * {
+				background-color: var(--excellent-bg);
+				--accent-color: var(--excellent-color);
 			}
 			progress {
 				border: 1px solid #888;
@@ -382,6 +1309,9 @@ const templateHTML = `
 					background-color: #333;
 				}
 			}
+			.items .wrapper.unscored > *, .tree .node.unscored {
+				opacity: 0.5;
+			}
 			.items .wrapper {
 				display: contents;
 				text-align: right;
@@ -391,55 +1321,671 @@ const templateHTML = `
 				text-align: left;
 				color: #cfcfcf;
 			}
+			.delta {
+				font-size: 0.8em;
+				&.up {
+					color: #00ff00;
+				}
+				&.down {
+					color: #ff8080;
+				}
+			}
+			.header .removed ul {
+				position: absolute;
+				max-height: 50vh;
+				overflow: auto;
+				margin: 0;
+				padding: 0.5rem 1.5rem;
+				background-color: #2a2a2a;
+				border: 1px solid #555;
+			}
+			.worst {
+				display: inline-block;
+				margin: 0 1rem 1rem 1rem;
+				padding: 2px 6px;
+				font-size: 0.8em;
+				border: 1px solid var(--danger-color);
+				border-radius: 4px;
+			}
+			.histogram {
+				display: flex;
+				gap: 4px;
+				height: 8rem;
+				max-width: 40rem;
+				margin: 0 1rem 2rem 1rem;
+				font-size: 0.6em;
+			}
+			.histogram .bar {
+				flex: 1;
+				display: flex;
+				flex-direction: column;
+				text-align: center;
+			}
+			.histogram .track {
+				flex: 1;
+				display: flex;
+				align-items: flex-end;
+			}
+			.histogram .fill {
+				width: 100%;
+				min-height: 1px;
+				background-color: var(--accent-color);
+			}
+			.histogram .label {
+				padding-top: 2px;
+				opacity: 0.8;
+			}
+			.histogram .danger {
+				--accent-color: var(--danger-color);
+			}
+			.histogram .warning {
+				--accent-color: var(--warning-color);
+			}
+			.histogram .safe {
+				--accent-color: var(--safe-color);
+			}
+			.histogram .excellent {
+				--accent-color: var(--excellent-color);
+			}
+			.legend {
+				display: flex;
+				flex-wrap: wrap;
+				gap: 4px 1rem;
+				margin: 0 1rem 2rem 1rem;
+				font-size: 0.6em;
+				opacity: 0.8;
+			}
+			.legend .entry {
+				display: flex;
+				align-items: center;
+				gap: 4px;
+			}
+			.legend .swatch {
+				width: 1em;
+				height: 1em;
+				border: 1px solid #555;
+				background-color: var(--accent-color, transparent);
+			}
+			.legend .swatch.uncovered {
+				background-color: color-mix(in srgb, var(--uncovered-line-color) calc(var(--uncovered-line-opacity) * 100%), transparent);
+			}
+			.legend .count.covered {
+				padding: 0 4px;
+				color: var(--covered-count-color);
+				background-color: color-mix(in srgb, var(--covered-line-color) calc(var(--covered-line-opacity) * 100%), transparent);
+			}
+			.legend .danger {
+				--accent-color: var(--danger-color);
+			}
+			.legend .warning {
+				--accent-color: var(--warning-color);
+			}
+			.legend .safe {
+				--accent-color: var(--safe-color);
+			}
+			.legend .excellent {
+				--accent-color: var(--excellent-color);
+			}
+			.doc {
+				margin: 0 1rem 2rem 1rem;
+				font-size: 0.8em;
+				color: #aaa;
+				white-space: pre-wrap;
+			}
+			.doc summary {
+				cursor: pointer;
+			}
+			.doc .doc-text {
+				padding-top: 0.5rem;
+			}
+			.note {
+				border: 1px dashed #888;
+				border-radius: 4px;
+				padding: 0 4px;
+				font-size: 0.8em;
+				color: #aaa;
+			}
+			.note.warning {
+				border-color: var(--warning-color);
+				color: var(--warning-color);
+			}
+			.footer {
+				padding: 1rem;
+				font-size: 0.8em;
+				opacity: 0.6;
+			}
+			.tree {
+				margin: 0 1rem 3rem 1rem;
+			}
+			.tree ul {
+				list-style: none;
+				margin: 0;
+				padding-left: 1.5rem;
+			}
+			.tree > ul {
+				padding-left: 0;
+			}
+			.tree summary {
+				cursor: pointer;
+			}
+			.tree .node {
+				display: inline-flex;
+				align-items: center;
+				gap: 1rem;
+				padding: 2px 4px;
+				color: #cfcfcf;
+			}
+			.tree .node .percent {
+				color: var(--accent-color, #cfcfcf);
+			}
+			.tree .danger {
+				--accent-color: var(--danger-color);
+			}
+			.tree .safe {
+				--accent-color: var(--safe-color);
+			}
+			.tree .warning {
+				--accent-color: var(--warning-color);
+			}
+			.tree .excellent {
+				--accent-color: var(--excellent-color);
+			}
+			body.with-sidebar {
+				padding-left: 16rem;
+			}
+			.sidebar {
+				position: fixed;
+				top: 0;
+				bottom: 0;
+				left: 0;
+				width: 16rem;
+				overflow-y: auto;
+				box-sizing: border-box;
+				padding: 0.5rem 0;
+				font-size: 0.8em;
+				background-color: #252525;
+				border-right: 1px solid #555;
+			}
+			.sidebar a {
+				display: flex;
+				justify-content: space-between;
+				gap: 0.5rem;
+				padding: 2px 0.5rem 2px calc(var(--depth) * 0.75rem);
+				color: #cfcfcf;
+				&:visited {
+					color: #cfcfcf;
+				}
+			}
+			.sidebar a.active {
+				background-color: #3a3a3a;
+			}
+			.sidebar .title {
+				overflow: hidden;
+				text-overflow: ellipsis;
+				white-space: nowrap;
+			}
+			.sidebar .percent {
+				color: var(--accent-color, #cfcfcf);
+			}
+			.sidebar .danger {
+				--accent-color: var(--danger-color);
+			}
+			.sidebar .safe {
+				--accent-color: var(--safe-color);
+			}
+			.sidebar .warning {
+				--accent-color: var(--warning-color);
+			}
+			.sidebar .excellent {
+				--accent-color: var(--excellent-color);
+			}
+			@media print {
+				:root {
+					--tok-keyword: #8b008b;
+					--tok-string: #a31515;
+					--tok-comment: #008000;
+				}
+				* {
+					-webkit-print-color-adjust: exact;
+					print-color-adjust: exact;
+				}
+				body {
+					background-color: #fff;
+					color: #000;
+				}
+				.header {
+					position: static;
+					background-color: #fff;
+				}
+				.lines {
+					--lines-background: #fff;
+				}
+				.header .stmts, .view .links a:first-child, .view .summary .stmts, .lines .covered-count {
+					background-color: #fff;
+					color: #000;
+				}
+				a, .view .links .current, .view .summary .label, .lines .line-number, .lines pre,
+				.items .wrapper .subpath, .items .wrapper > *:not(:first-child), .tree .node, .doc, .note, .footer {
+					color: #000;
+				}
+				.lines .covered-count.covered {
+					color: #006400;
+				}
+				.view {
+					display: block !important;
+					break-after: page;
+				}
+				.lines .collapsed {
+					display: revert;
+				}
+				.lines .expander, .view .links .copy-path, .sidebar {
+					display: none;
+				}
+				body.with-sidebar {
+					padding-left: 0;
+				}
+			}
 		</style>
 	</head>
-	<body>
+	<body{{if .Sidebar}} class="with-sidebar"{{end}}>
+		{{with .Sidebar}}
+		<nav class="sidebar">
+			{{range .}}
+			<a class="{{.ClassName}}" href="{{$.Href .ID}}" style="--depth: {{len .Links}}" title="{{html .Title}}">
+				<span class="title">{{html .Title}}</span>
+				<span class="percent">{{.Percent}}</span>
+			</a>
+			{{end}}
+		</nav>
+		{{end}}
+		{{with .Summary}}
+		<div class="header">
+			{{with $.Title}}<h1 class="title">{{html .}}</h1>{{end}}
+			<div class="label">Total</div>
+			<div class="percent">{{.Percent}}</div>
+			{{with .Delta}}<div class="delta {{$.Summary.DeltaClass}}">{{.}}</div>{{end}}
+			<div class="label">Statements</div>
+			<div class="stmts">{{.NumStmtCovered}}/{{.NumStmt}}</div>
+			{{with .Branches}}
+			<div class="label" title="explicit arms of if, switch and select statements which ran">Branches</div>
+			<div class="stmts">{{.Percent}} {{.NumCovered}}/{{.NumBranches}}</div>
+			{{end}}
+			{{with .Diff}}
+			<div class="label">Changed</div>
+			<div class="percent">{{.Percent}}</div>
+			<div class="label">Statements</div>
+			<div class="stmts">{{.NumStmtCovered}}/{{.NumStmt}}</div>
+			{{end}}
+			{{with .Hidden}}<div class="note">{{.}} fully covered files hidden</div>{{end}}
+			{{range $.Warnings}}<div class="note warning">{{html .}}</div>{{end}}
+			{{with $.Removed}}
+			<details class="removed">
+				<summary>{{len .}} removed files</summary>
+				<ul>{{range .}}<li>{{html .}}</li>{{end}}</ul>
+			</details>
+			{{end}}
+		</div>
+		{{end}}
 		{{range $idx, $view := .Views}}
 		<div id="{{$view.ID}}" class="view file" style="display:none">
 			<div class="links">
-				{{range $idx, $link := $view.Links}}
-				<a href="#{{$link.ID}}">{{$link.Title}}</a>
+				{{range $view.Ancestors}}
+				<a href="{{$.Href .ID}}">{{html .Title}}</a>
 				{{end}}
+				<span class="current" aria-current="page" data-href="{{$.Href $view.ID}}">{{html $view.Title}}</span>
+				{{with $view.Path}}<button class="copy-path" data-path="{{html .}}" title="copy {{html .}}">copy path</button>{{end}}
 			</div>
+			{{if $.Pages}}
+			{{with $view.Prev}}<a class="sibling" rel="prev" href="{{$.Href .}}" hidden></a>{{end}}
+			{{with $view.Next}}<a class="sibling" rel="next" href="{{$.Href .}}" hidden></a>{{end}}
+			{{end}}
 			<div class="summary">
 				<div class="percent">{{$view.Percent}}</div>
+				{{with $view.Progress}}<div class="progress {{$view.ClassName}}"><progress value="{{.}}" max="100"></progress></div>{{end}}
+				{{with $view.Delta}}<div class="delta {{$view.DeltaClass}}">{{.}}</div>{{end}}
 				<div class="label">Statements</div>
 				<div class="stmts">{{$view.NumStmtCovered}}/{{$view.NumStmt}}</div>
+				<div class="label">Uncovered</div>
+				<div class="stmts">{{$view.NumStmtUncovered}}</div>
+				{{with $view.Branches}}
+				<div class="label" title="explicit arms of if, switch and select statements which ran">Branches</div>
+				<div class="stmts">{{.Percent}} {{.NumCovered}}/{{.NumBranches}}</div>
+				{{end}}
+				<div class="label">Lines</div>
+				<div class="stmts">{{$view.NumLines}}</div>
+				{{with $view.Note}}<div class="note">{{html .}}</div>{{end}}
+				{{if $view.Unscored}}<div class="note">not scored</div>{{end}}
+				{{with $view.Language}}<div class="note language" title="language">{{.}} source</div>{{end}}
+				{{with $view.OutOfRange}}<div class="note warning">{{.}}</div>{{end}}
+				{{with $view.Owners}}<div class="note owners" title="code owners">owned by {{html .}}</div>{{end}}
+				{{with $view.Tests}}<div class="note tests{{if .Failed}} warning{{end}}" title="go test results">tests: {{.}}</div>{{end}}
+			</div>
+			{{with $view.Doc}}
+			{{if eq . $view.Synopsis}}
+			<div class="doc">{{html .}}</div>
+			{{else}}
+			<details class="doc">
+				<summary>{{html $view.Synopsis}}</summary>
+				<div class="doc-text">{{html .}}</div>
+			</details>
+			{{end}}
+			{{end}}
+			{{with $view.Worst}}
+			<a class="worst" href="{{$.Href .ID}}" title="open the least covered file">worst file: {{html .Title}}</a>
+			{{end}}
+			{{with $view.Histogram}}
+			<div class="histogram" title="files by coverage">
+				{{range .}}
+				<div class="bar {{.ClassName}}" title="{{.Label}}: {{.Count}} files">
+					<div class="count">{{.Count}}</div>
+					<div class="track"><div class="fill" style="height: {{.Height}}%"></div></div>
+					<div class="label">{{.Label}}</div>
+				</div>
+				{{end}}
 			</div>
-			{{if $view.IsDir}}
+			{{end}}
+			{{with $view.Legend}}
+			<div class="legend" title="legend">
+				{{range .Bands}}<span class="entry {{.ClassName}}"><span class="swatch"></span>{{.Label}}</span>{{end}}
+				<span class="entry"><span class="swatch uncovered"></span>line not run</span>
+				<span class="entry"><span class="count covered">Nx</span>line run N times</span>
+				<span class="entry"><span class="swatch"></span>no statement</span>
+			</div>
+			{{end}}
+			{{if $view.Tree}}
+			<div class="tree">
+				<ul>{{template "tree" $view.Tree}}</ul>
+			</div>
+			{{else if $view.IsDir}}
 			<div class="items">
 				{{range $idx, $file := $view.Items}}
-				<a class="wrapper {{$file.ClassName}}" href="#{{$file.ID}}">
-					<div class="subpath">{{$file.Title}}</div>
+				<a class="wrapper {{$file.ClassName}}{{if $file.Unscored}} unscored{{end}}" href="{{with $file.FirstUncoveredLine}}{{$.LineHref $file.ID .}}{{else}}{{$.Href $file.ID}}{{end}}" data-id="{{$file.ID}}">
+					<div class="subpath">{{html $file.Title}}{{with $file.Note}} <span class="note">{{html .}}</span>{{end}}{{if $file.New}} <span class="note">new</span>{{end}}{{if $file.Unscored}} <span class="note">not scored</span>{{end}}</div>
 					<div class="progress"><progress value="{{$file.Progress}}" max="100"></progress></div>
-					<div class="percent">{{$file.Percent}}</div>
+					<div class="percent">{{$file.Percent}}{{with $file.Delta}} <span class="delta {{$file.DeltaClass}}">{{.}}</span>{{end}}</div>
 					<div class="statements">{{$file.NumStmtCovered}}/{{$file.NumStmt}}</div>
+					<div class="uncovered-stmts" title="uncovered statements">{{$file.NumStmtUncovered}}</div>
+					<div class="num-lines" title="lines">{{$file.NumLines}} lines</div>
 				</a>
 				{{end}}
 			</div>
 			{{else}}
-			<div class="lines">
-				{{$view.Lines}}
+			{{if $view.Funcs}}
+			<div class="items funcs">
+				{{range $idx, $func := $view.Funcs}}
+				<div class="wrapper {{$func.ClassName}}">
+					<div class="subpath">{{html $func.Title}}</div>
+					<div class="progress"><progress value="{{$func.Progress}}" max="100"></progress></div>
+					<div class="percent">{{$func.Percent}}</div>
+					<div class="statements">{{$func.NumStmtCovered}}/{{$func.NumStmt}}</div>
+					<div class="uncovered-stmts" title="uncovered statements">{{$func.NumStmtUncovered}}</div>
+					<div class="num-lines" title="lines">{{$func.NumLines}} lines</div>
+				</div>
+				{{end}}
+			</div>
+			{{end}}
+			<div class="lines"{{if $.Collapse}} data-collapse="{{$.Collapse}}"{{end}}>
+				{{lines $view}}
 			</div>
 			{{end}}
 		</div>
 		{{end}}
+		<div class="footer">Generated by covreport {{html .Version}} at {{.Generated}}</div>
+		{{with .Profile}}<script type="application/json" id="profile">{{.}}</script>{{end}}
+		{{if .LiveReload}}
+		<script>
+		// In watch mode, the report reloads itself once regenerated, checking for a new version every second.
+		// Browsers refuse to fetch local files, so a report opened from disk notes it must be reloaded by hand.
+		(() => {
+			if (!window.location.protocol.startsWith('http')) {
+				const note = document.createElement('div');
+				note.className = 'note';
+				note.textContent = 'serve the report over HTTP to reload it once regenerated';
+				document.querySelector('.header').appendChild(note);
+				return;
+			}
+			let previous = null;
+			const check = () => fetch(window.location.href.split('#')[0], {cache: 'no-store'})
+				.then((response) => response.text())
+				.then((text) => {
+					if (previous !== null && text !== previous) {
+						window.location.reload();
+					}
+					previous = text;
+					setTimeout(check, 1000);
+				})
+				.catch(() => setTimeout(check, 5000));
+			check();
+		})();
+		</script>
+		{{end}}
 	</body>
+	{{define "tree"}}
+	<li class="{{.ClassName}}">
+		{{if .IsDir}}
+		<details open>
+			<summary>{{template "node" .}}</summary>
+			<ul>{{range $idx, $child := .Children}}{{template "tree" $child}}{{end}}</ul>
+		</details>
+		{{else}}
+		{{template "node" .}}
+		{{end}}
+	</li>
+	{{end}}
+	{{define "node"}}
+	<a class="node{{if .Unscored}} unscored{{end}}" href="#{{.ID}}">
+		<span class="subpath">{{html .Title}}{{with .Note}} <span class="note">{{html .}}</span>{{end}}{{if .New}} <span class="note">new</span>{{end}}{{if .Unscored}} <span class="note">not scored</span>{{end}}</span>
+		<progress value="{{.Progress}}" max="100"></progress>
+		<span class="percent">{{.Percent}}</span>
+		{{with .Delta}}<span class="delta {{$.DeltaClass}}">{{.}}</span>{{end}}
+		<span class="statements">{{.NumStmtCovered}}/{{.NumStmt}}</span>
+		<span class="uncovered-stmts" title="uncovered statements">{{.NumStmtUncovered}}</span>
+		<span class="num-lines" title="lines">{{.NumLines}} lines</span>
+	</a>
+	{{end}}
 	<script>
 	const initialID = '{{.InitialID}}';
 
+	// Fragments reference a view by its ID, or a range of lines of a file view like #ID-L40-L52, or #ID-L40.
+	const lineRangeRe = /^(.+?)-L(\d+)(?:-L(\d+))?$/;
+	const parseHash = (hash) => {
+		const id = hash.substring(1);
+		const match = lineRangeRe.exec(id);
+		if (!match || !document.getElementById(match[1])) {
+			return {id: id};
+		}
+		const from = parseInt(match[2], 10);
+		const to = match[3] ? parseInt(match[3], 10) : from;
+		return {id: match[1], from: Math.min(from, to), to: Math.max(from, to)};
+	};
+	const highlightLines = (view, from, to, scroll) => {
+		for (const cell of document.querySelectorAll('.lines .highlighted')) {
+			cell.classList.remove('highlighted');
+		}
+		let first = null;
+		for (const number of view.querySelectorAll('.lines .line-number')) {
+			const line = parseInt(number.textContent, 10);
+			if (!from || line < from || line > to) {
+				continue;
+			}
+			const cells = [number, number.nextElementSibling, number.nextElementSibling.nextElementSibling];
+			cells.forEach((cell) => cell.classList.remove('collapsed'));
+			cells.forEach((cell) => cell.classList.add('highlighted'));
+			first = first || number;
+		}
+		if (first && scroll) {
+			first.scrollIntoView({block: 'center'});
+		}
+	};
+
+	// The sidebar highlights the entry of the view, or of the nearest directory above a file view.
+	// Entries and breadcrumbs link to the same view with the same href, in single page and split reports alike,
+	// the current segment of the breadcrumbs holding the href of its view without linking to it.
+	const highlightSidebar = (view) => {
+		const entries = Array.from(document.querySelectorAll('.sidebar a'));
+		const hrefs = Array.from(view.querySelectorAll('.links a, .links .current'))
+			.map((a) => a.getAttribute('href') || a.dataset.href).reverse();
+		const active = hrefs.map((href) => entries.find((a) => a.getAttribute('href') === href)).find((a) => a);
+		entries.forEach((a) => a.classList.toggle('active', a === active));
+		if (active) {
+			active.scrollIntoView({block: 'nearest'});
+		}
+	};
+
 	window.renderView = () => {
 		for (const view of document.getElementsByClassName('view')) {
 			view.style.display = 'none';
 		};
-		const id = window.location.hash ? window.location.hash.substring(1) : initialID;
+		const {id, from, to} = window.location.hash ? parseHash(window.location.hash) : {id: initialID};
 		const target = document.getElementById(id) || document.getElementById(initialID);
 		target.style.display = 'block';
+		highlightLines(target, from, to, true);
+		highlightSidebar(target);
 	};
 	window.addEventListener('hashchange', () => {
 		window.renderView();
 	});
+
+	// Clicking a line number references it, shift-clicking references the range up to the referenced line.
+	document.addEventListener('click', (event) => {
+		const number = event.target.closest('.lines .line-number');
+		if (!number) {
+			return;
+		}
+		const view = number.closest('.view');
+		let from = parseInt(number.textContent, 10);
+		let to = from;
+		const current = parseHash(window.location.hash);
+		if (event.shiftKey && current.id === view.id && current.from) {
+			from = Math.min(from, current.from);
+			to = Math.max(to, current.from);
+		}
+		history.replaceState(null, '', '#' + view.id + '-L' + from + (to !== from ? '-L' + to : ''));
+		highlightLines(view, from, to, false);
+	});
+
+	// Collapse runs of lines with the same coverage behind an expander.
+	// Uncovered runs keep more of their lines visible than other runs.
+	window.collapseRuns = (lines, threshold) => {
+		const codes = Array.from(lines.querySelectorAll('pre.line'));
+		const state = (code) => code.classList.contains('uncovered') ? 'uncovered' : code.classList.contains('covered') ? 'covered' : '';
+		const collapse = (hidden, className) => {
+			const cells = hidden.flatMap((code) => [code.previousElementSibling.previousElementSibling, code.previousElementSibling, code]);
+			const expander = document.createElement('button');
+			expander.className = ('expander ' + className).trim();
+			expander.textContent = '\u2026 ' + hidden.length + ' lines \u2026';
+			expander.addEventListener('click', () => {
+				cells.forEach((cell) => cell.classList.remove('collapsed'));
+				expander.remove();
+			});
+			cells.forEach((cell) => cell.classList.add('collapsed'));
+			lines.insertBefore(expander, cells[0]);
+		};
+		let start = 0;
+		for (let i = 1; i <= codes.length; i++) {
+			if (i < codes.length && state(codes[i]) === state(codes[start])) {
+				continue;
+			}
+			const className = state(codes[start]);
+			const keep = className === 'uncovered' ? Math.floor(threshold / 2) : 1;
+			if (i - start > threshold && i - start > 2 * keep) {
+				collapse(codes.slice(start + keep, i - keep), className);
+			}
+			start = i;
+		}
+	};
+	for (const lines of document.querySelectorAll('.lines[data-collapse]')) {
+		window.collapseRuns(lines, parseInt(lines.dataset.collapse, 10));
+	}
+	// Rendered once runs are collapsed, so referenced lines show.
 	window.renderView();
+
+	// Copy the path of the file to the clipboard, falling back on a selection where the Clipboard API is missing.
+	const copyText = (text) => {
+		if (navigator.clipboard) {
+			return navigator.clipboard.writeText(text);
+		}
+		const area = document.createElement('textarea');
+		area.value = text;
+		document.body.appendChild(area);
+		area.select();
+		document.execCommand('copy');
+		area.remove();
+		return Promise.resolve();
+	};
+	for (const button of document.querySelectorAll('.copy-path')) {
+		button.addEventListener('click', () => {
+			copyText(button.dataset.path).then(() => {
+				button.textContent = 'copied';
+				setTimeout(() => {
+					button.textContent = 'copy path';
+				}, 1000);
+			});
+		});
+	}
+
+	// Keyboard navigation: u goes up to the parent directory, j and k move to the next and previous item.
+	// In a directory view, j and k select its items, opened with Enter. In a file view, they open the
+	// sibling items of the parent directory, from its view, or from the links to them of the pages of split reports.
+	// Links are followed by clicking them, so they work in split reports too.
+	const currentView = () => Array.from(document.getElementsByClassName('view')).find((view) => view.style.display !== 'none');
+	const parentLink = (view) => {
+		const links = view.querySelectorAll('.links a');
+		return links.length > 0 ? links[links.length - 1] : null;
+	};
+	const moveItem = (items, idx, step) => {
+		const next = Math.min(Math.max(idx + step, 0), items.length - 1);
+		return next === idx && idx >= 0 ? null : items[next];
+	};
+	window.addEventListener('keydown', (event) => {
+		if (event.altKey || event.ctrlKey || event.metaKey || event.target.closest('input, textarea, select')) {
+			return;
+		}
+		const view = currentView();
+		if (!view) {
+			return;
+		}
+		const own = view.querySelector(':scope > .items:not(.funcs)');
+		const selected = own && own.querySelector('a.wrapper.selected');
+		if (event.key === 'u') {
+			const link = parentLink(view);
+			if (link) {
+				link.click();
+			}
+			return;
+		}
+		if (event.key === 'Enter' && selected) {
+			selected.click();
+			return;
+		}
+		if (event.key !== 'j' && event.key !== 'k') {
+			return;
+		}
+		const step = event.key === 'j' ? 1 : -1;
+		if (own) {
+			const items = Array.from(own.querySelectorAll('a.wrapper'));
+			const item = moveItem(items, items.indexOf(selected), step);
+			if (item) {
+				items.forEach((a) => a.classList.toggle('selected', a === item));
+				item.firstElementChild.scrollIntoView({block: 'nearest'});
+			}
+			return;
+		}
+		const link = parentLink(view);
+		const parent = link && link.hash && document.getElementById(link.hash.substring(1));
+		if (parent) {
+			const items = Array.from(parent.querySelectorAll(':scope > .items a.wrapper'));
+			const item = moveItem(items, items.findIndex((a) => a.dataset.id === view.id), step);
+			if (item) {
+				item.click();
+			}
+			return;
+		}
+		const sibling = view.querySelector(':scope > a.sibling[rel="' + (step > 0 ? 'next' : 'prev') + '"]');
+		if (sibling) {
+			sibling.click();
+		}
+	});
 	</script>
 </html>
 `
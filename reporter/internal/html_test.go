@@ -2,10 +2,16 @@ package internal
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/drappier-charles/covreport/reporter/config"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +23,7 @@ func TestReport(t *testing.T) {
 		gp := NewGoProject("/", &config.Cutlines{Safe: 70, Warning: 40}, nil)
 		file := &GoFile{GoListItem: NewGoListItem("not-exist.go")}
 		gp.Root().AddFile(file)
-		err := gp.Report(nil)
+		err := gp.Report(io.Discard)
 		assert.ErrorContains(t, err, `can't read "not-exist.go"`)
 	})
 }
@@ -32,6 +38,14 @@ func TestWriteHTMLEscapedCode(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, `&lt;&gt;&amp;&amp;    "&lt;&gt;&amp;&amp;    "`, buf.String())
 	})
+
+	t.Run("should write multi-byte characters as is", func(t *testing.T) {
+		var buf strings.Builder
+		dst := bufio.NewWriter(&buf)
+		assert.NoError(t, WriteHTMLEscapedCode(dst, `"é🟢" < 1`))
+		assert.NoError(t, dst.Flush())
+		assert.Equal(t, `"é🟢" &lt; 1`, buf.String())
+	})
 }
 
 func TestWriteHTMLEscapedLine(t *testing.T) {
@@ -59,7 +73,7 @@ func TestWriteHTMLEscapedLine(t *testing.T) {
 			}
 			expected := fmt.Sprintf(`<div class="line-number">%d</div><div class="covered-count%s">%s</div><pre class="line%s">%s</pre>%s`, ln, tc.class, count, tc.class, code, "\n")
 
-			err := WriteHTMLEscapedLine(dst, ln, tc.count, code)
+			err := WriteHTMLEscapedLine(dst, &HTMLLine{Number: ln, Count: tc.count}, code, nil)
 			assert.NoError(t, err)
 			dst.Flush()
 			assert.Equal(t, expected, buf.String())
@@ -67,6 +81,38 @@ func TestWriteHTMLEscapedLine(t *testing.T) {
 	})
 }
 
+func TestWriteHTMLEscapedLineShaded(t *testing.T) {
+	write := func(count, maxCount int) string {
+		var buf strings.Builder
+		dst := bufio.NewWriter(&buf)
+		assert.NoError(t, WriteHTMLEscapedLine(dst, &HTMLLine{Number: 1, Count: &count, MaxCount: maxCount}, "x", nil))
+		assert.NoError(t, dst.Flush())
+		return buf.String()
+	}
+
+	t.Run("should shade covered counts by their magnitude", func(t *testing.T) {
+		assert.Contains(t, write(1, 100), `<div class="covered-count covered" style="opacity: 0.40">1x</div>`)
+		assert.Contains(t, write(10, 100), `<div class="covered-count covered" style="opacity: 0.70">10x</div>`)
+	})
+
+	t.Run("should not shade the highest count", func(t *testing.T) {
+		assert.Contains(t, write(100, 100), `<div class="covered-count covered">100x</div>`)
+		assert.Contains(t, write(1, 1), `<div class="covered-count covered">1x</div>`)
+	})
+}
+
+func TestWriteHTMLEscapedLineChanged(t *testing.T) {
+	t.Run("should mark changed lines", func(t *testing.T) {
+		count := 0
+		var buf strings.Builder
+		dst := bufio.NewWriter(&buf)
+		err := WriteHTMLEscapedLine(dst, &HTMLLine{Number: 7, Count: &count, Changed: true}, "x", nil)
+		assert.NoError(t, err)
+		assert.NoError(t, dst.Flush())
+		assert.Equal(t, `<div class="line-number changed">7</div><div class="covered-count uncovered"></div><pre class="line uncovered changed">x</pre>`+"\n", buf.String())
+	})
+}
+
 func TestNewTemplateListItemData(t *testing.T) {
 	t.Run("should return data for list item", func(t *testing.T) {
 		item := &GoListItem{
@@ -74,7 +120,7 @@ func TestNewTemplateListItemData(t *testing.T) {
 			Title: "bar",
 		}
 		wr := &config.Cutlines{Safe: 70, Warning: 40}
-		result := NewTemplateListItemData(item, wr)
+		result := NewTemplateListItemData(item, wr, 1)
 		assert.Equal(t, item.ID, result.ID)
 		assert.Equal(t, item.Title, result.Title)
 		assert.Equal(t, item.StmtCoveredCount, result.NumStmtCovered)
@@ -87,7 +133,7 @@ func TestNewTemplateListItemData(t *testing.T) {
 			Progress    string
 			Percent     string
 		}{
-			{0, 0, "", "0.0", "0.0%"},
+			{0, 0, "", "0.0", "N/A"},
 			{100, int(wr.Safe), "safe", "70.0", "70.0%"},
 			{100, int(wr.Warning), "warning", "40.0", "40.0%"},
 			{100, int(wr.Warning) - 1, "danger", "39.0", "39.0%"},
@@ -96,20 +142,76 @@ func TestNewTemplateListItemData(t *testing.T) {
 		for _, tc := range tests {
 			item.StmtCount = tc.StmtCount
 			item.StmtCoveredCount = tc.StmtCovered
-			result = NewTemplateListItemData(item, wr)
+			result = NewTemplateListItemData(item, wr, 1)
 
 			assert.Equal(t, tc.ClassName, result.ClassName)
 			assert.Equal(t, tc.Progress, result.Progress)
 			assert.Equal(t, tc.Percent, result.Percent)
 		}
 	})
+
+	t.Run("should return excellent class above the excellent cutline", func(t *testing.T) {
+		cutlines := &config.Cutlines{Safe: 70, Warning: 40, Excellent: 95}
+		tests := []struct {
+			StmtCovered int
+			ClassName   string
+		}{
+			{100, "excellent"},
+			{95, "excellent"},
+			{94, "safe"},
+			{70, "safe"},
+			{69, "warning"},
+		}
+		for _, tc := range tests {
+			result := NewTemplateListItemData(&GoListItem{StmtCount: 100, StmtCoveredCount: tc.StmtCovered}, cutlines, 1)
+			assert.Equal(t, tc.ClassName, result.ClassName, tc.StmtCovered)
+		}
+	})
+}
+
+func TestClassifyConsistently(t *testing.T) {
+	tests := []struct {
+		Cutlines  *config.Cutlines
+		ClassName string
+	}{
+		{&config.Cutlines{Safe: 70, Warning: 40}, config.ClassWarning},
+		{&config.Cutlines{Safe: 60, Warning: 40}, config.ClassSafe},
+		{&config.Cutlines{Safe: 90, Warning: 80}, config.ClassDanger},
+	}
+	for _, tc := range tests {
+		t.Run("should classify a file at 65% as "+tc.ClassName+" in every format", func(t *testing.T) {
+			gp := NewGoProject(".", tc.Cutlines, nil)
+			gp.SafeDir("app").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/x.go", Title: "x.go", StmtCount: 20, StmtCoveredCount: 13}})
+			gp.Root().Aggregate()
+
+			assert.Equal(t, tc.ClassName, tc.Cutlines.Classify(65))
+			item := NewTemplateListItemData(gp.Root().AllFiles()[0].GoListItem, gp.Cutlines, gp.Precision)
+			assert.Equal(t, tc.ClassName, item.ClassName)
+
+			var markdown strings.Builder
+			assert.NoError(t, gp.ReportMarkdown(&markdown))
+			assert.Contains(t, markdown.String(), "| "+markdownMarks[tc.ClassName]+" | x.go |")
+
+			var treemap strings.Builder
+			assert.NoError(t, gp.ReportTreemap(&treemap))
+			assert.Contains(t, treemap.String(), `fill="`+treemapColors[tc.ClassName]+`"`)
+		})
+	}
+}
+
+func TestFormatPercent(t *testing.T) {
+	assert.Equal(t, "N/A", FormatPercent(0, 0, 1))
+	assert.Equal(t, "0.0%", FormatPercent(0, 3, 1))
+	assert.Equal(t, "66.7%", FormatPercent(200.0/3, 3, 1))
+	assert.Equal(t, "67%", FormatPercent(200.0/3, 3, 0))
+	assert.Equal(t, "66.67%", FormatPercent(200.0/3, 3, 2))
 }
 
 func TestAddFile(t *testing.T) {
 	_, curFilename, _, ok := runtime.Caller(0)
 	assert.True(t, ok)
 
-	td := &TemplateData{}
+	td := &TemplateData{Precision: 1}
 	file := &GoFile{
 		GoListItem: &GoListItem{
 			RelPkgPath:       "pkg/path",
@@ -130,19 +232,947 @@ func TestAddFile(t *testing.T) {
 		{ID: "link_id_2", Title: "link_title_2"},
 	}
 
-	err := td.AddFile(file, links)
-	assert.NoError(t, err)
+	td.AddFile(file, links)
 
 	assert.Len(t, td.Views, 1)
-	assert.NotEmpty(t, td.Views[0].Lines)
+	assert.Same(t, file, td.Views[0].file)
 
 	assert.Equal(t, file.ID, td.Views[0].ID)
 	assert.Len(t, td.Views[0].Links, 3)
 
 	assert.Equal(t, file.ID, td.Views[0].Links[2].ID)
 	assert.Equal(t, file.Title, td.Views[0].Links[2].Title)
+	assert.Equal(t, "pkg/path", td.Views[0].Path)
 
 	assert.Equal(t, file.StmtCoveredCount, td.Views[0].NumStmtCovered)
 	assert.Equal(t, file.StmtCount, td.Views[0].NumStmt)
 	assert.Equal(t, fmt.Sprintf("%.1f%%", file.Percent()), td.Views[0].Percent)
+	assert.Equal(t, "50.0", td.Views[0].Progress)
+}
+
+func TestAddTree(t *testing.T) {
+	gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+	a := gp.SafeDir("a")
+	a.AddFile(&GoFile{GoListItem: NewGoListItem("a/x.go")})
+	b := gp.SafeDir("a/b")
+	b.AddFile(&GoFile{GoListItem: NewGoListItem("a/b/y.go")})
+
+	td := &TemplateData{InitialID: a.ID, Cutlines: gp.Cutlines}
+	td.AddTree(a)
+
+	assert.Equal(t, TreeViewID, td.InitialID)
+	assert.Len(t, td.Views, 1)
+	root := td.Views[0].Tree
+	assert.Equal(t, "a", root.Title)
+	assert.True(t, root.IsDir)
+	assert.Len(t, root.Children, 2)
+	assert.Equal(t, b.ID, root.Children[0].ID)
+	assert.True(t, root.Children[0].IsDir)
+	assert.Equal(t, "y.go", root.Children[0].Children[0].Title)
+	assert.Equal(t, "x.go", root.Children[1].Title)
+	assert.False(t, root.Children[1].IsDir)
+}
+
+func TestReportLayout(t *testing.T) {
+	t.Run("should return error with unknown layout", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Layout = "unknown"
+		err := gp.Report(io.Discard)
+		assert.ErrorContains(t, err, `unknown layout "unknown"`)
+	})
+
+	t.Run("should render tree view", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Layout = config.LayoutTree
+		gp.SafeDir("a/b")
+		var buf strings.Builder
+		err := gp.Report(&buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `<div class="tree">`)
+		assert.Contains(t, buf.String(), `const initialID = 'tree';`)
+	})
+
+	t.Run("should render flat view of every file with full paths", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: &GoListItem{ID: "y", RelPkgPath: "a/b/y.go", Title: "y.go", StmtCount: 4, StmtCoveredCount: 3}})
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{ID: "x", RelPkgPath: "a/x.go", Title: "x.go", StmtCount: 4, StmtCoveredCount: 1}})
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{ID: "w", RelPkgPath: "a/w.go", Title: "w.go", StmtCount: 4, StmtCoveredCount: 3}})
+		gp.Root().Aggregate()
+
+		data := &TemplateData{Cutlines: gp.Cutlines, Sort: config.SortWorst, Precision: 1}
+		data.AddFlat(gp.InitialDir())
+		assert.Equal(t, FlatViewID, data.InitialID)
+		view := data.Views[len(data.Views)-1]
+		var titles []string
+		for _, item := range view.Items {
+			titles = append(titles, item.Title)
+		}
+		assert.Equal(t, []string{"x.go", "b/y.go", "w.go"}, titles)
+		assert.Equal(t, "58.3%", view.Percent)
+
+		gp = NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Layout = config.LayoutFlat
+		gp.SafeDir("a/b")
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), `const initialID = 'files';`)
+	})
+}
+
+func TestReportSummary(t *testing.T) {
+	t.Run("should render project totals in the header", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		root := gp.Root()
+		root.StmtCount = 8
+		root.StmtCoveredCount = 2
+		gp.SafeDir("a")
+
+		var buf strings.Builder
+		err := gp.Report(&buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `<div class="header">`)
+		assert.Contains(t, buf.String(), `<div class="percent">25.0%</div>`)
+		assert.Contains(t, buf.String(), `<div class="stmts">2/8</div>`)
+	})
+
+	t.Run("should render the branch coverage next to the statements", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		root := gp.Root()
+		root.StmtCount, root.StmtCoveredCount = 8, 2
+		root.BranchCount, root.BranchCoveredCount = 4, 1
+
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), `>Branches</div>
+			<div class="stmts">25.0% 1/4</div>`)
+	})
+
+	t.Run("should not render the branch coverage without branches", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.NotContains(t, buf.String(), `>Branches</div>`)
+	})
+}
+
+func TestSortListItems(t *testing.T) {
+	cutlines := &config.Cutlines{Safe: 70, Warning: 40}
+	newItems := func() []*TemplateListItemData {
+		return []*TemplateListItemData{
+			NewTemplateListItemData(&GoListItem{Title: "b", StmtCount: 10, StmtCoveredCount: 5}, cutlines, 1),
+			NewTemplateListItemData(&GoListItem{Title: "empty"}, cutlines, 1),
+			NewTemplateListItemData(&GoListItem{Title: "c", StmtCount: 10, StmtCoveredCount: 9}, cutlines, 1),
+			NewTemplateListItemData(&GoListItem{Title: "a", StmtCount: 10, StmtCoveredCount: 5}, cutlines, 1),
+			NewTemplateListItemData(&GoListItem{Title: "d", StmtCount: 10, StmtCoveredCount: 1}, cutlines, 1),
+		}
+	}
+	titles := func(items []*TemplateListItemData) []string {
+		var result []string
+		for _, item := range items {
+			result = append(result, item.Title)
+		}
+		return result
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"b", "empty", "c", "a", "d"}},
+		{config.SortWorst, []string{"d", "a", "b", "c", "empty"}},
+		{config.SortBest, []string{"c", "a", "b", "d", "empty"}},
+		{config.SortName, []string{"a", "b", "c", "d", "empty"}},
+		{config.SortUncovered, []string{"d", "a", "b", "c", "empty"}},
+	}
+	for _, tt := range tests {
+		t.Run("should sort by "+tt.order, func(t *testing.T) {
+			items := newItems()
+			SortListItems(items, tt.order)
+			assert.Equal(t, tt.want, titles(items))
+		})
+	}
+
+	t.Run("should count uncovered statements", func(t *testing.T) {
+		items := newItems()
+		assert.Equal(t, 5, items[0].NumStmtUncovered)
+		assert.Equal(t, 0, items[1].NumStmtUncovered)
+		assert.Equal(t, 9, items[4].NumStmtUncovered)
+	})
+
+	t.Run("should return error with unknown sort", func(t *testing.T) {
+		gp := NewGoProject(".", cutlines, nil)
+		gp.Sort = "unknown"
+		err := gp.Report(io.Discard)
+		assert.ErrorContains(t, err, `unknown sort "unknown"`)
+	})
+}
+
+func TestReportGenerated(t *testing.T) {
+	t.Run("should render generation time and version", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		var buf strings.Builder
+		err := gp.Report(&buf)
+		assert.NoError(t, err)
+
+		matches := regexp.MustCompile(`<meta name="generated" content="([^"]+)">`).FindStringSubmatch(buf.String())
+		assert.Len(t, matches, 2)
+		_, err = time.Parse(time.RFC3339, matches[1])
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), fmt.Sprintf("Generated by covreport %s at %s", Version(), matches[1]))
+	})
+}
+
+func TestAddDir(t *testing.T) {
+	temp := t.TempDir()
+	gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+	dir := gp.SafeDir("a")
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("f%02d.txt", i)
+		absPath := filepath.Join(temp, name)
+		assert.NoError(t, os.WriteFile(absPath, []byte(name), 0o644))
+		dir.AddFile(&GoFile{GoListItem: NewGoListItem("a/" + name), ABSPath: absPath})
+	}
+
+	t.Run("should render every file view in order", func(t *testing.T) {
+		td := &TemplateData{Cutlines: gp.Cutlines}
+		td.AddDir(dir, nil)
+
+		assert.Len(t, td.Views, 21)
+		assert.Equal(t, dir.ID, td.Views[0].ID)
+		for i, file := range dir.Files {
+			assert.Equal(t, file.ID, td.Views[i+1].ID)
+		}
+
+		var buf strings.Builder
+		assert.NoError(t, gp.executeTemplate(&buf, td))
+		previous := 0
+		for _, file := range dir.Files {
+			view := strings.Index(buf.String(), `<div id="`+file.ID+`"`)
+			lines := strings.Index(buf.String(), `<pre class="line">`+file.Title+`</pre>`)
+			assert.Greater(t, view, previous, file.Title)
+			assert.Greater(t, lines, view, file.Title)
+			previous = lines
+		}
+	})
+
+	t.Run("should return error of the first failing file", func(t *testing.T) {
+		broken := gp.SafeDir("b")
+		broken.AddFile(&GoFile{GoListItem: NewGoListItem("b/x.go"), ABSPath: filepath.Join(temp, "x.go")})
+		broken.AddFile(&GoFile{GoListItem: NewGoListItem("b/y.go"), ABSPath: filepath.Join(temp, "y.go")})
+
+		td := &TemplateData{Cutlines: gp.Cutlines}
+		td.AddDir(broken, nil)
+		err := gp.executeTemplate(io.Discard, td)
+		assert.ErrorContains(t, err, `can't read "b/x.go"`)
+		assert.NotContains(t, err.Error(), "template")
+	})
+}
+
+// renderLines returns the lines of the file rendered with the template data.
+func renderLines(td *TemplateData, file *GoFile) (string, error) {
+	var buf strings.Builder
+	err := td.RenderLines(&buf, file)
+	return buf.String(), err
+}
+
+func TestRenderLines(t *testing.T) {
+	t.Run("should stream lines longer than the default scanner buffer", func(t *testing.T) {
+		long := strings.Repeat("x", 1<<20)
+		absPath := filepath.Join(t.TempDir(), "long.txt")
+		assert.NoError(t, os.WriteFile(absPath, []byte("a\n"+long+"\nb"), 0o644))
+
+		lines, err := renderLines(&TemplateData{}, &GoFile{GoListItem: NewGoListItem("long.txt"), ABSPath: absPath})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, strings.Count(lines, `<div class="line-number">`))
+		assert.Contains(t, lines, `<pre class="line">`+long+`</pre>`)
+	})
+
+	t.Run("should render the empty line after a trailing newline", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.txt")
+		assert.NoError(t, os.WriteFile(absPath, []byte("a\nb\n"), 0o644))
+
+		lines, err := renderLines(&TemplateData{}, &GoFile{GoListItem: NewGoListItem("x.txt"), ABSPath: absPath})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, strings.Count(lines, `<div class="line-number">`))
+		assert.Contains(t, lines, `<div class="line-number">3</div><div class="covered-count"></div><pre class="line"></pre>`)
+	})
+
+	t.Run("should attribute counts of blocks shuffled in the profile", func(t *testing.T) {
+		temp := t.TempDir()
+		source := filepath.Join(temp, "x.go")
+		assert.NoError(t, os.WriteFile(source, []byte("a; b; c\nd\ne\nf\ng\n"), 0o644))
+		input := filepath.Join(temp, "cover.prof")
+		assert.NoError(t, os.WriteFile(input, []byte(strings.ReplaceAll("mode: count\n"+
+			"x.go:4.1,5.2 2 4\nx.go:1.7,1.8 1 3\nx.go:2.1,2.2 1 0\nx.go:1.1,1.2 1 1\nx.go:1.4,1.5 1 2\n", "x.go", source)), 0o644))
+
+		gp := NewGoProject(temp, nil, nil)
+		assert.NoError(t, gp.Parse(input))
+		files := gp.Root().AllFiles()
+		assert.Len(t, files, 1)
+
+		lines, err := renderLines(&TemplateData{}, files[0])
+		assert.NoError(t, err)
+		assert.Contains(t, lines, `<div class="line-number">1</div><div class="covered-count covered" title="block 1.1,1.2: 1x&#10;block 1.4,1.5: 2x&#10;block 1.7,1.8: 3x" style="opacity: 0.40">1x</div>`)
+		assert.Contains(t, lines, `<div class="line-number">2</div><div class="covered-count uncovered" title="block 2.1,2.2: 0x"></div>`)
+		assert.Contains(t, lines, `<div class="line-number">3</div><div class="covered-count"></div>`)
+		assert.Contains(t, lines, `<div class="line-number">4</div><div class="covered-count covered" title="block 4.1,5.2: 4x">4x</div>`)
+		assert.Contains(t, lines, `<div class="line-number">5</div><div class="covered-count covered" title="block 4.1,5.2: 4x">4x</div>`)
+	})
+}
+
+func TestLineBlocks(t *testing.T) {
+	blocks := []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 10, EndLine: 3, EndCol: 2, NumStmt: 1, Count: 2},
+		{StartLine: 3, StartCol: 8, EndLine: 4, EndCol: 2, NumStmt: 1, Count: 0},
+	}
+	assert.Equal(t, map[int][]cover.ProfileBlock{
+		1: blocks[:1], 2: blocks[:1], 3: blocks, 4: blocks[1:],
+	}, LineBlocks(blocks))
+}
+
+func TestStrictLineCounts(t *testing.T) {
+	blocks := []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 10, EndLine: 3, EndCol: 2, NumStmt: 1, Count: 2},
+		{StartLine: 3, StartCol: 8, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 0},
+		{StartLine: 7, StartCol: 1, EndLine: 7, EndCol: 9, NumStmt: 1, Count: 3},
+	}
+	assert.Equal(t, map[int]int{1: 2, 2: 2, 3: 0, 4: 0, 5: 0, 7: 3}, StrictLineCounts(blocks))
+}
+
+func TestRenderLinesStrict(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.txt")
+	assert.NoError(t, os.WriteFile(absPath, []byte("if x {\n\ty()\n} else {\n\tz()\n}\n"), 0o644))
+	file := &GoFile{GoListItem: NewGoListItem("x.txt"), ABSPath: absPath, Profile: []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 6, EndLine: 3, EndCol: 2, NumStmt: 1, Count: 1},
+		{StartLine: 3, StartCol: 8, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 0},
+	}}
+
+	t.Run("should show a line covered by the first block overlapping it", func(t *testing.T) {
+		lines, err := renderLines(&TemplateData{}, file)
+		assert.NoError(t, err)
+		assert.Contains(t, lines, `<div class="line-number">3</div><div class="covered-count covered" title="block 1.6,3.2: 1x&#10;block 3.8,5.2: 0x">1x</div>`)
+	})
+
+	t.Run("should show a line uncovered when a block overlapping it didn't run", func(t *testing.T) {
+		lines, err := renderLines(&TemplateData{StrictLines: true}, file)
+		assert.NoError(t, err)
+		assert.Contains(t, lines, `<div class="line-number">2</div><div class="covered-count covered" title="block 1.6,3.2: 1x">1x</div>`)
+		assert.Contains(t, lines, `<div class="line-number">3</div><div class="covered-count uncovered" title="block 1.6,3.2: 1x&#10;block 3.8,5.2: 0x"></div>`)
+		assert.Contains(t, lines, `<div class="line-number">4</div><div class="covered-count uncovered" title="block 3.8,5.2: 0x"></div>`)
+	})
+}
+
+func TestReportCollapse(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+	newProject := func() *GoProject {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Root().AddFile(&GoFile{GoListItem: NewGoListItem("x.go"), ABSPath: absPath})
+		return gp
+	}
+
+	t.Run("should not collapse by default", func(t *testing.T) {
+		var buf strings.Builder
+		assert.NoError(t, newProject().Report(&buf))
+		assert.Contains(t, buf.String(), `<div class="lines">`)
+	})
+
+	t.Run("should set the collapse threshold on file views", func(t *testing.T) {
+		gp := newProject()
+		gp.Collapse = 20
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), `<div class="lines" data-collapse="20">`)
+	})
+}
+
+func TestReportLinkUncovered(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n\nfunc X() {\n}\n"), 0o644))
+	report := func(linkUncovered bool, count int) string {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		file := &GoFile{GoListItem: NewGoListItem("x.go"), ABSPath: absPath, Profile: []cover.ProfileBlock{{StartLine: 3, StartCol: 10, EndLine: 4, EndCol: 2, NumStmt: 1, Count: count}}}
+		gp.Root().AddFile(file)
+		gp.LinkUncovered = linkUncovered
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		return buf.String()
+	}
+	id := itemID("x.go")
+
+	t.Run("should link files to their first uncovered line", func(t *testing.T) {
+		assert.Contains(t, report(true, 0), `href="#`+id+`-L3"`)
+	})
+
+	t.Run("should link fully covered files to their view", func(t *testing.T) {
+		html := report(true, 1)
+		assert.Contains(t, html, `href="#`+id+`"`)
+		assert.NotContains(t, html, `href="#`+id+`-L`)
+	})
+
+	t.Run("should link files to their view by default", func(t *testing.T) {
+		assert.NotContains(t, report(false, 0), `href="#`+id+`-L`)
+	})
+
+	t.Run("should find the item of the file view by its ID for keyboard navigation", func(t *testing.T) {
+		html := report(true, 0)
+		assert.Contains(t, html, `href="#`+id+`-L3" data-id="`+id+`">`)
+		assert.Contains(t, html, "items.findIndex((a) => a.dataset.id === view.id)")
+	})
+}
+
+func TestReportSidebar(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n\nfunc X() {\n}\n"), 0o644))
+	report := func(sidebar bool) string {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		for _, name := range []string{"a/x.go", "b/x.go"} {
+			file := &GoFile{GoListItem: NewGoListItem(name), ABSPath: absPath, Profile: []cover.ProfileBlock{{StartLine: 3, StartCol: 10, EndLine: 4, EndCol: 2, NumStmt: 1}}}
+			file.StmtCount = 1
+			gp.SafeDir(filepath.Dir(name)).AddFile(file)
+		}
+		gp.Root().Aggregate()
+		gp.Sidebar = sidebar
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		return buf.String()
+	}
+
+	t.Run("should list every directory in the sidebar", func(t *testing.T) {
+		html := report(true)
+		assert.Contains(t, html, `<body class="with-sidebar">`)
+		assert.Contains(t, html, `<a class="danger" href="#`+itemID(".")+`" style="--depth: 1" title="root">`)
+		assert.Contains(t, html, `<a class="danger" href="#`+itemID("a")+`" style="--depth: 2" title="a">`)
+		assert.Contains(t, html, `<a class="danger" href="#`+itemID("b")+`" style="--depth: 2" title="b">`)
+		assert.NotContains(t, html, `href="#`+itemID("a/x.go")+`" style=`)
+	})
+
+	t.Run("should not show the sidebar by default", func(t *testing.T) {
+		html := report(false)
+		assert.Contains(t, html, "<body>")
+		assert.NotContains(t, html, `<nav class="sidebar">`)
+	})
+}
+
+func TestReportBreadcrumbs(t *testing.T) {
+	t.Run("should link the ancestors and not the current view", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath})
+		gp.SafeDir("b").AddFile(&GoFile{GoListItem: NewGoListItem("b/y.go"), ABSPath: absPath})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		html := buf.String()
+		assert.Contains(t, html, `<a href="#`+itemID("a")+`">a</a>`)
+		assert.Contains(t, html, `<span class="current" aria-current="page" data-href="#`+itemID("a/x.go")+`">x.go</span>`)
+		assert.NotContains(t, html, `<a href="#`+itemID("a/x.go")+`">x.go</a>`)
+	})
+
+	t.Run("should list all but the last link as ancestors", func(t *testing.T) {
+		links := []*TemplateLinkData{{ID: "1", Title: "root"}, {ID: "2", Title: "a"}}
+		view := &TemplateViewData{Links: links}
+		assert.Equal(t, links[:1], view.Ancestors())
+		assert.Equal(t, "a", view.Title())
+		assert.Empty(t, (&TemplateViewData{}).Ancestors())
+	})
+}
+
+func TestReportOwners(t *testing.T) {
+	t.Run("should show the owners in the views", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath})
+		gp.SafeDir("a").Owners = []string{"@org/core", "@alice"}
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Equal(t, 1, strings.Count(buf.String(), `<div class="note owners" title="code owners">owned by @org/core @alice</div>`))
+	})
+}
+
+func TestReportLiveReload(t *testing.T) {
+	report := func(liveReload bool) string {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.LiveReload = liveReload
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		return buf.String()
+	}
+
+	t.Run("should reload the report in watch mode", func(t *testing.T) {
+		assert.Contains(t, report(true), "window.location.reload()")
+	})
+
+	t.Run("should only reload the report served over HTTP", func(t *testing.T) {
+		html := report(true)
+		assert.Contains(t, html, "if (!window.location.protocol.startsWith('http')) {")
+		assert.Contains(t, html, "serve the report over HTTP to reload it once regenerated")
+		assert.Contains(t, html, ".catch(() => setTimeout(check, 5000));")
+	})
+
+	t.Run("should not reload the report by default", func(t *testing.T) {
+		assert.NotContains(t, report(false), "window.location.reload()")
+	})
+}
+
+func TestReportHidden(t *testing.T) {
+	t.Run("should note the hidden files in the header", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Hidden = 2
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), `<div class="note">2 fully covered files hidden</div>`)
+	})
+}
+
+func TestReportWorst(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+	report := func(covered int) string {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		file := &GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath}
+		file.StmtCount, file.StmtCoveredCount = 2, covered
+		gp.SafeDir("a").AddFile(file)
+		gp.Root().Aggregate()
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		return buf.String()
+	}
+
+	t.Run("should link the initial view to the worst file", func(t *testing.T) {
+		assert.Contains(t, report(1), `<a class="worst" href="#`+itemID("a/x.go")+`" title="open the least covered file">worst file: a/x.go</a>`)
+	})
+
+	t.Run("should not link fully covered reports", func(t *testing.T) {
+		assert.NotContains(t, report(2), `<a class="worst"`)
+	})
+}
+
+func TestReportUnscored(t *testing.T) {
+	t.Run("should mute unscored files", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.pb.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		file := &GoFile{GoListItem: NewGoListItem("a/x.pb.go"), ABSPath: absPath}
+		file.StmtCount, file.Unscored = 2, true
+		gp.SafeDir("a").AddFile(file)
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		html := buf.String()
+		assert.Contains(t, html, `<a class="wrapper danger unscored" href="#`+itemID("a/x.pb.go")+`" data-id="`+itemID("a/x.pb.go")+`">`)
+		assert.Contains(t, html, `x.pb.go <span class="note">not scored</span>`)
+		assert.Contains(t, html, `<div class="note">not scored</div>`)
+	})
+}
+
+func TestReportLanguages(t *testing.T) {
+	t.Run("should label the files of other languages than Go", func(t *testing.T) {
+		temp := t.TempDir()
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		for _, name := range []string{"x.go", "add.c", "y_amd64.s"} {
+			absPath := filepath.Join(temp, name)
+			assert.NoError(t, os.WriteFile(absPath, []byte("x\n"), 0o644))
+			gp.SafeDir("a").AddFile(&GoFile{GoListItem: NewGoListItem("a/" + name), ABSPath: absPath})
+		}
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		html := buf.String()
+		assert.Contains(t, html, `<div class="note language" title="language">C source</div>`)
+		assert.Contains(t, html, `<div class="note language" title="language">Assembly source</div>`)
+		assert.NotContains(t, html, `Go source</div>`)
+	})
+}
+
+func TestReportUnusualPaths(t *testing.T) {
+	t.Run("should link and escape paths with spaces and special characters", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Sidebar = true
+		file := &GoFile{GoListItem: NewGoListItem(`a b/#x"<y>.go`), ABSPath: absPath}
+		file.StmtCount = 1
+		gp.SafeDir("a b").AddFile(file)
+		gp.SafeDir("c").AddFile(&GoFile{GoListItem: NewGoListItem("c/z.go"), ABSPath: absPath})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		html := buf.String()
+		assert.Contains(t, html, `<div id="`+file.ID+`" class="view file"`)
+		assert.Contains(t, html, `href="#`+file.ID+`"`)
+		assert.Contains(t, html, `href="#`+gp.SafeDir("a b").ID+`"`)
+		assert.Contains(t, html, `#x&#34;&lt;y&gt;.go`)
+		assert.NotContains(t, html, `<y>`)
+		assert.NotContains(t, html, `x"<`)
+	})
+
+	t.Run("should escape the notes of the files", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+		for _, layout := range []string{config.LayoutDrilldown, config.LayoutTree} {
+			gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+			gp.Layout = layout
+			file := &GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath}
+			file.Note = "not built for <script>"
+			gp.SafeDir("a").AddFile(file)
+
+			var buf strings.Builder
+			assert.NoError(t, gp.Report(&buf))
+			assert.Contains(t, buf.String(), `<div class="note">not built for &lt;script&gt;</div>`, layout)
+			assert.Contains(t, buf.String(), `<span class="note">not built for &lt;script&gt;</span>`, layout)
+			assert.NotContains(t, buf.String(), `not built for <script>`, layout)
+		}
+	})
+}
+
+func TestReportOutOfRange(t *testing.T) {
+	t.Run("should show the warnings and the blocks past the end of files", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Warnings = []string{"profile may be stale, 2 blocks end past the last line of 1 file: a/x.go"}
+		file := &GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath}
+		file.OutOfRange = []cover.ProfileBlock{
+			{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2},
+			{StartLine: 7, StartCol: 1, EndLine: 7, EndCol: 9},
+		}
+		gp.SafeDir("a").AddFile(file)
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		html := buf.String()
+		assert.Contains(t, html, `<div class="note warning">profile may be stale, 2 blocks end past the last line of 1 file: a/x.go</div>`)
+		assert.Contains(t, html, `<div class="note warning">2 profile blocks past the last line, the profile may be stale: 3.10,5.2 7.1,7.9</div>`)
+	})
+
+	t.Run("should not warn without blocks past the end of files", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.NotContains(t, buf.String(), `<div class="note warning">`)
+	})
+}
+
+func TestReportFileProgress(t *testing.T) {
+	t.Run("should show a coverage bar in the summary of file views", func(t *testing.T) {
+		absPath := filepath.Join(t.TempDir(), "x.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Root().AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "x.go", ID: "x", Title: "x.go", StmtCount: 4, StmtCoveredCount: 1}, ABSPath: absPath})
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), `<div class="progress danger"><progress value="25.0" max="100"></progress></div>`)
+	})
+}
+
+func TestReportMinify(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n\nfunc X() {}\n"), 0o644))
+	report := func(minify bool) string {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Root().AddFile(&GoFile{GoListItem: NewGoListItem("x.go"), ABSPath: absPath})
+		gp.Minify = minify
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		return buf.String()
+	}
+	full, minified := report(false), report(true)
+
+	assert.Less(t, len(minified), len(full))
+	assert.NotContains(t, minified, "\n\t")
+	assert.NotContains(t, minified, "\n\n")
+	assert.Contains(t, minified, "</pre><div class=\"line-number\">2</div>")
+	assert.Contains(t, full, "</pre>\n<div class=\"line-number\">2</div>")
+}
+
+func TestMinifyWriter(t *testing.T) {
+	html := "\n<div>\n\t<a>x</a>\n  \n\t<a>y</a>\n\t<script>\n\t\tf()\n\t\tg()\n\t</script>\n</div>\n"
+	var buf strings.Builder
+	minify := &minifyWriter{w: &buf, lineStart: true}
+	// Written in pieces splitting lines and indentations, as the template writes it.
+	for _, piece := range []string{html[:3], html[3:9], html[9:20], html[20:]} {
+		n, err := minify.Write([]byte(piece))
+		assert.NoError(t, err)
+		assert.Equal(t, len(piece), n)
+	}
+	assert.Equal(t, "<div>\n<a>x</a>\n<a>y</a>\n<script>\nf()\ng()\n</script>\n</div>", buf.String())
+}
+
+func TestReportSelfContained(t *testing.T) {
+	newProject := func() *GoProject {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Profiles = []*cover.Profile{{
+			FileName: "a/</script>.go",
+			Mode:     "set",
+			Blocks:   []cover.ProfileBlock{{StartLine: 1, StartCol: 2, EndLine: 3, EndCol: 4, NumStmt: 5, Count: 1}},
+		}}
+		return gp
+	}
+	profileRe := regexp.MustCompile(`<script type="application/json" id="profile">(.*)</script>`)
+
+	t.Run("should not embed the profile by default", func(t *testing.T) {
+		var buf strings.Builder
+		assert.NoError(t, newProject().Report(&buf))
+		assert.NotRegexp(t, profileRe, buf.String())
+	})
+
+	t.Run("should embed the profile as json", func(t *testing.T) {
+		gp := newProject()
+		gp.SelfContained = true
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+
+		matches := profileRe.FindStringSubmatch(buf.String())
+		assert.Len(t, matches, 2)
+		var profiles []*JSONProfile
+		assert.NoError(t, json.Unmarshal([]byte(matches[1]), &profiles))
+		assert.Equal(t, []*JSONProfile{{
+			FileName: "a/</script>.go",
+			Mode:     "set",
+			Blocks:   []*JSONBlock{{StartLine: 1, StartCol: 2, EndLine: 3, EndCol: 4, Statements: 5, Count: 1}},
+		}}, profiles)
+	})
+}
+
+func TestPathStyle(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "y.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package b\n"), 0o644))
+	newProject := func() (*GoProject, *GoDir) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		a := gp.SafeDir("a")
+		a.AddFile(&GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath})
+		gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: NewGoListItem("a/b/y.go"), ABSPath: absPath})
+		return gp, a
+	}
+	viewTitles := func(td *TemplateData) ([]string, []string) {
+		var links, items []string
+		for _, view := range td.Views {
+			last := view.Links[len(view.Links)-1]
+			links = append(links, last.Title)
+			for _, item := range view.Items {
+				items = append(items, item.Title)
+			}
+		}
+		return links, items
+	}
+
+	t.Run("should show base names by default", func(t *testing.T) {
+		_, a := newProject()
+		td := &TemplateData{InitialID: a.ID, Cutlines: &config.Cutlines{}}
+		td.AddDir(a, nil)
+		links, items := viewTitles(td)
+		assert.Equal(t, []string{"a", "b", "y.go", "x.go"}, links)
+		assert.Equal(t, []string{"b", "x.go", "y.go"}, items)
+	})
+
+	t.Run("should show full paths", func(t *testing.T) {
+		_, a := newProject()
+		td := &TemplateData{InitialID: a.ID, Cutlines: &config.Cutlines{}, PathStyle: config.PathStyleFull}
+		td.AddDir(a, nil)
+		links, items := viewTitles(td)
+		assert.Equal(t, []string{"a", "a/b", "a/b/y.go", "a/x.go"}, links)
+		assert.Equal(t, []string{"a/b", "a/x.go", "a/b/y.go"}, items)
+	})
+
+	t.Run("should return error with unknown path style", func(t *testing.T) {
+		gp, _ := newProject()
+		gp.PathStyle = "unknown"
+		err := gp.Report(io.Discard)
+		assert.ErrorContains(t, err, `unknown path style "unknown"`)
+	})
+}
+
+func TestReportLegend(t *testing.T) {
+	t.Run("should explain the colors on the initial view only", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.SafeDir("a/b")
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		html := buf.String()
+		assert.Equal(t, 1, strings.Count(html, `<div class="legend" title="legend">`))
+		assert.Contains(t, html, `<span class="entry danger"><span class="swatch"></span>below 40%</span>`)
+		assert.Contains(t, html, `<span class="entry safe"><span class="swatch"></span>70% and above</span>`)
+		assert.Contains(t, html, `<span class="count covered">Nx</span>line run N times`)
+	})
+}
+
+func TestReportLinesScroll(t *testing.T) {
+	t.Run("should scroll long lines with the line numbers and counts pinned", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		css := regexp.MustCompile(`(?s)\.lines \{(.*?)\}`).FindStringSubmatch(buf.String())
+		if assert.NotNil(t, css) {
+			assert.Contains(t, css[1], "grid-template-columns: 3em 3em minmax(max-content, 1fr);")
+			assert.Contains(t, css[1], "overflow-x: auto;")
+		}
+		pinned := regexp.MustCompile(`(?s)\.lines \.line-number, \.lines \.covered-count \{(.*?)\}`).FindStringSubmatch(buf.String())
+		if assert.NotNil(t, pinned) {
+			assert.Contains(t, pinned[1], "position: sticky;")
+		}
+	})
+}
+
+func TestReportColors(t *testing.T) {
+	t.Run("should keep the default palette", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), "--danger-color: red;")
+		assert.NotContains(t, buf.String(), "color-mix(in srgb, #")
+	})
+
+	t.Run("should override the palette with css variables", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Colors = &config.Colors{Danger: "#d55e00", Warning: "#f0e442", Safe: "#0072b2"}
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), "--danger-color: #d55e00;")
+		assert.Contains(t, buf.String(), "--warning-bg: color-mix(in srgb, #f0e442 20%, transparent);")
+		assert.Contains(t, buf.String(), "--safe-color: #0072b2;")
+		assert.Contains(t, buf.String(), "--uncovered-line-color: #d55e00;")
+	})
+
+	t.Run("should override the line backgrounds with css variables", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.LineBackgrounds = &config.LineBackgrounds{
+			Uncovered: config.LineBackground{Opacity: 0.25},
+			Covered:   config.LineBackground{Color: "#0072b2", Opacity: 0.6},
+		}
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), "--uncovered-line-opacity: 0.25;")
+		assert.Contains(t, buf.String(), "--covered-line-color: #0072b2;")
+		assert.Contains(t, buf.String(), "--covered-line-opacity: 0.6;")
+		assert.Equal(t, 1, strings.Count(buf.String(), "--uncovered-line-color:"))
+	})
+}
+
+func TestReportCopyPath(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+	gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+	gp.SafeDir("a").AddFile(&GoFile{GoListItem: NewGoListItem(`a/"x".go`), ABSPath: absPath})
+
+	var buf strings.Builder
+	assert.NoError(t, gp.Report(&buf))
+	assert.Equal(t, 1, strings.Count(buf.String(), `<button class="copy-path"`))
+	assert.Contains(t, buf.String(), `data-path="a/&#34;x&#34;.go"`)
+}
+
+func TestReportLineRange(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n\nfunc f() {}\n"), 0o644))
+	gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+	file := &GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath, Profile: []cover.ProfileBlock{
+		{StartLine: 3, StartCol: 10, EndLine: 3, EndCol: 12, NumStmt: 1, Count: 0},
+	}}
+	gp.SafeDir("a").AddFile(file)
+	gp.LinkUncovered = true
+
+	var buf strings.Builder
+	assert.NoError(t, gp.Report(&buf))
+	out := buf.String()
+	assert.Contains(t, out, `const lineRangeRe = /^(.+?)-L(\d+)(?:-L(\d+))?$/;`)
+
+	t.Run("should link the first uncovered line of the file view", func(t *testing.T) {
+		assert.Contains(t, out, `href="#`+file.ID+`-L3"`)
+		assert.Contains(t, out, `<div id="`+file.ID+`" class="view file"`)
+	})
+
+	t.Run("should number the lines of the file view", func(t *testing.T) {
+		view := out[strings.Index(out, `<div id="`+file.ID+`"`):]
+		for _, ln := range []int{1, 2} {
+			assert.Contains(t, view, fmt.Sprintf(`<div class="line-number">%d</div>`, ln))
+		}
+		assert.Contains(t, view, `<div class="line-number">3</div><div class="covered-count uncovered"`)
+	})
+
+	t.Run("should not give views IDs which read as line ranges", func(t *testing.T) {
+		for _, id := range []string{file.ID, gp.SafeDir("a").ID} {
+			assert.Contains(t, out, `id="`+id+`"`)
+			assert.NotContains(t, id, "-L")
+		}
+	})
+}
+
+func TestReportDoc(t *testing.T) {
+	gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+	gp.SafeDir("a").Doc = "Package a does <things>.\n\nIt does them well."
+	gp.SafeDir("a/b").Doc = "Package b does things."
+	gp.CollapseRoot = false
+
+	var buf strings.Builder
+	assert.NoError(t, gp.Report(&buf))
+	assert.Contains(t, buf.String(), "<summary>Package a does &lt;things&gt;.</summary>")
+	assert.Contains(t, buf.String(), `<div class="doc-text">Package a does &lt;things&gt;.`)
+	assert.Contains(t, buf.String(), `<div class="doc">Package b does things.</div>`)
+}
+
+func TestReportTitle(t *testing.T) {
+	t.Run("should use the default title", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), "<title>Go Coverage Report</title>")
+		assert.NotContains(t, buf.String(), `<h1 class="title">`)
+	})
+
+	t.Run("should set the title and the heading", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Title = "billing <api>"
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), "<title>billing &lt;api&gt;</title>")
+		assert.Contains(t, buf.String(), `<h1 class="title">billing &lt;api&gt;</h1>`)
+	})
+}
+
+func TestReportCompare(t *testing.T) {
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+	newProject := func(covered int, paths ...string) *GoProject {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		for _, path := range paths {
+			file := &GoFile{GoListItem: NewGoListItem(path), ABSPath: absPath}
+			file.StmtCount, file.StmtCoveredCount = 10, covered
+			gp.SafeDir("a").AddFile(file)
+		}
+		gp.Root().Aggregate()
+		return gp
+	}
+
+	gp := newProject(5, "a/x.go", "a/new.go")
+	gp.Compare(newProject(4, "a/x.go", "a/old.go"))
+	var buf strings.Builder
+	assert.NoError(t, gp.Report(&buf))
+	assert.Contains(t, buf.String(), `<div class="delta up">+10.0%</div>`)
+	assert.Contains(t, buf.String(), `<span class="delta up">+10.0%</span>`)
+	assert.Contains(t, buf.String(), `new.go <span class="note">new</span>`)
+	assert.Contains(t, buf.String(), `<summary>1 removed files</summary>`)
+	assert.Contains(t, buf.String(), `<li>a/old.go</li>`)
+
+	t.Run("should escape the names of the removed files", func(t *testing.T) {
+		input := filepath.Join(t.TempDir(), "base.prof")
+		assert.NoError(t, os.WriteFile(input, []byte("mode: set\na/<img src=x onerror=alert(1)>.go:1.1,2.1 1 1\n"), 0o644))
+		base := NewGoProject(".", nil, nil)
+		assert.NoError(t, base.ParseBaseline(input))
+
+		gp := newProject(5, "a/x.go")
+		gp.Compare(base)
+		var buf strings.Builder
+		assert.NoError(t, gp.Report(&buf))
+		assert.Contains(t, buf.String(), `<li>a/&lt;img src=x onerror=alert(1)&gt;.go</li>`)
+		assert.NotContains(t, buf.String(), `<img src=x`)
+	})
 }
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"bytes"
+	"regexp"
+
+	"golang.org/x/tools/cover"
+)
+
+// ignoreCommentRe matches the magic comments excluding lines from the coverage:
+// "// covreport:ignore" ignores its own line, "// covreport:ignore-start" and "// covreport:ignore-end"
+// ignore the lines between them, their own included.
+var ignoreCommentRe = regexp.MustCompile(`//\s*covreport:ignore(-start|-end)?(\s|$)`)
+
+// IgnoredLines returns the lines of the source excluded from the coverage by magic comments.
+// A block without an end is ignored until the end of the source.
+func IgnoredLines(src []byte) map[int]bool {
+	var ignored map[int]bool
+	inBlock := false
+	for i, line := range bytes.Split(src, []byte("\n")) {
+		match := ignoreCommentRe.FindSubmatch(line)
+		if match != nil || inBlock {
+			if ignored == nil {
+				ignored = make(map[int]bool)
+			}
+			ignored[i+1] = true
+		}
+		if match != nil {
+			switch string(match[1]) {
+			case "-start":
+				inBlock = true
+			case "-end":
+				inBlock = false
+			}
+		}
+	}
+	return ignored
+}
+
+// IgnoreLines drops the profile blocks whose statements all lie on the lines of the source ignored by magic comments,
+// removing their statements from the counts of the file. The ignored blocks are traced by the debug messages of the Logger.
+func (file *GoFile) IgnoreLines(src []byte, logger *Logger) {
+	ignored := IgnoredLines(src)
+	if len(ignored) == 0 {
+		return
+	}
+
+	lines := bytes.Split(src, []byte("\n"))
+	blocks := file.Profile[:0]
+	for _, block := range file.Profile {
+		if blockIgnored(block, lines, ignored) {
+			logger.Debugf("%s:%d: ignoring a block of %d statements marked covreport:ignore", file.RelPkgPath, block.StartLine, block.NumStmt)
+			file.StmtCount -= block.NumStmt
+			if block.Count > 0 {
+				file.StmtCoveredCount -= block.NumStmt
+			}
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	file.Profile = blocks
+}
+
+// blockIgnored reports whether every line of the block bearing its statements is ignored.
+// The first line of a block is left out when the block only starts at its end, on an opening brace or
+// the colon of a case clause, and the last line when the block only ends there with a closing brace.
+func blockIgnored(block cover.ProfileBlock, lines [][]byte, ignored map[int]bool) bool {
+	start, end := block.StartLine, block.EndLine
+	if start < end && start <= len(lines) && block.StartCol-1 <= len(lines[start-1]) &&
+		len(bytes.Trim(lines[start-1][block.StartCol-1:], " \t{:")) == 0 {
+		start++
+	}
+	if start < end && end <= len(lines) && block.EndCol-1 <= len(lines[end-1]) &&
+		len(bytes.Trim(lines[end-1][:block.EndCol-1], " \t}")) == 0 {
+		end--
+	}
+	for line := start; line <= end; line++ {
+		if !ignored[line] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestIgnoredLines(t *testing.T) {
+	t.Run("should ignore lines with a trailing magic comment", func(t *testing.T) {
+		src := "a()\npanic(err) // covreport:ignore\nb() //covreport:ignore because\nc() // covreport:ignored\n"
+		assert.Equal(t, map[int]bool{2: true, 3: true}, IgnoredLines([]byte(src)))
+	})
+
+	t.Run("should ignore the lines of a block", func(t *testing.T) {
+		src := "a()\n// covreport:ignore-start\nb()\nc()\n// covreport:ignore-end\nd()\n// covreport:ignore-start\ne()"
+		assert.Equal(t, map[int]bool{2: true, 3: true, 4: true, 5: true, 7: true, 8: true}, IgnoredLines([]byte(src)))
+	})
+
+	t.Run("should return nil without magic comments", func(t *testing.T) {
+		assert.Nil(t, IgnoredLines([]byte("a()\n")))
+	})
+}
+
+func TestIgnoreLines(t *testing.T) {
+	const src = `package x
+
+func f(err error) int {
+	if err != nil {
+		panic(err) // covreport:ignore
+	}
+	switch {
+	case err == nil:
+		return 1
+	default:
+		// covreport:ignore-start
+		g()
+		return 2
+		// covreport:ignore-end
+	}
+}
+`
+	blocks := []cover.ProfileBlock{
+		{StartLine: 3, StartCol: 24, EndLine: 4, EndCol: 16, NumStmt: 1, Count: 1},
+		{StartLine: 4, StartCol: 16, EndLine: 6, EndCol: 3, NumStmt: 1, Count: 0},
+		{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, NumStmt: 1, Count: 1},
+		{StartLine: 8, StartCol: 17, EndLine: 9, EndCol: 11, NumStmt: 1, Count: 1},
+		{StartLine: 10, StartCol: 10, EndLine: 13, EndCol: 11, NumStmt: 2, Count: 0},
+	}
+
+	t.Run("should drop the blocks lying on ignored lines", func(t *testing.T) {
+		file := &GoFile{GoListItem: &GoListItem{StmtCount: 6, StmtCoveredCount: 3}, Profile: append([]cover.ProfileBlock(nil), blocks...)}
+		file.IgnoreLines([]byte(src), nil)
+		assert.Equal(t, []cover.ProfileBlock{blocks[0], blocks[2], blocks[3]}, file.Profile)
+		assert.Equal(t, 3, file.StmtCount)
+		assert.Equal(t, 3, file.StmtCoveredCount)
+	})
+
+	t.Run("should exclude ignored statements when parsing", func(t *testing.T) {
+		temp := t.TempDir()
+		absPath := filepath.Join(temp, "x.go")
+		assert.NoError(t, os.WriteFile(absPath, []byte(src), 0o644))
+
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		file := &GoFile{ABSPath: absPath, GoListItem: NewGoListItem("app/x.go"), Profile: append([]cover.ProfileBlock(nil), blocks...)}
+		file.StmtCount, file.StmtCoveredCount = 6, 3
+		gp.SafeDir("app").AddFile(file)
+		file.ReadSource(nil, nil)
+		gp.Root().Aggregate()
+
+		assert.Equal(t, 100.0, gp.Root().Percent())
+		assert.Len(t, file.Funcs, 1)
+		assert.Equal(t, 3, file.Funcs[0].StmtCount)
+	})
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+
+	"golang.org/x/tools/cover"
+)
+
+// JSONSchemaVersion is the version of the JSON report shape.
+// It is bumped whenever a field is removed, renamed or changes meaning.
+const JSONSchemaVersion = 1
+
+// JSONReport is the top-level object of the JSON report.
+type JSONReport struct {
+	SchemaVersion int      `json:"schemaVersion"`
+	Root          *JSONDir `json:"root"`
+}
+
+// JSONDir is a directory of the coverage tree with its subdirectories and files.
+type JSONDir struct {
+	Path              string      `json:"path"`
+	Statements        int         `json:"statements"`
+	CoveredStatements int         `json:"coveredStatements"`
+	Percent           float64     `json:"percent"`
+	Dirs              []*JSONDir  `json:"dirs"`
+	Files             []*JSONFile `json:"files"`
+}
+
+// JSONFile is a source file of the coverage tree with its profile blocks.
+// Unscored files are left out of the totals of their directories.
+type JSONFile struct {
+	Path              string       `json:"path"`
+	Note              string       `json:"note,omitempty"`
+	Unscored          bool         `json:"unscored,omitempty"`
+	Statements        int          `json:"statements"`
+	CoveredStatements int          `json:"coveredStatements"`
+	Percent           float64      `json:"percent"`
+	Blocks            []*JSONBlock `json:"blocks"`
+	Funcs             []*JSONFunc  `json:"funcs"`
+}
+
+// JSONFunc is a function or method of a source file.
+type JSONFunc struct {
+	Name              string  `json:"name"`
+	StartLine         int     `json:"startLine"`
+	EndLine           int     `json:"endLine"`
+	Statements        int     `json:"statements"`
+	CoveredStatements int     `json:"coveredStatements"`
+	Percent           float64 `json:"percent"`
+}
+
+// JSONBlock is a single block of a coverage profile.
+type JSONBlock struct {
+	StartLine  int `json:"startLine"`
+	StartCol   int `json:"startCol"`
+	EndLine    int `json:"endLine"`
+	EndCol     int `json:"endCol"`
+	Statements int `json:"statements"`
+	Count      int `json:"count"`
+}
+
+// JSONProfile is the coverage profile of a single file, as read from the input.
+type JSONProfile struct {
+	FileName string       `json:"fileName"`
+	Mode     string       `json:"mode"`
+	Blocks   []*JSONBlock `json:"blocks"`
+}
+
+// NewJSONProfiles returns the JSON representation of the coverage profiles.
+func NewJSONProfiles(profiles []*cover.Profile) []*JSONProfile {
+	result := make([]*JSONProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		result = append(result, &JSONProfile{
+			FileName: profile.FileName,
+			Mode:     profile.Mode,
+			Blocks:   newJSONBlocks(profile.Blocks),
+		})
+	}
+	return result
+}
+
+// newJSONBlocks returns the JSON representation of the profile blocks.
+func newJSONBlocks(blocks []cover.ProfileBlock) []*JSONBlock {
+	result := make([]*JSONBlock, 0, len(blocks))
+	for _, block := range blocks {
+		result = append(result, &JSONBlock{
+			StartLine:  block.StartLine,
+			StartCol:   block.StartCol,
+			EndLine:    block.EndLine,
+			EndCol:     block.EndCol,
+			Statements: block.NumStmt,
+			Count:      block.Count,
+		})
+	}
+	return result
+}
+
+// ReportJSON writes the coverage tree of the GoProject as JSON to the provided io.Writer.
+func (gp *GoProject) ReportJSON(wr io.Writer) error {
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Root:          NewJSONDir(gp.Root()),
+	})
+}
+
+// NewJSONDir returns the JSON representation of the directory and everything under it.
+func NewJSONDir(dir *GoDir) *JSONDir {
+	result := &JSONDir{
+		Path:              dir.RelPkgPath,
+		Statements:        dir.StmtCount,
+		CoveredStatements: dir.StmtCoveredCount,
+		Percent:           dir.Percent(),
+		Dirs:              make([]*JSONDir, 0, len(dir.SubDirs)),
+		Files:             make([]*JSONFile, 0, len(dir.Files)),
+	}
+	for _, subDir := range dir.SubDirs {
+		result.Dirs = append(result.Dirs, NewJSONDir(subDir))
+	}
+	for _, file := range dir.Files {
+		result.Files = append(result.Files, NewJSONFile(file))
+	}
+	return result
+}
+
+// NewJSONFile returns the JSON representation of the file.
+func NewJSONFile(file *GoFile) *JSONFile {
+	result := &JSONFile{
+		Path:              file.RelPkgPath,
+		Note:              file.Note,
+		Unscored:          file.Unscored,
+		Statements:        file.StmtCount,
+		CoveredStatements: file.StmtCoveredCount,
+		Percent:           file.Percent(),
+		Blocks:            newJSONBlocks(file.Profile),
+		Funcs:             make([]*JSONFunc, 0, len(file.Funcs)),
+	}
+	for _, f := range file.Funcs {
+		result.Funcs = append(result.Funcs, &JSONFunc{
+			Name:              f.Title,
+			StartLine:         f.StartLine,
+			EndLine:           f.EndLine,
+			Statements:        f.StmtCount,
+			CoveredStatements: f.StmtCoveredCount,
+			Percent:           f.Percent(),
+		})
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestReportJSON(t *testing.T) {
+	t.Run("should marshal the whole tree", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		a := gp.SafeDir("a")
+		a.AddFile(&GoFile{
+			GoListItem: NewGoListItem("a/x.go"),
+			Profile: []cover.ProfileBlock{
+				{StartLine: 1, StartCol: 2, EndLine: 3, EndCol: 4, NumStmt: 2, Count: 1},
+				{StartLine: 5, StartCol: 6, EndLine: 7, EndCol: 8, NumStmt: 2, Count: 0},
+			},
+		})
+		a.Files[0].StmtCount = 4
+		a.Files[0].StmtCoveredCount = 2
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		err := gp.ReportJSON(&buf)
+		assert.NoError(t, err)
+
+		var report JSONReport
+		err = json.Unmarshal([]byte(buf.String()), &report)
+		assert.NoError(t, err)
+
+		assert.Equal(t, JSONSchemaVersion, report.SchemaVersion)
+		assert.Equal(t, ".", report.Root.Path)
+		assert.Equal(t, 4, report.Root.Statements)
+		assert.Len(t, report.Root.Dirs, 1)
+
+		dir := report.Root.Dirs[0]
+		assert.Equal(t, "a", dir.Path)
+		assert.Equal(t, 50.0, dir.Percent)
+		assert.Len(t, dir.Files, 1)
+
+		file := dir.Files[0]
+		assert.Equal(t, "a/x.go", file.Path)
+		assert.Equal(t, 2, file.CoveredStatements)
+		assert.Equal(t, []*JSONBlock{
+			{StartLine: 1, StartCol: 2, EndLine: 3, EndCol: 4, Statements: 2, Count: 1},
+			{StartLine: 5, StartCol: 6, EndLine: 7, EndCol: 8, Statements: 2, Count: 0},
+		}, file.Blocks)
+		assert.False(t, file.Unscored)
+		assert.NotContains(t, buf.String(), `"unscored"`)
+	})
+
+	t.Run("should flag the unscored files", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/x.go", StmtCount: 4, StmtCoveredCount: 3}})
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/x.pb.go", StmtCount: 6, Unscored: true}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportJSON(&buf))
+		var report JSONReport
+		assert.NoError(t, json.Unmarshal([]byte(buf.String()), &report))
+
+		dir := report.Root.Dirs[0]
+		assert.Equal(t, 4, dir.Statements)
+		if assert.Len(t, dir.Files, 2) {
+			assert.False(t, dir.Files[0].Unscored)
+			assert.True(t, dir.Files[1].Unscored)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"path"
+	"strings"
+)
+
+// LanguageGo is the language of the Go files.
+const LanguageGo = "Go"
+
+// languages maps the extensions of the source files the go tool builds to their language.
+// Profiles of cgo packages may reference the C, C++ and assembly files of the package.
+var languages = map[string]string{
+	".go":   LanguageGo,
+	".c":    "C",
+	".h":    "C",
+	".cc":   "C++",
+	".cpp":  "C++",
+	".cxx":  "C++",
+	".hh":   "C++",
+	".hpp":  "C++",
+	".hxx":  "C++",
+	".m":    "Objective-C",
+	".f":    "Fortran",
+	".for":  "Fortran",
+	".f90":  "Fortran",
+	".s":    "Assembly",
+	".sx":   "Assembly",
+	".swig": "SWIG",
+}
+
+// FileLanguage returns the language of the file name, by extension, case insensitively.
+// Files of unknown extensions have an empty language.
+func FileLanguage(name string) string {
+	return languages[strings.ToLower(path.Ext(name))]
+}
@@ -0,0 +1,23 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLanguage(t *testing.T) {
+	for name, language := range map[string]string{
+		"a/x.go":        LanguageGo,
+		"a/add.c":       "C",
+		"a/y_amd64.s":   "Assembly",
+		"a/y_arm64.S":   "Assembly",
+		"a/lib.cpp":     "C++",
+		"a/Makefile":    "",
+		"a/notes.txt":   "",
+		"a.go/x.py":     "",
+		`a\b\windows.c`: "C",
+	} {
+		assert.Equal(t, language, FileLanguage(name), name)
+	}
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+// TemplateLegendData represents the legend of the initial view of the report, explaining its colors:
+// the bands of the cutlines, and the backgrounds and hit counts of the lines of code.
+type TemplateLegendData struct {
+	Bands []*TemplateLegendBand
+}
+
+// TemplateLegendBand represents a band of the cutlines in the legend of the report, with its range of coverage.
+type TemplateLegendBand struct {
+	ClassName string
+	Label     string
+}
+
+// newLegendBands returns the bands of the cutlines of the template data with their ranges, as classified by
+// config.Cutlines.Classify, from the least to the most covered. Empty bands, such as the danger band of a 0% warning
+// cutline, are left out. Nil cutlines classify nothing, so they have no band.
+func (td *TemplateData) newLegendBands() []*TemplateLegendBand {
+	cutlines := td.Cutlines
+	if cutlines == nil {
+		return nil
+	}
+	var bands []*TemplateLegendBand
+	add := func(className string, from, to float64) {
+		if from >= to || from > 100 {
+			return
+		}
+		label := formatCutline(from) + " to " + formatCutline(to)
+		if to > 100 {
+			label = formatCutline(from) + " and above"
+		} else if from <= 0 {
+			label = "below " + formatCutline(to)
+		}
+		bands = append(bands, &TemplateLegendBand{ClassName: className, Label: label})
+	}
+	// Classify tests the warning cutline first, so the safe band starts above both the safe and warning cutlines.
+	safe := math.Max(cutlines.Warning, cutlines.Safe)
+	add(config.ClassDanger, 0, cutlines.Warning)
+	add(config.ClassWarning, cutlines.Warning, cutlines.Safe)
+	if cutlines.Excellent > 0 {
+		add(config.ClassSafe, safe, cutlines.Excellent)
+		add(config.ClassExcellent, math.Max(safe, cutlines.Excellent), math.Inf(1))
+	} else {
+		add(config.ClassSafe, safe, math.Inf(1))
+	}
+	return bands
+}
+
+// formatCutline formats a cutline as a percentage, without trailing zeros.
+func formatCutline(cutline float64) string {
+	return strconv.FormatFloat(cutline, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLegendBands(t *testing.T) {
+	labels := func(cutlines *config.Cutlines) []string {
+		var result []string
+		for _, band := range (&TemplateData{Cutlines: cutlines}).newLegendBands() {
+			result = append(result, band.ClassName+": "+band.Label)
+		}
+		return result
+	}
+
+	t.Run("should give the range of every band of the cutlines", func(t *testing.T) {
+		assert.Equal(t, []string{"danger: below 40%", "warning: 40% to 70%", "safe: 70% and above"},
+			labels(&config.Cutlines{Safe: 70, Warning: 40}))
+		assert.Equal(t, []string{"danger: below 40%", "warning: 40% to 70%", "safe: 70% to 92.5%", "excellent: 92.5% and above"},
+			labels(&config.Cutlines{Safe: 70, Warning: 40, Excellent: 92.5}))
+	})
+
+	t.Run("should leave out the empty bands", func(t *testing.T) {
+		assert.Equal(t, []string{"danger: below 50%", "safe: 50% and above"}, labels(&config.Cutlines{Safe: 50, Warning: 50}))
+		assert.Equal(t, []string{"warning: below 80%", "excellent: 80% and above"}, labels(&config.Cutlines{Safe: 80, Excellent: 60}))
+		assert.Equal(t, []string{"safe: 0% and above"}, labels(&config.Cutlines{}))
+	})
+
+	t.Run("should have no band without cutlines", func(t *testing.T) {
+		assert.Empty(t, labels(nil))
+	})
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"io"
+	"log"
+)
+
+// LogLevel controls the volume of the diagnostics printed by a Logger.
+type LogLevel int
+
+// Levels of the diagnostics, from the quietest.
+const (
+	// LogQuiet prints no diagnostics, errors being reported by the caller.
+	LogQuiet LogLevel = iota
+	// LogNormal prints warnings, such as skipped profile lines, which may make the report incomplete.
+	LogNormal
+	// LogVerbose also prints debug messages tracing the files read, the ignored blocks and the profile blocks,
+	// to diagnose path resolution issues.
+	LogVerbose
+)
+
+// Logger prints the diagnostics of a GoProject up to its level.
+// A nil Logger prints nothing, so callers don't check whether diagnostics are enabled.
+// It is safe for concurrent use, and each GoProject has its own, so concurrent reports don't mix their diagnostics.
+type Logger struct {
+	level  LogLevel
+	logger *log.Logger
+}
+
+// NewLogger returns a Logger printing the diagnostics up to the given level to the provided io.Writer, usually stderr.
+func NewLogger(wr io.Writer, level LogLevel) *Logger {
+	return &Logger{level: level, logger: log.New(wr, "", log.LstdFlags)}
+}
+
+// Warnf prints a warning, unless quiet.
+func (l *Logger) Warnf(format string, args ...any) {
+	if l != nil && l.level >= LogNormal {
+		l.logger.Printf("warning: "+format, args...)
+	}
+}
+
+// Debugf prints a debug message when verbose.
+func (l *Logger) Debugf(format string, args ...any) {
+	if l != nil && l.level >= LogVerbose {
+		l.logger.Printf("debug: "+format, args...)
+	}
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevel(t *testing.T) {
+	logAt := func(level LogLevel) string {
+		var logs strings.Builder
+		logger := NewLogger(&logs, level)
+		logger.Warnf("skipped %d lines", 2)
+		logger.Debugf("read %s", "x.go")
+		return logs.String()
+	}
+
+	t.Run("should print nothing when quiet", func(t *testing.T) {
+		assert.Empty(t, logAt(LogQuiet))
+	})
+
+	t.Run("should print warnings by default", func(t *testing.T) {
+		logs := logAt(LogNormal)
+		assert.Contains(t, logs, "warning: skipped 2 lines")
+		assert.NotContains(t, logs, "debug:")
+	})
+
+	t.Run("should print debug messages when verbose", func(t *testing.T) {
+		logs := logAt(LogVerbose)
+		assert.Contains(t, logs, "warning: skipped 2 lines")
+		assert.Contains(t, logs, "debug: read x.go")
+	})
+
+	t.Run("should print nothing without logger", func(t *testing.T) {
+		var logger *Logger
+		assert.NotPanics(t, func() {
+			logger.Warnf("skipped %d lines", 2)
+			logger.Debugf("read %s", "x.go")
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+// markdownMarks maps the class names of the cutlines to the marks shown in the Markdown table.
+var markdownMarks = map[string]string{
+	config.ClassExcellent: "🔵",
+	config.ClassSafe:      "🟢",
+	config.ClassWarning:   "🟡",
+	config.ClassDanger:    "🔴",
+	"":                    "⚪",
+}
+
+// ReportMarkdown writes a compact Markdown summary of the GoProject to the provided io.Writer.
+// It lists the items of the initial directory with their coverage, below the overall coverage.
+// Items are ordered as in directory views.
+func (gp *GoProject) ReportMarkdown(wr io.Writer) error {
+	root := gp.Root()
+	initialDir := gp.InitialDir()
+
+	var sb strings.Builder
+	title := FormatPercent(root.Percent(), root.StmtCount, gp.Precision)
+	if delta, _ := FormatDelta(root.GoListItem, gp.Precision); delta != "" {
+		title += " (" + delta + ")"
+	}
+	fmt.Fprintf(&sb, "### Coverage: %s\n\n", title)
+	fmt.Fprintf(&sb, "%d of %d statements covered.\n\n", root.StmtCoveredCount, root.StmtCount)
+	if gp.Hidden > 0 {
+		fmt.Fprintf(&sb, "%d fully covered files hidden.\n\n", gp.Hidden)
+	}
+
+	items := make([]*TemplateListItemData, 0, len(initialDir.SubDirs)+len(initialDir.Files))
+	for _, subDir := range initialDir.SubDirs {
+		items = append(items, NewTemplateListItemData(subDir.GoListItem, gp.Cutlines, gp.Precision))
+	}
+	for _, file := range initialDir.Files {
+		items = append(items, NewTemplateListItemData(file.GoListItem, gp.Cutlines, gp.Precision))
+	}
+	SortListItems(items, gp.Sort)
+
+	if len(items) > 0 {
+		if gp.Compared {
+			sb.WriteString("| | Package | Coverage | Delta | Statements |\n")
+			sb.WriteString("|:-:|:--|--:|--:|--:|\n")
+		} else {
+			sb.WriteString("| | Package | Coverage | Statements |\n")
+			sb.WriteString("|:-:|:--|--:|--:|\n")
+		}
+		for _, item := range items {
+			cells := []string{
+				markdownMarks[item.ClassName],
+				escapeMarkdown(strings.TrimPrefix(item.item.RelPkgPath, initialDir.RelPkgPath+"/")),
+				item.Percent,
+			}
+			if gp.Compared {
+				delta := item.Delta
+				if item.New {
+					delta = "new"
+				}
+				cells = append(cells, delta)
+			}
+			cells = append(cells, fmt.Sprintf("%d/%d", item.NumStmtCovered, item.NumStmt))
+			fmt.Fprintf(&sb, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	if len(gp.Removed) > 0 {
+		sb.WriteString("\nRemoved files:\n")
+		for _, removed := range gp.Removed {
+			fmt.Fprintf(&sb, "- %s\n", escapeMarkdown(removed))
+		}
+	}
+
+	_, err := io.WriteString(wr, sb.String())
+	return err
+}
+
+// escapeMarkdown escapes the characters breaking a Markdown table cell.
+func escapeMarkdown(text string) string {
+	return strings.NewReplacer("|", "\\|", "*", "\\*", "_", "\\_", "`", "\\`").Replace(text)
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportMarkdown(t *testing.T) {
+	t.Run("should list items of the initial directory", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.SafeDir("app/crypto").AddFile(&GoFile{GoListItem: &GoListItem{StmtCount: 10, StmtCoveredCount: 3}})
+		gp.SafeDir("app/api").AddFile(&GoFile{GoListItem: &GoListItem{StmtCount: 10, StmtCoveredCount: 9}})
+		gp.SafeDir("app/my_pkg").AddFile(&GoFile{GoListItem: &GoListItem{StmtCount: 10, StmtCoveredCount: 5}})
+		gp.SafeDir("app").AddFile(&GoFile{GoListItem: NewGoListItem("app/doc.go")})
+		gp.Root().Aggregate()
+		gp.Sort = config.SortWorst
+
+		var buf strings.Builder
+		err := gp.ReportMarkdown(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Join([]string{
+			"### Coverage: 56.7%",
+			"",
+			"17 of 30 statements covered.",
+			"",
+			"| | Package | Coverage | Statements |",
+			"|:-:|:--|--:|--:|",
+			"| 🔴 | crypto | 30.0% | 3/10 |",
+			"| 🟡 | my\\_pkg | 50.0% | 5/10 |",
+			"| 🟢 | api | 90.0% | 9/10 |",
+			"| ⚪ | doc.go | N/A | 0/0 |",
+			"",
+		}, "\n"), buf.String())
+	})
+
+	t.Run("should note the hidden files", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Hidden = 4
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportMarkdown(&buf))
+		assert.Contains(t, buf.String(), "0 of 0 statements covered.\n\n4 fully covered files hidden.\n")
+	})
+
+	t.Run("should show deltas when compared", func(t *testing.T) {
+		newProject := func(files map[string][2]int) *GoProject {
+			gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+			for path, counts := range files {
+				gp.SafeDir(filepath.Dir(path)).AddFile(&GoFile{GoListItem: NewGoListItem(path)})
+				file := gp.SafeDir(filepath.Dir(path)).Files[0]
+				file.StmtCoveredCount, file.StmtCount = counts[0], counts[1]
+			}
+			gp.Root().Aggregate()
+			gp.Sort = config.SortName
+			return gp
+		}
+		gp := newProject(map[string][2]int{"app/api/x.go": {9, 10}, "app/crypto/x.go": {3, 10}})
+		base := newProject(map[string][2]int{"app/api/x.go": {8, 10}, "app/gone/x.go": {0, 10}})
+		gp.Compare(base)
+
+		var buf strings.Builder
+		err := gp.ReportMarkdown(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Join([]string{
+			"### Coverage: 60.0% (+20.0%)",
+			"",
+			"12 of 20 statements covered.",
+			"",
+			"| | Package | Coverage | Delta | Statements |",
+			"|:-:|:--|--:|--:|--:|",
+			"| 🟢 | api | 90.0% | +10.0% | 9/10 |",
+			"| 🔴 | crypto | 30.0% | new | 3/10 |",
+			"",
+			"Removed files:",
+			"- app/gone/x.go",
+			"",
+		}, "\n"), buf.String())
+	})
+}
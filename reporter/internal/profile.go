@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/cover"
+)
+
+// modePrefix starts the header line of a coverage profile.
+const modePrefix = "mode: "
+
+// blockLineRe matches a block line of a coverage profile: name.go:line.column,line.column numberOfStatements count.
+var blockLineRe = regexp.MustCompile(`^.+:[0-9]+\.[0-9]+,[0-9]+\.[0-9]+ [0-9]+ [0-9]+$`)
+
+// StdinInput is the input name reading from the standard input.
+const StdinInput = "-"
+
+// InputSeparator separates the names of several inputs read one after the other,
+// such as the profiles of the modules of a workspace.
+const InputSeparator = ","
+
+// gzipMagic starts gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ParseProfiles parses the coverage profile of the named file.
+// In lenient mode, malformed lines are skipped with a warning of the Logger and the number of skipped lines is returned.
+func ParseProfiles(input string, lenient bool, logger *Logger) ([]*cover.Profile, int, error) {
+	rd, err := OpenInput(input)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rd.Close()
+	return ParseProfilesFromReader(rd, lenient, logger)
+}
+
+// OpenInput opens the named input file, or the standard input when the name is StdinInput.
+// Gzip compressed inputs, told by a .gz extension or their magic bytes, are decompressed transparently.
+// Several inputs separated by InputSeparator are read one after the other, each ending a line.
+func OpenInput(input string) (io.ReadCloser, error) {
+	names := strings.Split(input, InputSeparator)
+	if len(names) == 1 {
+		return openInput(input)
+	}
+
+	inputs := make(multiInputReader, 0, len(names))
+	readers := make([]io.Reader, 0, 2*len(names))
+	for _, name := range names {
+		rd, err := openInput(name)
+		if err != nil {
+			inputs.Close()
+			return nil, err
+		}
+		inputs = append(inputs, rd)
+		readers = append(readers, rd, strings.NewReader("\n"))
+	}
+	return &inputReader{Reader: io.MultiReader(readers...), file: inputs}, nil
+}
+
+// openInput opens a single input, see OpenInput.
+func openInput(input string) (io.ReadCloser, error) {
+	var file io.ReadCloser = os.Stdin
+	if input != StdinInput {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+	}
+
+	rd := bufio.NewReader(file)
+	magic, _ := rd.Peek(len(gzipMagic))
+	if !strings.HasSuffix(input, ".gz") && !bytes.Equal(magic, gzipMagic) {
+		return &inputReader{Reader: rd, file: file}, nil
+	}
+	gz, err := gzip.NewReader(rd)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("can't decompress %q: %v", input, err)
+	}
+	return &inputReader{Reader: gz, file: file}, nil
+}
+
+// inputReader reads an input, closing the underlying file but the standard input.
+type inputReader struct {
+	io.Reader
+	file io.Closer
+}
+
+// Close closes the input file, leaving the standard input open.
+func (rd *inputReader) Close() error {
+	if rd.file == os.Stdin {
+		return nil
+	}
+	return rd.file.Close()
+}
+
+// multiInputReader closes the inputs read one after the other.
+type multiInputReader []io.ReadCloser
+
+// Close closes every input, returning the first error.
+func (inputs multiInputReader) Close() error {
+	var err error
+	for _, input := range inputs {
+		if closeErr := input.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// ParseProfilesFromReader parses a coverage profile from the reader.
+// Profiles concatenated from several files repeat the mode line: the repeated lines are skipped
+// as long as they agree with the first one. Blank lines are skipped as well.
+// In lenient mode, malformed block lines, such as the last line of a truncated profile,
+// are skipped with a warning of the Logger, and the number of skipped lines is returned.
+func ParseProfilesFromReader(rd io.Reader, lenient bool, logger *Logger) ([]*cover.Profile, int, error) {
+	var buf bytes.Buffer
+	var mode string
+	var skipped int
+
+	scanner := bufio.NewScanner(rd)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, modePrefix) {
+			m := strings.TrimPrefix(line, modePrefix)
+			if mode != "" {
+				if m != mode {
+					return nil, 0, fmt.Errorf("conflicting coverage modes %q and %q", mode, m)
+				}
+				continue
+			}
+			mode = m
+		} else if lenient && !blockLineRe.MatchString(line) {
+			logger.Warnf("skipping malformed profile line %d: %q", lineNum, line)
+			skipped++
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	profiles, err := cover.ParseProfilesFromReader(&buf)
+	return profiles, skipped, err
+}
@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfilesFromReader(t *testing.T) {
+	t.Run("should skip repeated mode lines", func(t *testing.T) {
+		input := "mode: set\na/x.go:1.1,2.1 2 1\n\nmode: set\na/x.go:3.1,4.1 3 0\na/y.go:1.1,2.1 1 1\n"
+		profiles, _, err := ParseProfilesFromReader(strings.NewReader(input), false, nil)
+		assert.NoError(t, err)
+		assert.Len(t, profiles, 2)
+		assert.Equal(t, "a/x.go", profiles[0].FileName)
+		assert.Equal(t, "set", profiles[0].Mode)
+		assert.Len(t, profiles[0].Blocks, 2)
+		assert.Equal(t, "a/y.go", profiles[1].FileName)
+		assert.Len(t, profiles[1].Blocks, 1)
+	})
+
+	t.Run("should return error when modes conflict", func(t *testing.T) {
+		input := "mode: set\na/x.go:1.1,2.1 2 1\nmode: count\na/x.go:3.1,4.1 3 0\n"
+		_, _, err := ParseProfilesFromReader(strings.NewReader(input), false, nil)
+		assert.EqualError(t, err, `conflicting coverage modes "set" and "count"`)
+	})
+
+	t.Run("should return error without mode line", func(t *testing.T) {
+		_, _, err := ParseProfilesFromReader(strings.NewReader("a/x.go:1.1,2.1 2 1\n"), false, nil)
+		assert.ErrorContains(t, err, "bad mode line")
+	})
+
+	t.Run("should return error on truncated line", func(t *testing.T) {
+		input := "mode: set\na/x.go:1.1,2.1 2 1\na/y.go:1.1,2"
+		_, _, err := ParseProfilesFromReader(strings.NewReader(input), false, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("should skip truncated line in lenient mode", func(t *testing.T) {
+		var logs strings.Builder
+		input := "mode: set\na/x.go:1.1,2.1 2 1\na/y.go:1.1,2"
+		profiles, skipped, err := ParseProfilesFromReader(strings.NewReader(input), true, NewLogger(&logs, LogNormal))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, skipped)
+		assert.Len(t, profiles, 1)
+		assert.Equal(t, "a/x.go", profiles[0].FileName)
+		assert.Contains(t, logs.String(), `warning: skipping malformed profile line 3: "a/y.go:1.1,2"`)
+	})
+}
+
+func TestParseProfiles(t *testing.T) {
+	const content = "mode: set\na/x.go:1.1,2.1 2 1\n"
+	gzipped := func() []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, gz.Close())
+		return buf.Bytes()
+	}
+	temp := t.TempDir()
+
+	t.Run("should decompress a .gz input", func(t *testing.T) {
+		input := filepath.Join(temp, "cover.prof.gz")
+		assert.NoError(t, os.WriteFile(input, gzipped(), 0o644))
+		profiles, _, err := ParseProfiles(input, false, nil)
+		assert.NoError(t, err)
+		assert.Len(t, profiles, 1)
+		assert.Equal(t, "a/x.go", profiles[0].FileName)
+	})
+
+	t.Run("should decompress a gzip input without extension", func(t *testing.T) {
+		input := filepath.Join(temp, "archived.prof")
+		assert.NoError(t, os.WriteFile(input, gzipped(), 0o644))
+		profiles, _, err := ParseProfiles(input, false, nil)
+		assert.NoError(t, err)
+		assert.Len(t, profiles, 1)
+	})
+
+	t.Run("should return error with a corrupted .gz input", func(t *testing.T) {
+		input := filepath.Join(temp, "corrupted.prof.gz")
+		assert.NoError(t, os.WriteFile(input, []byte(content), 0o644))
+		_, _, err := ParseProfiles(input, false, nil)
+		assert.ErrorContains(t, err, `can't decompress "`+input+`"`)
+	})
+
+	t.Run("should read the standard input", func(t *testing.T) {
+		stdin := filepath.Join(temp, "stdin")
+		assert.NoError(t, os.WriteFile(stdin, gzipped(), 0o644))
+		file, err := os.Open(stdin)
+		assert.NoError(t, err)
+		defer file.Close()
+		defer func(stdin *os.File) { os.Stdin = stdin }(os.Stdin)
+		os.Stdin = file
+
+		profiles, _, err := ParseProfiles(StdinInput, false, nil)
+		assert.NoError(t, err)
+		assert.Len(t, profiles, 1)
+	})
+	t.Run("should read comma-separated inputs one after the other", func(t *testing.T) {
+		first := filepath.Join(temp, "first.prof")
+		assert.NoError(t, os.WriteFile(first, []byte("mode: set\na/x.go:1.1,2.1 2 1"), 0o644))
+		second := filepath.Join(temp, "second.prof.gz")
+		assert.NoError(t, os.WriteFile(second, gzipped(), 0o644))
+		third := filepath.Join(temp, "third.prof")
+		assert.NoError(t, os.WriteFile(third, []byte("mode: set\nb/y.go:1.1,2.1 1 0\n"), 0o644))
+
+		profiles, _, err := ParseProfiles(first+InputSeparator+second+InputSeparator+third, false, nil)
+		assert.NoError(t, err)
+		assert.Len(t, profiles, 2)
+		assert.Equal(t, "a/x.go", profiles[0].FileName)
+		assert.Len(t, profiles[0].Blocks, 1)
+		assert.Equal(t, "b/y.go", profiles[1].FileName)
+	})
+
+	t.Run("should return error when one of several inputs is missing", func(t *testing.T) {
+		_, _, err := ParseProfiles(filepath.Join(temp, "first.prof")+InputSeparator+filepath.Join(temp, "missing.prof"), false, nil)
+		assert.Error(t, err)
+	})
+}
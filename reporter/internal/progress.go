@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Progress prints the progress of the steps of a long job, such as parsing a large profile,
+// as a percentage and a counter rewritten in place on a single line.
+// A nil Progress prints nothing, so callers don't check whether progress is enabled.
+type Progress struct {
+	wr io.Writer
+
+	mu      sync.Mutex
+	label   string
+	done    int
+	total   int
+	percent int
+}
+
+// NewProgress returns a Progress printing to the provided io.Writer, usually stderr.
+func NewProgress(wr io.Writer) *Progress {
+	return &Progress{wr: wr}
+}
+
+// Start starts a step of the given number of units of work, printing its label at 0%.
+func (p *Progress) Start(label string, total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.label, p.done, p.total, p.percent = label, 0, total, 0
+	p.print()
+}
+
+// Begin starts a step whose amount of work isn't known beforehand, such as reading the profile,
+// printing its label only.
+func (p *Progress) Begin(label string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.label, p.done, p.total, p.percent = label, 0, 0, 0
+	fmt.Fprintf(p.wr, "\r%s...", p.label)
+}
+
+// Step marks a unit of work of the current step as done. It is safe for concurrent use.
+// The line is only rewritten when the percentage changes, so steps of many small units don't flood the output.
+func (p *Progress) Step() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if percent := p.done * 100 / max(p.total, 1); percent != p.percent || p.done == p.total {
+		p.percent = percent
+		p.print()
+	}
+}
+
+// Finish ends the current step, moving to the next line.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	fmt.Fprintln(p.wr)
+}
+
+// print rewrites the line of the current step.
+func (p *Progress) print() {
+	fmt.Fprintf(p.wr, "\r%s: %d%% (%d/%d)", p.label, p.percent, p.done, p.total)
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	t.Run("should print the percentage when it changes", func(t *testing.T) {
+		var buf strings.Builder
+		p := NewProgress(&buf)
+		p.Start("parsing", 3)
+		p.Step()
+		p.Step()
+		p.Step()
+		p.Finish()
+		assert.Equal(t, "\rparsing: 0% (0/3)\rparsing: 33% (1/3)\rparsing: 66% (2/3)\rparsing: 100% (3/3)\n", buf.String())
+	})
+
+	t.Run("should not print the same percentage twice", func(t *testing.T) {
+		var buf strings.Builder
+		p := NewProgress(&buf)
+		p.Start("rendering", 1000)
+		for i := 0; i < 1000; i++ {
+			p.Step()
+		}
+		assert.Equal(t, 101, strings.Count(buf.String(), "\r"))
+	})
+
+	t.Run("should print the label of a step of unknown size", func(t *testing.T) {
+		var buf strings.Builder
+		p := NewProgress(&buf)
+		p.Begin("reading")
+		p.Finish()
+		assert.Equal(t, "\rreading...\n", buf.String())
+	})
+
+	t.Run("should print nothing when nil", func(t *testing.T) {
+		var p *Progress
+		p.Begin("reading")
+		p.Start("parsing", 1)
+		p.Step()
+		p.Finish()
+	})
+
+	t.Run("should report the progress of parsing and rendering", func(t *testing.T) {
+		var buf strings.Builder
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Progress = NewProgress(&buf)
+		input := filepath.Join(t.TempDir(), "cover.prof")
+		assert.NoError(t, os.WriteFile(input, []byte("mode: set\ngithub.com/drappier-charles/covreport/reporter/internal/progress.go:1.1,2.1 1 1\n"), 0o644))
+		assert.NoError(t, gp.Parse(input))
+		assert.NoError(t, gp.Report(&strings.Builder{}))
+		assert.True(t, strings.HasPrefix(buf.String(), "\rreading profiles...\n\rlocating packages...\n"), buf.String())
+		assert.Contains(t, buf.String(), "parsing profiles: 100% (1/1)\n")
+		assert.Contains(t, buf.String(), "reading sources: 100% (1/1)\n")
+		assert.Contains(t, buf.String(), "rendering files: 100% (1/1)\n")
+	})
+}
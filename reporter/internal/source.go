@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// SourceCache reads the source files of a GoProject at most once, keeping them in memory
+// for the formats and the views rendering them again. It is safe for concurrent use.
+// A nil or zero SourceCache reads the files every time they are needed, which bounds the memory on large projects.
+type SourceCache struct {
+	mu      sync.Mutex
+	sources map[string]*cachedSource
+	// Retries is the number of times a failed read is tried again, for network filesystems returning transient errors.
+	Retries int
+	// Logger traces the retried reads, nil printing nothing.
+	Logger *Logger
+}
+
+// Bounds of the exponential backoff between the attempts to read a source file.
+const (
+	minReadRetryDelay = 50 * time.Millisecond
+	maxReadRetryDelay = 2 * time.Second
+)
+
+// cachedSource is the content of a source file, read once.
+type cachedSource struct {
+	once sync.Once
+	src  []byte
+	err  error
+}
+
+// NewSourceCache returns an empty SourceCache.
+func NewSourceCache() *SourceCache {
+	return &SourceCache{sources: make(map[string]*cachedSource)}
+}
+
+// ReadFile returns the content of the named file, reading it on the first call only.
+// Failed reads are cached as well, returning the same error.
+func (c *SourceCache) ReadFile(name string) ([]byte, error) {
+	if c == nil || c.sources == nil {
+		return c.readFile(name)
+	}
+
+	c.mu.Lock()
+	source, ok := c.sources[name]
+	if !ok {
+		source = &cachedSource{}
+		c.sources[name] = source
+	}
+	c.mu.Unlock()
+
+	source.once.Do(func() {
+		source.src, source.err = c.readFile(name)
+	})
+	return source.src, source.err
+}
+
+// Open returns a reader of the named file, from memory once it was read.
+// Without a cache, the file is streamed from the disk instead of being read at once.
+func (c *SourceCache) Open(name string) (io.ReadCloser, error) {
+	if c == nil || c.sources == nil {
+		var file *os.File
+		err := retryRead(name, c.retries(), c.logger(), func() (err error) {
+			file, err = os.Open(name)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+	src, err := c.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(src)), nil
+}
+
+// retries returns the number of retries of failed reads, none for a nil SourceCache.
+func (c *SourceCache) retries() int {
+	if c == nil {
+		return 0
+	}
+	return c.Retries
+}
+
+// logger returns the Logger tracing the retried reads, none for a nil SourceCache.
+func (c *SourceCache) logger() *Logger {
+	if c == nil {
+		return nil
+	}
+	return c.Logger
+}
+
+// readFile reads the named file, retrying failed reads.
+func (c *SourceCache) readFile(name string) (src []byte, err error) {
+	err = retryRead(name, c.retries(), c.logger(), func() (err error) {
+		src, err = os.ReadFile(name)
+		return err
+	})
+	return src, err
+}
+
+// retryRead calls read until it succeeds, retrying it at most retries times with an exponential backoff.
+// Missing or forbidden files fail at once, as retrying can't fix them. The retries are traced by the debug messages of the Logger.
+func retryRead(name string, retries int, logger *Logger, read func() error) error {
+	delay := minReadRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := read()
+		if err == nil || attempt > retries || errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			return err
+		}
+		logger.Debugf("can't read %s, retrying in %v (%d/%d): %v", name, delay, attempt, retries, err)
+		time.Sleep(delay)
+		delay = min(2*delay, maxReadRetryDelay)
+	}
+}
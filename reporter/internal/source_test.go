@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceCache(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(name, []byte("package x\n"), 0o644))
+
+	t.Run("should read each file once", func(t *testing.T) {
+		cache := NewSourceCache()
+		src, err := cache.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, "package x\n", string(src))
+
+		assert.NoError(t, os.WriteFile(name, []byte("package y\n"), 0o644))
+		defer os.WriteFile(name, []byte("package x\n"), 0o644)
+		src, err = cache.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, "package x\n", string(src))
+
+		rd, err := cache.Open(name)
+		assert.NoError(t, err)
+		defer rd.Close()
+		src, err = io.ReadAll(rd)
+		assert.NoError(t, err)
+		assert.Equal(t, "package x\n", string(src))
+	})
+
+	t.Run("should read files every time when nil", func(t *testing.T) {
+		var cache *SourceCache
+		src, err := cache.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, "package x\n", string(src))
+
+		rd, err := cache.Open(name)
+		assert.NoError(t, err)
+		defer rd.Close()
+		src, err = io.ReadAll(rd)
+		assert.NoError(t, err)
+		assert.Equal(t, "package x\n", string(src))
+	})
+
+	t.Run("should read files every time when zero", func(t *testing.T) {
+		cache := &SourceCache{Retries: 1}
+		src, err := cache.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, "package x\n", string(src))
+
+		rd, err := cache.Open(name)
+		assert.NoError(t, err)
+		defer rd.Close()
+		src, err = io.ReadAll(rd)
+		assert.NoError(t, err)
+		assert.Equal(t, "package x\n", string(src))
+	})
+
+	t.Run("should return the error of missing files", func(t *testing.T) {
+		cache := NewSourceCache()
+		_, err := cache.ReadFile("not-exist.go")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		_, err = cache.Open("not-exist.go")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should be safe for concurrent use", func(t *testing.T) {
+		cache := NewSourceCache()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				src, err := cache.ReadFile(name)
+				assert.NoError(t, err)
+				assert.Equal(t, "package x\n", string(src))
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestRetryRead(t *testing.T) {
+	errTransient := errors.New("stale file handle")
+
+	t.Run("should retry failed reads", func(t *testing.T) {
+		attempts := 0
+		err := retryRead("x.go", 2, nil, func() error {
+			attempts++
+			if attempts < 3 {
+				return errTransient
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("should return the last error after the retries", func(t *testing.T) {
+		attempts := 0
+		err := retryRead("x.go", 1, nil, func() error {
+			attempts++
+			return errTransient
+		})
+		assert.ErrorIs(t, err, errTransient)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("should try once without retries", func(t *testing.T) {
+		attempts := 0
+		err := retryRead("x.go", 0, nil, func() error {
+			attempts++
+			return errTransient
+		})
+		assert.ErrorIs(t, err, errTransient)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("should not retry missing files", func(t *testing.T) {
+		attempts := 0
+		err := retryRead("x.go", 3, nil, func() error {
+			attempts++
+			return os.ErrNotExist
+		})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Equal(t, 1, attempts)
+	})
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+// ReportSplit generates the HTML report of the GoProject as a static site under the given directory,
+// with an index.html page per directory and a page per file, named after the file, linked together.
+// The embedded profile of a self-contained report is only written to the root index.html.
+func (gp *GoProject) ReportSplit(outDir string) error {
+	switch gp.Layout {
+	case "", config.LayoutDrilldown:
+	case config.LayoutTree:
+		return errors.New("the tree layout can't be split into pages")
+	case config.LayoutFlat:
+		return errors.New("the flat layout can't be split into pages")
+	default:
+		return fmt.Errorf("unknown layout %q", gp.Layout)
+	}
+
+	data, err := gp.newTemplateData()
+	if err != nil {
+		return err
+	}
+	data.Pages = gp.splitPages()
+
+	gp.Progress.Start("rendering files", len(data.fileViews()))
+	defer gp.Progress.Finish()
+	for _, view := range data.Views {
+		page := *data
+		page.Views = []*TemplateViewData{view}
+		page.InitialID = view.ID
+		page.Page = data.Pages[view.ID]
+		if page.Page != "index.html" {
+			page.Profile = ""
+		}
+		if err := gp.writePage(filepath.Join(outDir, filepath.FromSlash(page.Page)), &page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitPages maps the IDs of the directories and files of the report to the paths of their pages,
+// relative to the initial directory.
+func (gp *GoProject) splitPages() map[string]string {
+	initialDir := gp.InitialDir()
+	rel := func(item *GoListItem) string {
+		rel, err := filepath.Rel(initialDir.RelPkgPath, item.RelPkgPath)
+		if err != nil {
+			return item.RelPkgPath
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	pages := make(map[string]string)
+	for _, dir := range initialDir.AllDirs() {
+		pages[dir.ID] = filepath.ToSlash(filepath.Join(rel(dir.GoListItem), "index.html"))
+	}
+	for _, file := range initialDir.AllFiles() {
+		pages[file.ID] = rel(file.GoListItem) + ".html"
+	}
+	return pages
+}
+
+// writePage renders the template data to the named page, creating its directory.
+func (gp *GoProject) writePage(filename string, data *TemplateData) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("can't create %q: %v", filename, err)
+	}
+	defer file.Close()
+	if err := gp.executeTemplate(file, data); err != nil {
+		return err
+	}
+	return file.Close()
+}
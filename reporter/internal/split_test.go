@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportSplit(t *testing.T) {
+	temp := t.TempDir()
+	absPath := filepath.Join(temp, "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte("package x\n"), 0o644))
+	newProject := func() *GoProject {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: NewGoListItem("a/x.go"), ABSPath: absPath})
+		gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: NewGoListItem("a/b/y y.go"), ABSPath: absPath})
+		return gp
+	}
+
+	t.Run("should write a page per directory and file", func(t *testing.T) {
+		gp := newProject()
+		outDir := filepath.Join(t.TempDir(), "site")
+		assert.NoError(t, gp.ReportSplit(outDir))
+
+		read := func(page string) string {
+			content, err := os.ReadFile(filepath.Join(outDir, page))
+			assert.NoError(t, err)
+			return string(content)
+		}
+		index := read("index.html")
+		assert.Contains(t, index, `href="b/index.html"`)
+		assert.Contains(t, index, `href="x.go.html"`)
+		assert.NotContains(t, index, `<div id="`+gp.SafeDir("a/b").ID+`"`)
+
+		sub := read("b/index.html")
+		assert.Contains(t, sub, `<a href="../index.html">a</a>`)
+		assert.Contains(t, sub, `href="y%20y.go.html"`)
+
+		file := read("b/y y.go.html")
+		assert.Contains(t, file, `<a href="../index.html">a</a>`)
+		assert.Contains(t, file, `<a href="index.html">b</a>`)
+		assert.Contains(t, file, `<div class="line-number">1</div>`)
+	})
+
+	t.Run("should link the file pages to the items around them", func(t *testing.T) {
+		gp := newProject()
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: NewGoListItem("a/z.go"), ABSPath: absPath})
+		outDir := t.TempDir()
+		assert.NoError(t, gp.ReportSplit(outDir))
+
+		x, err := os.ReadFile(filepath.Join(outDir, "x.go.html"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(x), `<a class="sibling" rel="prev" href="b/index.html" hidden></a>`)
+		assert.Contains(t, string(x), `<a class="sibling" rel="next" href="z.go.html" hidden></a>`)
+		z, err := os.ReadFile(filepath.Join(outDir, "z.go.html"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(z), `<a class="sibling" rel="prev" href="x.go.html" hidden></a>`)
+		assert.NotContains(t, string(z), `rel="next"`)
+	})
+
+	t.Run("should not link the siblings of file views in a single page", func(t *testing.T) {
+		var buf strings.Builder
+		assert.NoError(t, newProject().Report(&buf))
+		assert.NotContains(t, buf.String(), `class="sibling"`)
+	})
+
+	t.Run("should only embed the profile in the root page", func(t *testing.T) {
+		gp := newProject()
+		gp.SelfContained = true
+		outDir := t.TempDir()
+		assert.NoError(t, gp.ReportSplit(outDir))
+
+		index, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(index), `id="profile"`)
+		file, err := os.ReadFile(filepath.Join(outDir, "x.go.html"))
+		assert.NoError(t, err)
+		assert.NotContains(t, string(file), `id="profile"`)
+	})
+
+	t.Run("should return error with the tree layout", func(t *testing.T) {
+		gp := newProject()
+		gp.Layout = config.LayoutTree
+		assert.EqualError(t, gp.ReportSplit(t.TempDir()), "the tree layout can't be split into pages")
+	})
+
+	t.Run("should return error with the flat layout", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Layout = config.LayoutFlat
+		assert.EqualError(t, gp.ReportSplit(t.TempDir()), "the flat layout can't be split into pages")
+	})
+}
+
+func TestTemplateDataHref(t *testing.T) {
+	t.Run("should link to fragments in a single page", func(t *testing.T) {
+		td := &TemplateData{}
+		assert.Equal(t, "#a", td.Href("a"))
+	})
+
+	t.Run("should link to pages relative to the current one", func(t *testing.T) {
+		td := &TemplateData{
+			Pages: map[string]string{"root": "index.html", "b": "b/index.html", "x": "b/c/x#1.go.html"},
+			Page:  "b/index.html",
+		}
+		assert.Equal(t, "../index.html", td.Href("root"))
+		assert.Equal(t, "index.html", td.Href("b"))
+		assert.Equal(t, "c/x%231.go.html", td.Href("x"))
+		assert.Equal(t, "#unknown", td.Href("unknown"))
+	})
+}
+
+func TestTemplateDataLineHref(t *testing.T) {
+	t.Run("should link to line fragments in a single page", func(t *testing.T) {
+		td := &TemplateData{}
+		assert.Equal(t, "#x-L12", td.LineHref("x", 12))
+	})
+
+	t.Run("should link to line fragments of pages", func(t *testing.T) {
+		td := &TemplateData{Pages: map[string]string{"x": "b/x.go.html"}, Page: "index.html"}
+		assert.Equal(t, "b/x.go.html#x-L12", td.LineHref("x", 12))
+	})
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stats are the sizes of the parsed profiles and of the tree of the GoProject,
+// and the time spent parsing the profiles and rendering the report, to tell which phase dominates.
+type Stats struct {
+	Blocks     int
+	Files      int
+	Dirs       int
+	Statements int
+	Parse      time.Duration
+	Render     time.Duration
+}
+
+// Stats returns the sizes of the GoProject, with the given durations of the parse and render phases.
+// Blocks counts every block of the profiles as parsed, before any filtering.
+func (gp *GoProject) Stats(parse, render time.Duration) *Stats {
+	stats := &Stats{Statements: gp.Root().StmtCount, Parse: parse, Render: render}
+	for _, profile := range gp.Profiles {
+		stats.Blocks += len(profile.Blocks)
+	}
+	var walk func(dir *GoDir)
+	walk = func(dir *GoDir) {
+		stats.Dirs++
+		stats.Files += len(dir.Files)
+		for _, subDir := range dir.SubDirs {
+			walk(subDir)
+		}
+	}
+	walk(gp.Root())
+	return stats
+}
+
+// String returns the statistics on a single line, such as
+// "STATS: 1200 blocks, 80 files, 12 dirs, 3400 statements, parse 120ms, render 450ms".
+func (stats *Stats) String() string {
+	return fmt.Sprintf("STATS: %d blocks, %d files, %d dirs, %d statements, parse %v, render %v",
+		stats.Blocks, stats.Files, stats.Dirs, stats.Statements,
+		stats.Parse.Round(time.Millisecond), stats.Render.Round(time.Millisecond))
+}
@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("should count the blocks, files, dirs and statements", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.Profiles = []*cover.Profile{
+			{FileName: "a/x.go", Blocks: make([]cover.ProfileBlock, 3)},
+			{FileName: "a/b/y.go", Blocks: make([]cover.ProfileBlock, 2)},
+		}
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/x.go", StmtCount: 4}})
+		gp.SafeDir("a/b").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "a/b/y.go", StmtCount: 2}})
+		gp.Root().Aggregate()
+
+		stats := gp.Stats(1500*time.Microsecond, 2*time.Second)
+		assert.Equal(t, &Stats{Blocks: 5, Files: 2, Dirs: 3, Statements: 6, Parse: 1500 * time.Microsecond, Render: 2 * time.Second}, stats)
+		assert.Equal(t, "STATS: 5 blocks, 2 files, 3 dirs, 6 statements, parse 2ms, render 2s", stats.String())
+	})
+}
@@ -0,0 +1,10 @@
+package internal
+
+import "fmt"
+
+// SummaryLine returns a single line stating the total coverage of the GoProject, meant to be grepped by scripts,
+// such as "COVERAGE: 73.4% (1234/1680 statements)".
+func (gp *GoProject) SummaryLine() string {
+	root := gp.Root()
+	return fmt.Sprintf("COVERAGE: %s (%d/%d statements)", FormatPercent(root.Percent(), root.StmtCount, gp.Precision), root.StmtCoveredCount, root.StmtCount)
+}
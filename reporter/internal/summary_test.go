@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryLine(t *testing.T) {
+	t.Run("should state the total coverage", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.Root().StmtCount = 1680
+		gp.Root().StmtCoveredCount = 1234
+		assert.Equal(t, "COVERAGE: 73.5% (1234/1680 statements)", gp.SummaryLine())
+	})
+
+	t.Run("should use the precision of the report", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.Precision = 0
+		gp.Root().StmtCount = 3
+		gp.Root().StmtCoveredCount = 1
+		assert.Equal(t, "COVERAGE: 33% (1/3 statements)", gp.SummaryLine())
+	})
+
+	t.Run("should not give a percentage without statements", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		assert.Equal(t, "COVERAGE: N/A (0/0 statements)", gp.SummaryLine())
+	})
+}
@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+)
+
+// Size of the sunburst SVG, in pixels.
+const (
+	sunburstSize = 800
+	// sunburstCenter is the radius of the disc of the directory at the center of the sunburst.
+	sunburstCenter = 80
+	// sunburstMinAngle is the smallest angle of the arcs drawn, in radians, thinner arcs being left out.
+	sunburstMinAngle = 0.002
+)
+
+// ReportSunburst writes a standalone HTML page of a zoomable sunburst of the GoProject to the provided io.Writer.
+// Each directory and file is an arc whose angle is proportional to its statements, colored by its coverage,
+// around the arc of its parent directory. Clicking a directory zooms into it, and clicking the center zooms out.
+// The zoomed directory is kept in the fragment of the URL, the ID of its view in the HTML report.
+func (gp *GoProject) ReportSunburst(wr io.Writer) error {
+	initialDir := gp.InitialDir()
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta http-equiv=\"Content-Type\" content=\"text/html; charset=utf-8\">\n")
+	title := "Go Coverage Sunburst"
+	if gp.Title != "" {
+		title = gp.Title
+	}
+	fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(title))
+	sb.WriteString(`<style>
+body { margin: 0; display: flex; justify-content: center; background-color: #1e1e1e; color: #cfcfcf; font-family: Menlo, monospace; }
+.view { display: none; }
+.view.active { display: inline; }
+path { stroke: #1e1e1e; stroke-width: 1; }
+.dir, .up { cursor: pointer; }
+path:hover { opacity: 0.8; }
+</style>
+</head>
+<body>
+`)
+	half := sunburstSize / 2
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"%d %d %d %d\" font-size=\"12\">\n", sunburstSize, sunburstSize, -half, -half, sunburstSize, sunburstSize)
+	gp.writeSunburstView(&sb, initialDir, "")
+	sb.WriteString("</svg>\n")
+	fmt.Fprintf(&sb, `<script>
+const initialID = '%s';
+const renderView = () => {
+	const id = window.location.hash.substring(1);
+	const target = document.getElementById(id) || document.getElementById(initialID);
+	for (const view of document.querySelectorAll('.view')) {
+		view.classList.toggle('active', view === target);
+	}
+};
+window.addEventListener('hashchange', renderView);
+document.addEventListener('click', (event) => {
+	const node = event.target.closest('[data-id]');
+	if (node && node.dataset.id) {
+		window.location.hash = node.dataset.id;
+	}
+});
+renderView();
+</script>
+</body>
+</html>
+`, initialDir.ID)
+
+	_, err := io.WriteString(wr, sb.String())
+	return err
+}
+
+// writeSunburstView writes the view of the sunburst centered on the directory, then the views of its subdirectories.
+// The center links to the view of the parent directory, if any.
+func (gp *GoProject) writeSunburstView(sb *strings.Builder, dir *GoDir, parentID string) {
+	fmt.Fprintf(sb, "<g class=\"view\" id=\"%s\">\n", dir.ID)
+	data := NewTemplateListItemData(dir.GoListItem, gp.Cutlines, gp.Precision)
+	class := "center"
+	if parentID != "" {
+		class = "up"
+	}
+	fmt.Fprintf(sb, "<g class=\"%s\" data-id=\"%s\"><title>%s %s (%d/%d)</title>\n", class, parentID, html.EscapeString(dir.RelPkgPath), data.Percent, dir.StmtCoveredCount, dir.StmtCount)
+	fmt.Fprintf(sb, "<circle r=\"%d\" fill=\"%s\"/>\n", sunburstCenter, gp.classColor(data.ClassName))
+	fmt.Fprintf(sb, "<text text-anchor=\"middle\" y=\"-4\" fill=\"#ffffff\">%s</text>\n", html.EscapeString(dir.Title))
+	fmt.Fprintf(sb, "<text text-anchor=\"middle\" y=\"14\" fill=\"#ffffff\">%s</text>\n", data.Percent)
+	sb.WriteString("</g>\n")
+
+	depth := sunburstDepth(dir)
+	if depth > 0 {
+		ring := float64(sunburstSize/2-sunburstCenter) / float64(depth)
+		gp.writeSunburstArcs(sb, dir, 0, 2*math.Pi, sunburstCenter, ring)
+	}
+	sb.WriteString("</g>\n")
+
+	for _, subDir := range dir.SubDirs {
+		if subDir.StmtCount > 0 {
+			gp.writeSunburstView(sb, subDir, dir.ID)
+		}
+	}
+}
+
+// writeSunburstArcs writes the arcs of the subdirectories and files of the directory between the angles,
+// as a ring starting at the given radius, and the rings of their own items beyond it.
+func (gp *GoProject) writeSunburstArcs(sb *strings.Builder, dir *GoDir, from, to, radius, ring float64) {
+	if dir.StmtCount == 0 {
+		return
+	}
+	var items []*GoListItem
+	var subDirs []*GoDir
+	for _, subDir := range dir.SubDirs {
+		if subDir.StmtCount > 0 {
+			items = append(items, subDir.GoListItem)
+			subDirs = append(subDirs, subDir)
+		}
+	}
+	for _, file := range dir.Files {
+		if file.StmtCount > 0 && !file.Unscored {
+			items = append(items, file.GoListItem)
+			subDirs = append(subDirs, nil)
+		}
+	}
+
+	angle := from
+	step := (to - from) / float64(dir.StmtCount)
+	for i, item := range items {
+		start, end := angle, angle+step*float64(item.StmtCount)
+		angle = end
+		if end-start < sunburstMinAngle {
+			continue
+		}
+		data := NewTemplateListItemData(item, gp.Cutlines, gp.Precision)
+		var attrs string
+		if subDirs[i] != nil {
+			attrs = fmt.Sprintf(" class=\"dir\" data-id=\"%s\"", item.ID)
+		}
+		fmt.Fprintf(sb, "<path%s d=\"%s\" fill=\"%s\"><title>%s %s (%d/%d)</title></path>\n",
+			attrs, sunburstArc(start, end, radius, radius+ring), gp.classColor(data.ClassName),
+			html.EscapeString(item.RelPkgPath), data.Percent, item.StmtCoveredCount, item.StmtCount)
+		if subDirs[i] != nil {
+			gp.writeSunburstArcs(sb, subDirs[i], start, end, radius+ring, ring)
+		}
+	}
+}
+
+// sunburstDepth returns the number of rings around the directory: the depth of its deepest file with statements.
+func sunburstDepth(dir *GoDir) int {
+	var depth int
+	for _, file := range dir.Files {
+		if file.StmtCount > 0 && !file.Unscored {
+			depth = 1
+			break
+		}
+	}
+	for _, subDir := range dir.SubDirs {
+		if subDir.StmtCount > 0 {
+			depth = max(depth, 1+sunburstDepth(subDir))
+		}
+	}
+	return depth
+}
+
+// sunburstArc returns the SVG path of the ring segment between the angles, clockwise from the top,
+// and between the inner and outer radii. A full turn is drawn as a nearly closed ring.
+func sunburstArc(from, to, inner, outer float64) string {
+	to = math.Min(to, from+2*math.Pi-1e-4)
+	large := 0
+	if to-from > math.Pi {
+		large = 1
+	}
+	point := func(angle, radius float64) string {
+		return fmt.Sprintf("%.2f %.2f", radius*math.Sin(angle), -radius*math.Cos(angle))
+	}
+	return fmt.Sprintf("M %s A %.2f %.2f 0 %d 1 %s L %s A %.2f %.2f 0 %d 0 %s Z",
+		point(from, outer), outer, outer, large, point(to, outer),
+		point(to, inner), inner, inner, large, point(from, inner))
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSunburstArc(t *testing.T) {
+	t.Run("should draw a ring segment clockwise from the top", func(t *testing.T) {
+		assert.Equal(t, "M 0.00 -20.00 A 20.00 20.00 0 0 1 20.00 -0.00 L 10.00 -0.00 A 10.00 10.00 0 0 0 0.00 -10.00 Z", sunburstArc(0, 0.5*3.141592653589793, 10, 20))
+	})
+
+	t.Run("should use the large arc flag beyond a half turn", func(t *testing.T) {
+		assert.Contains(t, sunburstArc(0, 4, 10, 20), " 0 1 1 ")
+	})
+}
+
+func TestReportSunburst(t *testing.T) {
+	t.Run("should write a view per directory with an arc per item", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		a := gp.SafeDir("app/a")
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/x.go", Title: "x.go", StmtCount: 10, StmtCoveredCount: 9}})
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/y.go", Title: "y.go", StmtCount: 30, StmtCoveredCount: 3}})
+		gp.SafeDir("app/b").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/b/z.go", Title: "z.go", StmtCount: 20, StmtCoveredCount: 10}})
+		gp.SafeDir("app/c").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/c/empty.go", Title: "empty.go"}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportSunburst(&buf))
+		page := buf.String()
+
+		svg := page[strings.Index(page, "<svg") : strings.Index(page, "</svg>")+len("</svg>")]
+		dec := xml.NewDecoder(strings.NewReader(svg))
+		for {
+			_, err := dec.Token()
+			if err != nil {
+				assert.EqualError(t, err, "EOF")
+				break
+			}
+		}
+
+		app, dirA := gp.SafeDir("app"), gp.SafeDir("app/a")
+		assert.Contains(t, page, `const initialID = '`+app.ID+`';`)
+		assert.Contains(t, page, `<g class="view" id="`+app.ID+`">`)
+		assert.Contains(t, page, `<g class="view" id="`+dirA.ID+`">`)
+		assert.Contains(t, page, `<g class="up" data-id="`+app.ID+`">`)
+		assert.Contains(t, page, `<path class="dir" data-id="`+dirA.ID+`" d="`)
+		assert.Contains(t, page, `fill="`+treemapColors["danger"]+`"><title>app/a/y.go 10.0% (3/30)</title></path>`)
+		assert.NotContains(t, page, "empty.go")
+		assert.NotContains(t, page, `id="`+gp.SafeDir("app/c").ID+`"`)
+	})
+
+	t.Run("should leave out the unscored files", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		a := gp.SafeDir("app/a")
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/x.go", Title: "x.go", StmtCount: 10, StmtCoveredCount: 9}})
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/gen.go", Title: "gen.go", StmtCount: 30, Unscored: true}})
+		gp.SafeDir("app/gen").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/gen/z.go", Title: "z.go", StmtCount: 20, Unscored: true}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportSunburst(&buf))
+		page := buf.String()
+		assert.Contains(t, page, "<title>app/a/x.go 90.0% (9/10)</title>")
+		assert.NotContains(t, page, "gen.go")
+		assert.NotContains(t, page, `id="`+gp.SafeDir("app/gen").ID+`"`)
+		// The only file left fills the whole ring of its directory.
+		assert.Contains(t, page, `<path d="`+sunburstArc(0, 2*math.Pi, sunburstCenter+float64(sunburstSize/2-sunburstCenter)/2, sunburstSize/2)+`"`)
+	})
+}
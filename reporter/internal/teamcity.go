@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ReportTeamCity writes TeamCity service messages recording the total coverage of the GoProject as build statistics:
+// the percentage as CodeCoverageL, and the covered and total statement counts as CodeCoverageAbsSCovered and CodeCoverageAbsSTotal.
+// TeamCity graphs these statistics over the builds.
+func (gp *GoProject) ReportTeamCity(wr io.Writer) error {
+	root := gp.Root()
+	stats := []struct {
+		Key   string
+		Value string
+	}{
+		{"CodeCoverageL", strconv.FormatFloat(root.Percent(), 'f', gp.Precision, 64)},
+		{"CodeCoverageAbsSCovered", strconv.Itoa(root.StmtCoveredCount)},
+		{"CodeCoverageAbsSTotal", strconv.Itoa(root.StmtCount)},
+	}
+	for _, stat := range stats {
+		if _, err := fmt.Fprintf(wr, "##teamcity[buildStatisticValue key='%s' value='%s']\n", stat.Key, stat.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
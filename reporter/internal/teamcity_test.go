@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportTeamCity(t *testing.T) {
+	t.Run("should print the root totals as build statistics", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.SafeDir("a").AddFile(&GoFile{GoListItem: &GoListItem{StmtCount: 1680, StmtCoveredCount: 1234}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportTeamCity(&buf))
+		assert.Equal(t, strings.Join([]string{
+			"##teamcity[buildStatisticValue key='CodeCoverageL' value='73.5']",
+			"##teamcity[buildStatisticValue key='CodeCoverageAbsSCovered' value='1234']",
+			"##teamcity[buildStatisticValue key='CodeCoverageAbsSTotal' value='1680']",
+			"",
+		}, "\n"), buf.String())
+	})
+
+	t.Run("should print 0 without statements", func(t *testing.T) {
+		gp := NewGoProject(".", nil, nil)
+		gp.Precision = 0
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportTeamCity(&buf))
+		assert.Contains(t, buf.String(), "key='CodeCoverageL' value='0'")
+	})
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TestEvent is an event of the go test -json output; see 'go doc test2json'.
+type TestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// TestResult counts the tests of a package which passed, failed and were skipped, subtests aside.
+type TestResult struct {
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+// String describes the test result, like "3 passed, 1 failed", leaving out the empty counts.
+func (r *TestResult) String() string {
+	var parts []string
+	for _, count := range []struct {
+		n     int
+		label string
+	}{{r.Passed, "passed"}, {r.Failed, "failed"}, {r.Skipped, "skipped"}} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.label))
+		}
+	}
+	if len(parts) == 0 {
+		return "no tests"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// maxLineSize is the size of the longest go test -json event which can be read.
+const maxLineSize = 64 << 20
+
+// ParseTestJSON parses the go test -json events of the named file into the test results of their packages,
+// by import path. The events don't hold the coverage profile, which is read from the file written by
+// go test -coverprofile. Lines which aren't JSON events, such as build errors, are skipped.
+func ParseTestJSON(input string) (map[string]*TestResult, error) {
+	rd, err := OpenInput(input)
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+
+	results := make(map[string]*TestResult)
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		var event TestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Package == "" {
+			continue
+		}
+		result, ok := results[event.Package]
+		if !ok {
+			result = &TestResult{}
+			results[event.Package] = result
+		}
+		if event.Test == "" || strings.Contains(event.Test, "/") {
+			continue
+		}
+		switch event.Action {
+		case "pass":
+			result.Passed++
+		case "fail":
+			result.Failed++
+		case "skip":
+			result.Skipped++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read %q: %v", input, err)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no go test -json events found in %q", input)
+	}
+	return results, nil
+}
+
+// SetTestResults sets the test results of the directories of their packages, by import path.
+func (gp *GoProject) SetTestResults(results map[string]*TestResult) {
+	for pkg, result := range results {
+		if dir, ok := gp.Dirs[pkg]; ok {
+			dir.Tests = result
+		} else {
+			gp.Logger.Debugf("skipping the test results of %s, which has no coverage", pkg)
+		}
+	}
+}
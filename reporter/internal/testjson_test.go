@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testEvents is the output of go test -json -coverprofile=cover.prof ./... on a package with a passing,
+// a skipped and a failing test, preceded by a build error which isn't an event.
+const testEvents = `# example.com/app/broken
+{"Time":"2026-10-16T10:13:48.96797028Z","Action":"start","Package":"example.com/app/calc"}
+{"Time":"2026-10-16T10:13:48.970776923Z","Action":"run","Package":"example.com/app/calc","Test":"TestAbs"}
+{"Time":"2026-10-16T10:13:48.970978308Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbs","Output":"=== RUN   TestAbs\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972474251Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbs","Output":"--- PASS: TestAbs (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972493636Z","Action":"pass","Package":"example.com/app/calc","Test":"TestAbs","Elapsed":0}
+{"Time":"2026-10-16T10:13:48.972504552Z","Action":"run","Package":"example.com/app/calc","Test":"TestAbsZero"}
+{"Time":"2026-10-16T10:13:48.97250708Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbsZero","Output":"=== RUN   TestAbsZero\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972510751Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbsZero","Output":"    calc_test.go:12: not yet\n"}
+{"Time":"2026-10-16T10:13:48.972516044Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbsZero","Output":"--- SKIP: TestAbsZero (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972520229Z","Action":"skip","Package":"example.com/app/calc","Test":"TestAbsZero","Elapsed":0}
+{"Time":"2026-10-16T10:13:48.972523464Z","Action":"run","Package":"example.com/app/calc","Test":"TestAbsBroken"}
+{"Time":"2026-10-16T10:13:48.972528031Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbsBroken","Output":"=== RUN   TestAbsBroken\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972530818Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbsBroken","Output":"    calc_test.go:16: broken\n","OutputType":"error"}
+{"Time":"2026-10-16T10:13:48.972534239Z","Action":"output","Package":"example.com/app/calc","Test":"TestAbsBroken","Output":"--- FAIL: TestAbsBroken (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972537079Z","Action":"fail","Package":"example.com/app/calc","Test":"TestAbsBroken","Elapsed":0}
+{"Time":"2026-10-16T10:13:48.972539307Z","Action":"output","Package":"example.com/app/calc","Output":"FAIL\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972542881Z","Action":"output","Package":"example.com/app/calc","Output":"coverage: 66.7% of statements\n"}
+{"Time":"2026-10-16T10:13:48.972843466Z","Action":"output","Package":"example.com/app/calc","Output":"FAIL\texample.com/app/calc\t0.004s\n","OutputType":"frame"}
+{"Time":"2026-10-16T10:13:48.972856299Z","Action":"fail","Package":"example.com/app/calc","Elapsed":0.005}
+`
+
+func TestParseTestJSON(t *testing.T) {
+	temp := t.TempDir()
+
+	t.Run("should count the tests of the packages", func(t *testing.T) {
+		input := filepath.Join(temp, "events.json")
+		assert.NoError(t, os.WriteFile(input, []byte(testEvents), 0o644))
+		results, err := ParseTestJSON(input)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*TestResult{
+			"example.com/app/calc": {Passed: 1, Failed: 1, Skipped: 1},
+		}, results)
+		assert.Equal(t, "1 passed, 1 failed, 1 skipped", results["example.com/app/calc"].String())
+	})
+
+	t.Run("should leave subtests out", func(t *testing.T) {
+		input := filepath.Join(temp, "subtests.json")
+		assert.NoError(t, os.WriteFile(input, []byte(
+			`{"Action":"pass","Package":"a","Test":"TestX/one"}`+"\n"+
+				`{"Action":"pass","Package":"a","Test":"TestX"}`+"\n"+
+				`{"Action":"start","Package":"b"}`+"\n"), 0o644))
+		results, err := ParseTestJSON(input)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*TestResult{"a": {Passed: 1}, "b": {}}, results)
+		assert.Equal(t, "no tests", results["b"].String())
+	})
+
+	t.Run("should return error without events", func(t *testing.T) {
+		input := filepath.Join(temp, "cover.prof")
+		assert.NoError(t, os.WriteFile(input, []byte("mode: set\nexample.com/app/calc/calc.go:4.2,4.11 1 1\n"), 0o644))
+		_, err := ParseTestJSON(input)
+		assert.ErrorContains(t, err, "no go test -json events found in")
+	})
+
+	t.Run("should return error when cannot open file", func(t *testing.T) {
+		_, err := ParseTestJSON(filepath.Join(temp, "not-exist.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestGoProject_SetTestResults(t *testing.T) {
+	gp := NewGoProject(".", nil, nil)
+	dir := gp.SafeDir("example.com/app/calc")
+	result := &TestResult{Passed: 2}
+	gp.SetTestResults(map[string]*TestResult{"example.com/app/calc": result, "example.com/app/other": {Failed: 1}})
+	assert.Same(t, result, dir.Tests)
+	assert.NotContains(t, gp.Dirs, "example.com/app/other")
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReportText writes a plain text report of the GoProject to the provided io.Writer, for terminals.
+// Below the total coverage, it lists the uncovered lines of every file having some.
+// When Context is set, the source of the uncovered lines is printed too, with Context lines around them,
+// uncovered lines being marked with ">".
+func (gp *GoProject) ReportText(wr io.Writer) error {
+	if gp.Context < 0 {
+		return fmt.Errorf("invalid context %d", gp.Context)
+	}
+
+	root := gp.Root()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Coverage: %s (%d/%d statements)\n", FormatPercent(root.Percent(), root.StmtCount, gp.Precision), root.StmtCoveredCount, root.StmtCount)
+
+	files := root.AllFiles()
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].RelPkgPath < files[j].RelPkgPath
+	})
+	for _, file := range files {
+		uncovered := file.UncoveredLines()
+		if len(uncovered) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s: %s (%d/%d statements)\n", file.RelPkgPath, FormatPercent(file.Percent(), file.StmtCount, gp.Precision), file.StmtCoveredCount, file.StmtCount)
+		fmt.Fprintf(&sb, "  uncovered lines %s\n", formatLineRanges(uncovered))
+		if gp.Context > 0 {
+			if err := writeTextContext(&sb, gp.Sources, file, uncovered, gp.Context); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := io.WriteString(wr, sb.String())
+	return err
+}
+
+// UncoveredLines returns the sorted lines overlapped by the profile blocks which didn't run.
+func (file *GoFile) UncoveredLines() []int {
+	seen := make(map[int]bool)
+	var lines []int
+	for _, block := range file.Profile {
+		if block.Count > 0 {
+			continue
+		}
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			if !seen[line] {
+				seen[line] = true
+				lines = append(lines, line)
+			}
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// formatLineRanges formats sorted lines as comma separated ranges, such as "12-14, 20".
+func formatLineRanges(lines []int) string {
+	var ranges []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(lines[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// writeTextContext writes the source of the uncovered lines of the file with context lines around them,
+// like the hunks of a diff. Hunks whose context overlaps are merged, the others being separated by "...".
+func writeTextContext(sb *strings.Builder, sources *SourceCache, file *GoFile, uncovered []int, context int) error {
+	src, err := sources.ReadFile(file.ABSPath)
+	if err != nil {
+		return fmt.Errorf("can't read %q: %v", file.RelPkgPath, err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+
+	marked := make(map[int]bool, len(uncovered))
+	for _, line := range uncovered {
+		marked[line] = true
+	}
+
+	last := 0
+	for _, line := range uncovered {
+		start, end := max(line-context, last+1, 1), min(line+context, len(lines))
+		if start > end {
+			continue
+		}
+		if last > 0 && start > last+1 {
+			sb.WriteString("  ...\n")
+		}
+		for n := start; n <= end; n++ {
+			marker := " "
+			if marked[n] {
+				marker = ">"
+			}
+			fmt.Fprintf(sb, "%s %5d | %s\n", marker, n, lines[n-1])
+		}
+		last = end
+	}
+	return nil
+}
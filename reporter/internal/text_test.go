@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestReportText(t *testing.T) {
+	var src strings.Builder
+	for i := 1; i <= 20; i++ {
+		src.WriteString("line\n")
+	}
+	absPath := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(absPath, []byte(src.String()), 0o644))
+
+	newProject := func(context int) *GoProject {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Context = context
+		gp.Root().AddFile(&GoFile{GoListItem: NewGoListItem("x.go"), ABSPath: absPath, Profile: []cover.ProfileBlock{
+			{StartLine: 2, EndLine: 3, NumStmt: 2, Count: 0},
+			{StartLine: 4, EndLine: 5, NumStmt: 2, Count: 1},
+			{StartLine: 6, EndLine: 6, NumStmt: 1, Count: 0},
+			{StartLine: 15, EndLine: 15, NumStmt: 1, Count: 0},
+		}})
+		gp.Root().AddFile(&GoFile{GoListItem: NewGoListItem("y.go"), ABSPath: absPath, Profile: []cover.ProfileBlock{
+			{StartLine: 1, EndLine: 1, NumStmt: 1, Count: 1},
+		}})
+		for _, file := range gp.Root().Files {
+			for _, block := range file.Profile {
+				file.StmtCount += block.NumStmt
+				if block.Count > 0 {
+					file.StmtCoveredCount += block.NumStmt
+				}
+			}
+		}
+		gp.Root().Aggregate()
+		return gp
+	}
+
+	t.Run("should list the uncovered lines of files having some", func(t *testing.T) {
+		var buf strings.Builder
+		assert.NoError(t, newProject(0).ReportText(&buf))
+		assert.Equal(t, "Coverage: 42.9% (3/7 statements)\n\nx.go: 33.3% (2/6 statements)\n  uncovered lines 2-3, 6, 15\n", buf.String())
+	})
+
+	t.Run("should print the source of uncovered lines with context", func(t *testing.T) {
+		var buf strings.Builder
+		assert.NoError(t, newProject(1).ReportText(&buf))
+		assert.Contains(t, buf.String(), "  uncovered lines 2-3, 6, 15\n"+
+			"      1 | line\n"+
+			">     2 | line\n"+
+			">     3 | line\n"+
+			"      4 | line\n"+
+			"      5 | line\n"+
+			">     6 | line\n"+
+			"      7 | line\n"+
+			"  ...\n"+
+			"     14 | line\n"+
+			">    15 | line\n"+
+			"     16 | line\n")
+	})
+
+	t.Run("should return error with negative context", func(t *testing.T) {
+		assert.EqualError(t, newProject(-1).ReportText(&strings.Builder{}), "invalid context -1")
+	})
+
+	t.Run("should return error when cannot read source", func(t *testing.T) {
+		gp := newProject(1)
+		gp.Root().Files[0].ABSPath = filepath.Join(t.TempDir(), "not-exist.go")
+		assert.ErrorContains(t, gp.ReportText(&strings.Builder{}), `can't read "x.go"`)
+	})
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	assert.Equal(t, "", formatLineRanges(nil))
+	assert.Equal(t, "1-3, 5, 7-8", formatLineRanges([]int{1, 2, 3, 5, 7, 8}))
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CheckThresholds returns an error listing every coverage violation.
+// The project total must reach failUnder. When thresholds are given, every package must also reach
+// the minimum of its longest matching path prefix, or failUnder if no prefix matches.
+// Projects and packages without statements have nothing to cover and always pass.
+func (gp *GoProject) CheckThresholds(failUnder float64, thresholds map[string]float64) error {
+	var violations []string
+
+	root := gp.Root()
+	if root.StmtCount > 0 && root.Percent() < failUnder {
+		violations = append(violations, fmt.Sprintf("total coverage %s is below %s", FormatPercent(root.Percent(), root.StmtCount, gp.Precision), FormatPercent(failUnder, root.StmtCount, gp.Precision)))
+	}
+
+	if len(thresholds) > 0 {
+		for _, dir := range root.AllDirs() {
+			if len(dir.Files) == 0 || dir.StmtCount == 0 {
+				continue
+			}
+			minimum, longest := failUnder, -1
+			for prefix, m := range thresholds {
+				if matchPathPrefix(dir.RelPkgPath, prefix) && len(prefix) > longest {
+					minimum, longest = m, len(prefix)
+				}
+			}
+			if dir.Percent() < minimum {
+				violations = append(violations, fmt.Sprintf("%s: coverage %s is below %s", dir.RelPkgPath, FormatPercent(dir.Percent(), dir.StmtCount, gp.Precision), FormatPercent(minimum, dir.StmtCount, gp.Precision)))
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return errors.New("coverage thresholds not met:\n\t" + strings.Join(violations, "\n\t"))
+}
+
+// CheckZeroCoverage returns an error listing every file having statements but none of them covered.
+// Ignored files are not part of the project, and unscored files don't count, so they are never listed.
+func (gp *GoProject) CheckZeroCoverage() error {
+	var files []string
+	for _, file := range gp.Root().AllFiles() {
+		if !file.Unscored && file.StmtCount > 0 && file.StmtCoveredCount == 0 {
+			files = append(files, file.RelPkgPath)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+	return fmt.Errorf("%d files without coverage:\n\t%s", len(files), strings.Join(files, "\n\t"))
+}
+
+// matchPathPrefix reports whether the path is the prefix itself or lies under it.
+func matchPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	newProject := func() *GoProject {
+		gp := NewGoProject("app", nil, nil)
+		crypto := gp.SafeDir("app/internal/crypto")
+		crypto.AddFile(&GoFile{GoListItem: &GoListItem{StmtCount: 10, StmtCoveredCount: 8}})
+		exp := gp.SafeDir("app/experimental")
+		exp.AddFile(&GoFile{GoListItem: &GoListItem{StmtCount: 10, StmtCoveredCount: 3}})
+		gp.SafeDir("app/empty").AddFile(&GoFile{GoListItem: &GoListItem{}})
+		gp.Root().Aggregate()
+		return gp
+	}
+
+	t.Run("should pass without thresholds", func(t *testing.T) {
+		assert.NoError(t, newProject().CheckThresholds(0, nil))
+	})
+
+	t.Run("should fail when total is below fail-under", func(t *testing.T) {
+		err := newProject().CheckThresholds(60, nil)
+		assert.EqualError(t, err, "coverage thresholds not met:\n\ttotal coverage 55.0% is below 60.0%")
+	})
+
+	t.Run("should format the percentages with the precision", func(t *testing.T) {
+		gp := newProject()
+		gp.Precision = 2
+		err := gp.CheckThresholds(60, nil)
+		assert.EqualError(t, err, "coverage thresholds not met:\n\ttotal coverage 55.00% is below 60.00%")
+	})
+
+	t.Run("should check packages against longest matching prefix", func(t *testing.T) {
+		err := newProject().CheckThresholds(20, map[string]float64{
+			"app":                 10,
+			"app/internal":        50,
+			"app/internal/crypto": 90,
+		})
+		assert.EqualError(t, err, "coverage thresholds not met:\n\tapp/internal/crypto: coverage 80.0% is below 90.0%")
+	})
+
+	t.Run("should fall back to fail-under for packages without entry", func(t *testing.T) {
+		err := newProject().CheckThresholds(50, map[string]float64{
+			"app/internal/crypto": 70,
+			"app/inter":           100,
+		})
+		assert.EqualError(t, err, "coverage thresholds not met:\n\tapp/experimental: coverage 30.0% is below 50.0%")
+	})
+
+	t.Run("should pass without statements", func(t *testing.T) {
+		gp := NewGoProject("app", nil, nil)
+		gp.SafeDir("app/empty").AddFile(&GoFile{GoListItem: &GoListItem{}})
+		gp.Root().Aggregate()
+		assert.NoError(t, gp.CheckThresholds(50, map[string]float64{"app": 90}))
+	})
+}
+
+func TestCheckZeroCoverage(t *testing.T) {
+	t.Run("should pass when every file is covered", func(t *testing.T) {
+		gp := NewGoProject("app", nil, nil)
+		gp.SafeDir("app/a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/x.go", StmtCount: 10, StmtCoveredCount: 1}})
+		gp.SafeDir("app/a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/empty.go"}})
+		assert.NoError(t, gp.CheckZeroCoverage())
+	})
+
+	t.Run("should list every file without coverage", func(t *testing.T) {
+		gp := NewGoProject("app", nil, nil)
+		gp.SafeDir("app/b").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/b/y.go", StmtCount: 3}})
+		gp.SafeDir("app/a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/x.go", StmtCount: 10, StmtCoveredCount: 1}})
+		gp.SafeDir("app/a").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/z.go", StmtCount: 2}})
+		err := gp.CheckZeroCoverage()
+		assert.EqualError(t, err, "2 files without coverage:\n\tapp/a/z.go\n\tapp/b/y.go")
+	})
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+// Size of the treemap SVG, in pixels.
+const (
+	treemapWidth  = 1200
+	treemapHeight = 800
+	// treemapPadding is the inset of the content of a directory within its rectangle.
+	treemapPadding = 2
+)
+
+// treemapColors maps the class names of the cutlines to the fill colors of the treemap.
+var treemapColors = map[string]string{
+	config.ClassExcellent: "#1e90ff",
+	config.ClassSafe:      "#2e9e44",
+	config.ClassWarning:   "#d9a400",
+	config.ClassDanger:    "#d13b3b",
+	"":                    "#555555",
+}
+
+// classColor returns the fill color of the class name of the cutlines,
+// using the configured colors when they are set.
+func (gp *GoProject) classColor(className string) string {
+	if gp.Colors != nil {
+		switch className {
+		case config.ClassDanger:
+			return gp.Colors.Danger
+		case config.ClassWarning:
+			return gp.Colors.Warning
+		case config.ClassSafe:
+			return gp.Colors.Safe
+		case config.ClassExcellent:
+			if gp.Colors.Excellent != "" {
+				return gp.Colors.Excellent
+			}
+		}
+	}
+	return treemapColors[className]
+}
+
+// TreemapRect is a rectangle of the treemap.
+type TreemapRect struct {
+	X, Y, W, H float64
+}
+
+// ReportTreemap writes a standalone SVG treemap of the GoProject to the provided io.Writer.
+// Each file is a rectangle whose area is proportional to its statements, colored by its coverage.
+func (gp *GoProject) ReportTreemap(wr io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"Menlo, monospace\" font-size=\"11\">\n", treemapWidth, treemapHeight, treemapWidth, treemapHeight)
+	fmt.Fprintf(&sb, "<rect width=\"%d\" height=\"%d\" fill=\"#1e1e1e\"/>\n", treemapWidth, treemapHeight)
+	gp.writeTreemapDir(&sb, gp.InitialDir(), TreemapRect{W: treemapWidth, H: treemapHeight})
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(wr, sb.String())
+	return err
+}
+
+// writeTreemapDir lays out the subdirectories and files of the directory within the rectangle.
+func (gp *GoProject) writeTreemapDir(sb *strings.Builder, dir *GoDir, r TreemapRect) {
+	var items []*GoListItem
+	var subDirs []*GoDir
+	for _, subDir := range dir.SubDirs {
+		if subDir.StmtCount > 0 {
+			items = append(items, subDir.GoListItem)
+			subDirs = append(subDirs, subDir)
+		}
+	}
+	for _, file := range dir.Files {
+		if file.StmtCount > 0 && !file.Unscored {
+			items = append(items, file.GoListItem)
+			subDirs = append(subDirs, nil)
+		}
+	}
+
+	weights := make([]float64, len(items))
+	for i, item := range items {
+		weights[i] = float64(item.StmtCount)
+	}
+
+	for i, rect := range Squarify(weights, r) {
+		item := items[i]
+		data := NewTemplateListItemData(item, gp.Cutlines, gp.Precision)
+		fmt.Fprintf(sb, "<g><title>%s %s (%d/%d)</title>\n", html.EscapeString(item.RelPkgPath), data.Percent, item.StmtCoveredCount, item.StmtCount)
+		if subDirs[i] != nil {
+			fmt.Fprintf(sb, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"none\" stroke=\"#cfcfcf\" stroke-width=\"1\"/>\n", rect.X, rect.Y, rect.W, rect.H)
+			inner := TreemapRect{X: rect.X + treemapPadding, Y: rect.Y + treemapPadding, W: rect.W - 2*treemapPadding, H: rect.H - 2*treemapPadding}
+			if inner.W > 0 && inner.H > 0 {
+				gp.writeTreemapDir(sb, subDirs[i], inner)
+			}
+			sb.WriteString("</g>\n")
+			continue
+		}
+		fmt.Fprintf(sb, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"%s\" stroke=\"#1e1e1e\" stroke-width=\"1\"/>\n", rect.X, rect.Y, rect.W, rect.H, gp.classColor(data.ClassName))
+		if rect.W > float64(len(item.Title))*7+4 && rect.H > 14 {
+			fmt.Fprintf(sb, "<text x=\"%.2f\" y=\"%.2f\" fill=\"#ffffff\">%s</text>\n", rect.X+2, rect.Y+12, html.EscapeString(item.Title))
+		}
+		sb.WriteString("</g>\n")
+	}
+}
+
+// Squarify lays out rectangles with areas proportional to the weights within the given rectangle,
+// keeping their aspect ratios close to 1. The rectangles are returned in the order of the weights.
+// See "Squarified Treemaps" by Bruls, Huizing and van Wijk.
+func Squarify(weights []float64, r TreemapRect) []TreemapRect {
+	result := make([]TreemapRect, len(weights))
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 || r.W <= 0 || r.H <= 0 {
+		return result
+	}
+
+	// Lay out the largest weights first, as areas in pixels.
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return weights[order[i]] > weights[order[j]]
+	})
+	areas := make([]float64, len(weights))
+	for i, idx := range order {
+		areas[i] = weights[idx] / total * r.W * r.H
+	}
+
+	for start := 0; start < len(areas); {
+		side := math.Min(r.W, r.H)
+		end := start + 1
+		for end < len(areas) && worstRatio(areas[start:end+1], side) <= worstRatio(areas[start:end], side) {
+			end++
+		}
+
+		var sum float64
+		for _, area := range areas[start:end] {
+			sum += area
+		}
+		if r.W >= r.H {
+			// Lay out the row as a column on the left.
+			width := sum / r.H
+			y := r.Y
+			for i := start; i < end; i++ {
+				height := areas[i] / width
+				result[order[i]] = TreemapRect{X: r.X, Y: y, W: width, H: height}
+				y += height
+			}
+			r = TreemapRect{X: r.X + width, Y: r.Y, W: r.W - width, H: r.H}
+		} else {
+			// Lay out the row on the top.
+			height := sum / r.W
+			x := r.X
+			for i := start; i < end; i++ {
+				width := areas[i] / height
+				result[order[i]] = TreemapRect{X: x, Y: r.Y, W: width, H: height}
+				x += width
+			}
+			r = TreemapRect{X: r.X, Y: r.Y + height, W: r.W, H: r.H - height}
+		}
+		start = end
+	}
+	return result
+}
+
+// worstRatio returns the highest aspect ratio of the areas laid out as a row along the given side.
+func worstRatio(areas []float64, side float64) float64 {
+	sum, lo, hi := 0.0, math.Inf(1), 0.0
+	for _, area := range areas {
+		sum += area
+		lo = math.Min(lo, area)
+		hi = math.Max(hi, area)
+	}
+	side2, sum2 := side*side, sum*sum
+	return math.Max(side2*hi/sum2, sum2/(side2*lo))
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSquarify(t *testing.T) {
+	t.Run("should fill the rectangle with proportional areas", func(t *testing.T) {
+		weights := []float64{6, 6, 4, 3, 2, 2, 1}
+		r := TreemapRect{X: 10, Y: 20, W: 6, H: 4}
+		rects := Squarify(weights, r)
+		assert.Len(t, rects, len(weights))
+
+		var total float64
+		for i, rect := range rects {
+			assert.InDelta(t, weights[i], rect.W*rect.H, 1e-9)
+			assert.GreaterOrEqual(t, rect.X, r.X-1e-9)
+			assert.GreaterOrEqual(t, rect.Y, r.Y-1e-9)
+			assert.LessOrEqual(t, rect.X+rect.W, r.X+r.W+1e-9)
+			assert.LessOrEqual(t, rect.Y+rect.H, r.Y+r.H+1e-9)
+			total += rect.W * rect.H
+		}
+		assert.InDelta(t, r.W*r.H, total, 1e-9)
+	})
+
+	t.Run("should return empty rectangles without weights", func(t *testing.T) {
+		rects := Squarify([]float64{0, 0}, TreemapRect{W: 10, H: 10})
+		assert.Equal(t, []TreemapRect{{}, {}}, rects)
+	})
+}
+
+func TestReportTreemap(t *testing.T) {
+	t.Run("should write a valid svg with a rectangle per file", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		a := gp.SafeDir("app/a")
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/x.go", Title: "x.go", StmtCount: 10, StmtCoveredCount: 9}})
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/y.go", Title: "y.go", StmtCount: 30, StmtCoveredCount: 3}})
+		gp.SafeDir("app/b").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/b/z.go", Title: "z.go", StmtCount: 20, StmtCoveredCount: 10}})
+		gp.SafeDir("app/c").AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/c/empty.go", Title: "empty.go"}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		err := gp.ReportTreemap(&buf)
+		assert.NoError(t, err)
+
+		dec := xml.NewDecoder(strings.NewReader(buf.String()))
+		for {
+			_, err := dec.Token()
+			if err != nil {
+				assert.EqualError(t, err, "EOF")
+				break
+			}
+		}
+		svg := buf.String()
+		assert.Contains(t, svg, `fill="`+treemapColors["safe"]+`"`)
+		assert.Contains(t, svg, `fill="`+treemapColors["danger"]+`"`)
+		assert.Contains(t, svg, `fill="`+treemapColors["warning"]+`"`)
+		assert.Contains(t, svg, "<title>app/a/y.go 10.0% (3/30)</title>")
+		assert.NotContains(t, svg, "empty.go")
+	})
+
+	t.Run("should leave out the unscored files", func(t *testing.T) {
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		a := gp.SafeDir("app/a")
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/x.go", Title: "x.go", StmtCount: 10, StmtCoveredCount: 9}})
+		a.AddFile(&GoFile{GoListItem: &GoListItem{RelPkgPath: "app/a/gen.go", Title: "gen.go", StmtCount: 30, Unscored: true}})
+		gp.Root().Aggregate()
+
+		var buf strings.Builder
+		assert.NoError(t, gp.ReportTreemap(&buf))
+		svg := buf.String()
+		assert.Contains(t, svg, "<title>app/a/x.go 90.0% (9/10)</title>")
+		assert.NotContains(t, svg, "gen.go")
+	})
+}
@@ -0,0 +1,24 @@
+package internal
+
+import "runtime/debug"
+
+// modulePath is the path of the covreport module.
+const modulePath = "github.com/drappier-charles/covreport"
+
+// Version returns the version of covreport recorded in the build information,
+// whether it was built as the main module or as a dependency.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+	return "(devel)"
+}
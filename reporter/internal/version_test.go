@@ -0,0 +1,13 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	t.Run("should not be empty", func(t *testing.T) {
+		assert.NotEmpty(t, Version())
+	})
+}
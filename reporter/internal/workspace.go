@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Module is a module of a Go workspace.
+type Module struct {
+	// Path is the module path declared by its go.mod file.
+	Path string
+	// Dir is the directory of the module.
+	Dir string
+}
+
+// LoadWorkspace returns the modules used by the go.work file of the directory.
+func LoadWorkspace(dir string) ([]*Module, error) {
+	filename := filepath.Join(dir, "go.work")
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %q: %v", filename, err)
+	}
+
+	var modules []*Module
+	for _, use := range parseWorkspaceUses(data) {
+		modDir := use
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(dir, filepath.FromSlash(use))
+		}
+		modFile := filepath.Join(modDir, "go.mod")
+		modData, err := os.ReadFile(modFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read %q: %v", modFile, err)
+		}
+		modPath, err := parseModulePath(modData)
+		if err != nil {
+			return nil, fmt.Errorf("can't read %q: %v", modFile, err)
+		}
+		modules = append(modules, &Module{Path: modPath, Dir: modDir})
+	}
+	return modules, nil
+}
+
+// parseWorkspaceUses returns the directories of the use directives of a go.work file,
+// written on a single line or as a block.
+func parseWorkspaceUses(data []byte) []string {
+	var uses []string
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			uses = append(uses, strings.Trim(fields[0], "\"`"))
+		case fields[0] == "use" && len(fields) >= 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "use" && len(fields) >= 2:
+			uses = append(uses, strings.Trim(fields[1], "\"`"))
+		}
+	}
+	return uses
+}
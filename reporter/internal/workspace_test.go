@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadWorkspace(t *testing.T) {
+	temp := t.TempDir()
+	writeFile := func(name, content string) {
+		name = filepath.Join(temp, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		assert.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+	}
+	writeFile("go.work", "go 1.21\n\nuse ./api // the API\n\nuse (\n\t./cli\n\t\"./lib/core\"\n)\n")
+	writeFile("api/go.mod", "module example.com/api\n")
+	writeFile("api/server/x.go", "package server\n")
+	writeFile("cli/go.mod", "module example.com/cli\n")
+	writeFile("cli/main.go", "package main\n")
+	writeFile("lib/core/go.mod", "module example.com/lib/core\n")
+
+	t.Run("should load the modules of the use directives", func(t *testing.T) {
+		modules, err := LoadWorkspace(temp)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Module{
+			{Path: "example.com/api", Dir: filepath.Join(temp, "api")},
+			{Path: "example.com/cli", Dir: filepath.Join(temp, "cli")},
+			{Path: "example.com/lib/core", Dir: filepath.Join(temp, "lib", "core")},
+		}, modules)
+	})
+
+	t.Run("should return error when a module has no go.mod", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.work"), []byte("use ./missing\n"), 0o644))
+		_, err := LoadWorkspace(dir)
+		assert.ErrorContains(t, err, "can't read")
+	})
+
+	t.Run("should resolve packages from the module roots", func(t *testing.T) {
+		modules, err := LoadWorkspace(temp)
+		assert.NoError(t, err)
+		pkgs := map[string]*Pkg{"example.com/api/server": nil, "example.com/cli": nil}
+		assert.True(t, resolveModulesPkgs(pkgs, modules))
+		assert.Equal(t, filepath.Join(temp, "api", "server"), pkgs["example.com/api/server"].Dir)
+		assert.Equal(t, filepath.Join(temp, "cli"), pkgs["example.com/cli"].Dir)
+	})
+
+	t.Run("should make each module a top-level directory", func(t *testing.T) {
+		modules, err := LoadWorkspace(temp)
+		assert.NoError(t, err)
+		input := filepath.Join(temp, "api.prof") + InputSeparator + filepath.Join(temp, "cli.prof")
+		writeFile("api.prof", "mode: set\nexample.com/api/server/x.go:1.1,1.15 1 1\n")
+		writeFile("cli.prof", "mode: set\nexample.com/cli/main.go:1.1,1.13 1 0\n")
+
+		gp := NewGoProject(".", &config.Cutlines{Safe: 70, Warning: 40}, nil)
+		gp.Modules = modules
+		assert.NoError(t, gp.Parse(input))
+
+		root := gp.Root()
+		assert.Len(t, root.SubDirs, 2)
+		titles := []string{root.SubDirs[0].Title, root.SubDirs[1].Title}
+		assert.ElementsMatch(t, []string{"example.com/api", "example.com/cli"}, titles)
+		assert.Equal(t, 2, root.StmtCount)
+		assert.Equal(t, 1, root.StmtCoveredCount)
+		assert.Equal(t, root, gp.InitialDir())
+		assert.NotContains(t, gp.Dirs, "example.com")
+	})
+}
+
+func TestParseWorkspaceUses(t *testing.T) {
+	uses := parseWorkspaceUses([]byte("go 1.22\n// use ./commented\nuse .\nuse (\n\t./a // a\n\n\t`./b`\n)\nreplace x => ./y\n"))
+	assert.Equal(t, []string{".", "./a", "./b"}, uses)
+	assert.Empty(t, parseWorkspaceUses([]byte("go 1.22\n")))
+}
@@ -0,0 +1,22 @@
+package reporter
+
+import "github.com/drappier-charles/covreport/reporter/internal"
+
+// JSONSchemaVersion is the version of the shape of the JSON report, held by its schemaVersion field.
+// It is bumped whenever a field is removed, renamed or changes meaning, new fields keeping the version.
+const JSONSchemaVersion = internal.JSONSchemaVersion
+
+// SummaryJSON is the top-level object of the JSON report, for Go callers to unmarshal it directly.
+type SummaryJSON = internal.JSONReport
+
+// Types of the objects nested in the JSON report.
+type (
+	// JSONDir is a directory of the coverage tree with its subdirectories and files.
+	JSONDir = internal.JSONDir
+	// JSONFile is a source file of the coverage tree with its profile blocks and functions.
+	JSONFile = internal.JSONFile
+	// JSONFunc is a function or method of a source file.
+	JSONFunc = internal.JSONFunc
+	// JSONBlock is a single block of a coverage profile.
+	JSONBlock = internal.JSONBlock
+)
@@ -0,0 +1,52 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter"
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryJSON(t *testing.T) {
+	t.Run("should unmarshal the json report", func(t *testing.T) {
+		temp := t.TempDir()
+		source := filepath.Join(temp, "x.go")
+		assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+		input := filepath.Join(temp, "cover.prof")
+		assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+
+		var buf bytes.Buffer
+		assert.NoError(t, reporter.ReportTo(&config.Config{
+			Input:    input,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Format:   config.FormatJSON,
+			Quiet:    true,
+		}, &buf))
+
+		var summary reporter.SummaryJSON
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &summary))
+		assert.Equal(t, reporter.JSONSchemaVersion, summary.SchemaVersion)
+		assert.Equal(t, 1, summary.Root.Statements)
+		assert.Equal(t, 100.0, summary.Root.Percent)
+
+		var files []*reporter.JSONFile
+		var walk func(dir *reporter.JSONDir)
+		walk = func(dir *reporter.JSONDir) {
+			files = append(files, dir.Files...)
+			for _, subDir := range dir.Dirs {
+				walk(subDir)
+			}
+		}
+		walk(summary.Root)
+		assert.Len(t, files, 1)
+		assert.Equal(t, []*reporter.JSONBlock{{StartLine: 3, StartCol: 10, EndLine: 5, EndCol: 2, Statements: 1, Count: 1}}, files[0].Blocks)
+		assert.Equal(t, "f", files[0].Funcs[0].Name)
+	})
+}
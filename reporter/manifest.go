@@ -0,0 +1,132 @@
+package reporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/drappier-charles/covreport/reporter/internal"
+)
+
+// Manifest is the index of the reports generated with the same manifest file, for dashboards listing them.
+type Manifest struct {
+	Reports []*ManifestEntry `json:"reports"`
+}
+
+// ManifestEntry is a report of a Manifest, with its overall coverage.
+// Output is the path of the report, relative to the directory of the manifest when possible, with slashes.
+type ManifestEntry struct {
+	Title             string  `json:"title,omitempty"`
+	Output            string  `json:"output"`
+	Statements        int     `json:"statements"`
+	CoveredStatements int     `json:"coveredStatements"`
+	Percent           float64 `json:"percent"`
+	Generated         string  `json:"generated"`
+}
+
+// manifestLockTimeout is how long AppendManifest waits for another process to release the manifest.
+// A lock older than that is assumed to be left by a crashed process, and broken.
+const manifestLockTimeout = 10 * time.Second
+
+// AppendManifest adds the entry to the manifest file, created if needed, replacing the entry of the same output if any.
+// The manifest is locked while it is updated, so concurrent runs sharing a manifest don't lose entries,
+// and written atomically, so dashboards never read a partial one.
+func AppendManifest(filename string, entry *ManifestEntry) error {
+	return appendManifest(filename, entry, nil)
+}
+
+// appendManifest is AppendManifest, warning through the Logger when it breaks a stale lock.
+func appendManifest(filename string, entry *ManifestEntry, logger *internal.Logger) error {
+	unlock, err := lockManifest(filename, logger)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var manifest Manifest
+	content, err := os.ReadFile(filename)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return fmt.Errorf("can't read manifest %q: %v", filename, err)
+		}
+	}
+
+	replaced := false
+	for i, report := range manifest.Reports {
+		if report.Output == entry.Output {
+			manifest.Reports[i], replaced = entry, true
+		}
+	}
+	if !replaced {
+		manifest.Reports = append(manifest.Reports, entry)
+	}
+
+	content, err = json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := &outputFile{name: filename}
+	defer file.Close()
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// lockManifest creates the lock file of the manifest, waiting for other processes holding it,
+// and returns the function removing it. Breaking a stale lock is warned through the Logger.
+func lockManifest(filename string, logger *internal.Logger) (func(), error) {
+	lock := filename + ".lock"
+	deadline := time.Now().Add(manifestLockTimeout)
+	for {
+		file, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("can't lock manifest %q: %v", filename, err)
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > manifestLockTimeout {
+			logger.Warnf("breaking stale lock %s", lock)
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("can't lock manifest %q: %s is held by another process", filename, lock)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newManifestEntry returns the manifest entry of the report of the configuration, of the GoProject.
+func newManifestEntry(gp *internal.GoProject, cfg *config.Config) *ManifestEntry {
+	output := cfg.Output
+	if cfg.SplitOutput != "" {
+		output = cfg.SplitOutput
+	}
+	if abs, err := filepath.Abs(output); err == nil {
+		if dir, err := filepath.Abs(filepath.Dir(cfg.Manifest)); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				output = rel
+			}
+		}
+	}
+	root := gp.Root()
+	return &ManifestEntry{
+		Title:             cfg.Title,
+		Output:            filepath.ToSlash(output),
+		Statements:        root.StmtCount,
+		CoveredStatements: root.StmtCoveredCount,
+		Percent:           root.Percent(),
+		Generated:         time.Now().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,88 @@
+package reporter_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/drappier-charles/covreport/reporter"
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendManifest(t *testing.T) {
+	readManifest := func(t *testing.T, filename string) *reporter.Manifest {
+		content, err := os.ReadFile(filename)
+		assert.NoError(t, err)
+		var manifest reporter.Manifest
+		assert.NoError(t, json.Unmarshal(content, &manifest))
+		return &manifest
+	}
+
+	t.Run("should add the entries and replace the ones of the same output", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "manifest.json")
+		assert.NoError(t, reporter.AppendManifest(filename, &reporter.ManifestEntry{Output: "a.html", Percent: 50}))
+		assert.NoError(t, reporter.AppendManifest(filename, &reporter.ManifestEntry{Output: "b.html", Percent: 60}))
+		assert.NoError(t, reporter.AppendManifest(filename, &reporter.ManifestEntry{Output: "a.html", Percent: 70}))
+
+		assert.Equal(t, &reporter.Manifest{Reports: []*reporter.ManifestEntry{
+			{Output: "a.html", Percent: 70},
+			{Output: "b.html", Percent: 60},
+		}}, readManifest(t, filename))
+		assert.NoFileExists(t, filename+".lock")
+	})
+
+	t.Run("should keep every entry of concurrent appends", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "manifest.json")
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, reporter.AppendManifest(filename, &reporter.ManifestEntry{Output: fmt.Sprintf("%d.html", i)}))
+			}(i)
+		}
+		wg.Wait()
+		assert.Len(t, readManifest(t, filename).Reports, 10)
+	})
+
+	t.Run("should return error with an invalid manifest", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "manifest.json")
+		assert.NoError(t, os.WriteFile(filename, []byte("not json"), 0o644))
+		assert.ErrorContains(t, reporter.AppendManifest(filename, &reporter.ManifestEntry{Output: "a.html"}), "can't read manifest")
+	})
+
+	t.Run("should add the written report relative to the manifest", func(t *testing.T) {
+		temp := t.TempDir()
+		source := filepath.Join(temp, "x.go")
+		assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+		input := filepath.Join(temp, "cover.prof")
+		assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+		assert.NoError(t, os.Mkdir(filepath.Join(temp, "reports"), 0o755))
+		filename := filepath.Join(temp, "manifest.json")
+
+		err := reporter.Report(&config.Config{
+			Input:    input,
+			Output:   filepath.Join(temp, "reports", "x.html"),
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Title:    "x service",
+			Manifest: filename,
+			Quiet:    true,
+		})
+		assert.NoError(t, err)
+
+		reports := readManifest(t, filename).Reports
+		if assert.Len(t, reports, 1) {
+			assert.Equal(t, "x service", reports[0].Title)
+			assert.Equal(t, "reports/x.html", reports[0].Output)
+			assert.Equal(t, 1, reports[0].Statements)
+			assert.Equal(t, 1, reports[0].CoveredStatements)
+			assert.Equal(t, 100.0, reports[0].Percent)
+			assert.NotEmpty(t, reports[0].Generated)
+		}
+	})
+}
@@ -2,77 +2,724 @@
 package reporter
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"go/build"
+	"io"
+	"io/fs"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/drappier-charles/covreport/reporter/config"
 	"github.com/drappier-charles/covreport/reporter/internal"
+	"gopkg.in/yaml.v3"
 )
 
-// Report generates a coverage report using the given configuration.
+// Report generates a coverage report using the given configuration, written to cfg.Output,
+// or to stdout for the formats meant for terminals and CI logs, the group table of cfg.GroupBy then going to stderr.
+// See ReportTo.
+// The report is written to a temporary file renamed to cfg.Output once complete, so a failing or interrupted run
+// leaves a previous report untouched, and readers never see a partial one.
 func Report(cfg *config.Config) error {
-	gp := internal.NewGoProject(cfg.Root, cfg.Cutlines, cfg.Ignores)
-	if err := gp.Parse(cfg.Input); err != nil {
-		return err
+	if cfg.SplitOutput != "" || stdoutFormats[outputFormat(cfg)] {
+		return reportTo(cfg, stdout(cfg), stdoutFormats[outputFormat(cfg)], nil)
+	}
+	file := &outputFile{name: cfg.Output}
+	err := reportTo(cfg, file, false, file.Commit)
+	return errors.Join(err, file.Close())
+}
+
+// stdoutFormats are the formats written to stdout by Report, whatever cfg.Output.
+var stdoutFormats = map[string]bool{
+	config.FormatGitHubActions: true,
+	config.FormatText:          true,
+	config.FormatTeamCity:      true,
+}
+
+// stdout returns the writer of the messages to stdout of the configuration.
+func stdout(cfg *config.Config) io.Writer {
+	if cfg.Stdout != nil {
+		return cfg.Stdout
+	}
+	return os.Stdout
+}
+
+// stderr returns the writer of the messages to stderr of the configuration.
+func stderr(cfg *config.Config) io.Writer {
+	if cfg.Stderr != nil {
+		return cfg.Stderr
+	}
+	return os.Stderr
+}
+
+// outputFormat returns the format of the configuration, inferred from the output file name when not set.
+func outputFormat(cfg *config.Config) string {
+	if cfg.Format == "" {
+		return config.FormatFromOutput(cfg.Output)
+	}
+	return cfg.Format
+}
+
+// outputFile is an output file written atomically: the writes go to a temporary file of the same directory,
+// created on the first write, which Commit renames to the output file.
+type outputFile struct {
+	name string
+	file *os.File
+}
+
+// Write creates the temporary file on the first call, then writes to it.
+func (f *outputFile) Write(p []byte) (int, error) {
+	if f.file == nil {
+		file, err := createTemp(f.name)
+		if err != nil {
+			return 0, fmt.Errorf("can't create %q: %v", f.name, err)
+		}
+		f.file = file
 	}
+	return f.file.Write(p)
+}
 
-	file, err := os.Create(cfg.Output)
+// createTemp creates a temporary file next to the given file, under a random name.
+// Unlike os.CreateTemp, which creates it with mode 0600, it lets the umask decide its mode as for any new file.
+func createTemp(name string) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".")
+	for try := 0; ; try++ {
+		file, err := os.OpenFile(prefix+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if errors.Is(err, fs.ErrExist) && try < 10000 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// Commit closes the temporary file and renames it to the output file, if it was created.
+// It keeps the mode of the file it replaces, if any.
+func (f *outputFile) Commit() error {
+	if f.file == nil {
+		return nil
+	}
+	file := f.file
+	f.file = nil
+	var err error
+	if info, statErr := os.Stat(f.name); statErr == nil {
+		err = file.Chmod(info.Mode().Perm())
+	}
+	err = errors.Join(err, file.Close())
+	if err == nil {
+		err = os.Rename(file.Name(), f.name)
+	}
 	if err != nil {
-		return fmt.Errorf("can't create %q: %v", cfg.Output, err)
+		os.Remove(file.Name())
+		return fmt.Errorf("can't write %q: %v", f.name, err)
+	}
+	return nil
+}
+
+// Close closes and removes the temporary file if it wasn't committed.
+func (f *outputFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return errors.Join(f.file.Close(), os.Remove(f.file.Name()))
+}
+
+// ReportTo generates a coverage report using the given configuration and writes it to the provided io.Writer,
+// whatever cfg.Output, so library users can target buffers, HTTP responses or compressed streams.
+// With cfg.SplitOutput, the pages of the HTML report are files written under that directory instead.
+// With cfg.GroupBy, it also prints a flat summary of the coverage by group to stdout.
+// With cfg.Summary, it then prints a summary line of the total coverage to stderr.
+// Stdout and stderr are cfg.Stdout and cfg.Stderr when set, warnings going to stderr too unless cfg.Quiet,
+// so concurrent calls each print to the writers of their own configuration.
+// With cfg.Manifest, the report is added to the manifest once written.
+// With cfg.Stats, it also prints the sizes of the report and the durations of its phases to stderr.
+// With cfg.DryRun, the report isn't written and the summary line is printed to stdout instead, whatever cfg.Summary.
+// It returns an error when the coverage doesn't meet the thresholds of the configuration.
+func ReportTo(cfg *config.Config, wr io.Writer) error {
+	return reportTo(cfg, wr, false, nil)
+}
+
+// reportTo is ReportTo, calling written once the report is completely written to the io.Writer, if not nil.
+// When toStdout tells the io.Writer is stdout, the group table goes to stderr instead, not to garble the report.
+func reportTo(cfg *config.Config, wr io.Writer, toStdout bool, written func() error) error {
+	if cfg.Quiet && cfg.Verbose {
+		return errors.New("quiet and verbose modes are mutually exclusive")
+	}
+
+	format := outputFormat(cfg)
+	if format == "" {
+		return fmt.Errorf("can't infer the format of %q from its extension, set it with -format", cfg.Output)
+	}
+	gp := newProject(cfg)
+
+	var report func(io.Writer) error
+	switch format {
+	case config.FormatHTML:
+		report = gp.Report
+	case config.FormatJSON:
+		report = gp.ReportJSON
+	case config.FormatMarkdown:
+		report = gp.ReportMarkdown
+	case config.FormatTreemap:
+		report = gp.ReportTreemap
+	case config.FormatSunburst:
+		report = gp.ReportSunburst
+	case config.FormatCSV:
+		report = gp.ReportCSV
+	case config.FormatBadge:
+		report = gp.ReportBadge
+	case config.FormatGitHubActions:
+		report = gp.ReportGitHubActions
+	case config.FormatText:
+		report = gp.ReportText
+	case config.FormatTeamCity:
+		report = gp.ReportTeamCity
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	switch cfg.GroupBy {
+	case "", config.GroupByTopDir, config.GroupByExt, config.GroupByComponent:
+	case config.GroupByOwner:
+		if cfg.CodeOwners == "" {
+			return fmt.Errorf("grouping by %s requires a codeowners file", config.GroupByOwner)
+		}
+	default:
+		return fmt.Errorf("unknown grouping %q", cfg.GroupBy)
+	}
+
+	split := cfg.SplitOutput != ""
+	if split && format != config.FormatHTML {
+		return fmt.Errorf("split output is only supported by the %s format", config.FormatHTML)
+	}
+
+	start := time.Now()
+	if err := parseProject(gp, cfg); err != nil {
+		return err
+	}
+	parsed := time.Now()
+
+	if split {
+		// Pages are written under cfg.SplitOutput instead of the writer.
+		report = func(io.Writer) error {
+			return gp.ReportSplit(cfg.SplitOutput)
+		}
+	}
+
+	if !cfg.DryRun {
+		if err := report(wr); err != nil {
+			return err
+		}
+		if written != nil {
+			if err := written(); err != nil {
+				return err
+			}
+		}
+		if cfg.Manifest != "" {
+			if err := appendManifest(cfg.Manifest, newManifestEntry(gp, cfg), gp.Logger); err != nil {
+				return err
+			}
+		}
+	}
+	rendered := time.Now()
+	if cfg.GroupBy != "" {
+		groupsOut := stdout(cfg)
+		if toStdout && !cfg.DryRun {
+			groupsOut = stderr(cfg)
+		}
+		if err := gp.ReportGroups(groupsOut, cfg.GroupBy); err != nil {
+			return err
+		}
+	}
+	switch {
+	case cfg.DryRun:
+		fmt.Fprintln(stdout(cfg), gp.SummaryLine())
+	case cfg.Summary:
+		fmt.Fprintln(stderr(cfg), gp.SummaryLine())
+	}
+	if cfg.Stats {
+		fmt.Fprintln(stderr(cfg), gp.Stats(parsed.Sub(start), rendered.Sub(parsed)))
+	}
+
+	err := gp.CheckThresholds(cfg.FailUnder, cfg.Thresholds)
+	if cfg.FailOnZero {
+		err = errors.Join(err, gp.CheckZeroCoverage())
+	}
+	return err
+}
+
+// newProject creates a GoProject with the options of the configuration.
+func newProject(cfg *config.Config) *internal.GoProject {
+	gp := internal.NewGoProject(cfg.Root, cfg.Cutlines, cfg.Ignores)
+	gp.Title = cfg.Title
+	gp.ScoreExcludes = cfg.ScoreExcludes
+	gp.GoOnly = cfg.GoOnly
+	gp.Colors = cfg.Colors
+	gp.LineBackgrounds = cfg.LineBackgrounds
+	gp.Remaps = cfg.Remaps
+	gp.LiveReload = cfg.Watch
+	gp.Layout = cfg.Layout
+	gp.PathStyle = cfg.PathStyle
+	gp.Sort = cfg.Sort
+	gp.Collapse = cfg.Collapse
+	gp.CollapseRoot = !cfg.NoCollapseRoot
+	if cfg.Precision != nil {
+		gp.Precision = *cfg.Precision
+	}
+	gp.MaxAnnotations = cfg.MaxAnnotations
+	gp.BadgeStyle = cfg.BadgeStyle
+	gp.Context = cfg.Context
+	gp.Lenient = cfg.Lenient
+	gp.AllowEmpty = cfg.AllowEmpty
+	gp.Strict = cfg.Strict
+	gp.SelfContained = cfg.SelfContained
+	gp.Minify = cfg.Minify
+	gp.StrictLines = cfg.StrictLines
+	gp.LinkUncovered = cfg.LinkUncovered
+	gp.Sidebar = cfg.Sidebar
+	gp.IncludeTests = cfg.IncludeTests
+	gp.External = cfg.External
+	gp.Constraints = cfg.Constraints
+	gp.GOOS = cfg.GOOS
+	gp.GOARCH = cfg.GOARCH
+	if cfg.Progress {
+		gp.Progress = internal.NewProgress(stderr(cfg))
+	}
+	gp.Logger = newLogger(cfg)
+	if cfg.CacheSources {
+		gp.Sources = internal.NewSourceCache()
+	}
+	gp.Sources.Retries = cfg.ReadRetries
+	gp.Sources.Logger = gp.Logger
+	return gp
+}
+
+// newLogger returns the Logger printing the diagnostics of the configuration to its stderr,
+// nothing with cfg.Quiet, and the debug messages too with cfg.Verbose.
+func newLogger(cfg *config.Config) *internal.Logger {
+	level := internal.LogNormal
+	switch {
+	case cfg.Quiet:
+		level = internal.LogQuiet
+	case cfg.Verbose:
+		level = internal.LogVerbose
+	}
+	return internal.NewLogger(stderr(cfg), level)
+}
+
+// parseProject parses the input profile of the configuration into the GoProject,
+// spanning the modules of the workspace of cfg.Workspace when set, restricting diff coverage to the lines changed since cfg.Diff when set,
+// comparing it with the baseline profile cfg.Compare when set, and hiding the fully covered files with cfg.OnlyIncomplete.
+func parseProject(gp *internal.GoProject, cfg *config.Config) error {
+	switch cfg.Constraints {
+	case "", config.ConstraintsAnnotate, config.ConstraintsExclude, config.ConstraintsIgnore:
+	default:
+		return fmt.Errorf("unknown constraints handling %q", cfg.Constraints)
+	}
+	switch cfg.External {
+	case "", config.ExternalSkip, config.ExternalInclude:
+	default:
+		return fmt.Errorf("unknown external handling %q", cfg.External)
+	}
+
+	if cfg.Workspace != "" {
+		if cfg.Root != "" && cfg.Root != "." {
+			return errors.New("a workspace spans the modules of its go.work file, it can't be restricted with a root")
+		}
+		modules, err := internal.LoadWorkspace(cfg.Workspace)
+		if err != nil {
+			return err
+		}
+		// Profiles of a workspace name their files by import path, each module becoming a top-level directory.
+		gp.Modules = modules
+		gp.RootPath = "."
 	}
-	defer file.Close()
 
-	if err := gp.Report(file); err != nil {
+	if cfg.RespectGitignore {
+		gitIgnore, err := internal.LoadGitIgnore(".")
+		if err != nil {
+			return err
+		}
+		gp.GitIgnore = gitIgnore
+	}
+
+	if cfg.CodeOwners != "" {
+		codeOwners, err := internal.LoadCodeOwners(cfg.CodeOwners)
+		if err != nil {
+			return err
+		}
+		gp.CodeOwners = codeOwners
+	}
+
+	if cfg.Diff != "" {
+		changed, err := internal.GitChangedLines(cfg.Diff)
+		if err != nil {
+			return err
+		}
+		gp.Changed = changed
+	}
+
+	input := cfg.Input
+	var testResults map[string]*internal.TestResult
+	switch cfg.InputFormat {
+	case "", config.InputFormatProfile:
+	case config.InputFormatJSON:
+		if cfg.Profile == "" {
+			return fmt.Errorf("the %s input format requires a coverage profile given with -profile", config.InputFormatJSON)
+		}
+		results, err := internal.ParseTestJSON(cfg.Input)
+		if err != nil {
+			return err
+		}
+		input, testResults = cfg.Profile, results
+	default:
+		return fmt.Errorf("unknown input format %q", cfg.InputFormat)
+	}
+
+	if err := gp.Parse(input); err != nil {
 		return err
 	}
+	gp.SetTestResults(testResults)
+
+	if cfg.Compare != "" {
+		base := newProject(cfg)
+		base.Modules = gp.Modules
+		base.RootPath = gp.RootPath
+		if err := base.ParseBaseline(cfg.Compare); err != nil {
+			return err
+		}
+		gp.Compare(base)
+	}
 
+	if cfg.OnlyIncomplete {
+		gp.Logger.Debugf("hid %d fully covered files", gp.PruneCovered())
+	}
 	return nil
 }
 
 // NewCLIConfig creates a new configuration based on the command-line arguments.
 func NewCLIConfig() (*config.Config, error) {
-	input := flag.String("i", "cover.prof", "input file name")
-	output := flag.String("o", "cover.html", "output file name")
-	cutlines := flag.String("cutlines", "70,40", "cutlines (safe,warning)")
-	root := flag.String("root", ".", "root package name")
-	ignores := flag.String("ignores", "", "ignore packages (comma separated)")
-	flag.Parse()
+	return NewFlagConfig(flag.CommandLine, os.Args[1:])
+}
+
+// NewFlagConfig creates a new configuration by parsing the arguments with the given flag set.
+// The COVREPORT_ environment variables, then the settings of the configuration file, fill in the flags
+// which were not set explicitly: flags take precedence over environment variables, which take precedence
+// over the configuration file, which takes precedence over the defaults.
+func NewFlagConfig(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	input := fs.String("i", "cover.prof", "input file name (- for stdin, gzip compressed inputs are decompressed, comma-separated for several profiles)")
+	inputFormat := fs.String("format-in", config.InputFormatProfile, "input format (profile, json for go test -json events with the coverage profile given with -profile)")
+	profile := fs.String("profile", "", "coverage profile of the go test -json input, whose events give the test results")
+	output := fs.String("o", "cover.html", "output file name")
+	manifest := fs.String("manifest", "", "json manifest indexing the reports, which the report is added to once written")
+	splitOutput := fs.String("split-output", "", "write the html report as a page per directory and file under this directory, instead of a single file")
+	cutlines := fs.String("cutlines", "70,40", "cutlines (safe,warning[,excellent])")
+	colors := fs.String("colors", "", "hex colors of the coverage classes (danger,warning,safe[,excellent]), default red,orange,green,dodgerblue")
+	lineBackgrounds := fs.String("line-bg", "", "backgrounds of the uncovered and covered lines (uncovered,covered), each an opacity, a hex color or both like #ff0000/0.25, default 0.4,0.4")
+	root := fs.String("root", ".", "root package name")
+	workspace := fs.String("workspace", "", "directory of the go.work file whose modules the report spans")
+	collapseRoot := fs.Bool("collapse-root", true, "start the report below the directories of the root having a single subdirectory and no files")
+	ignores := fs.String("ignores", "", "ignore packages (comma separated)")
+	scoreExcludes := fs.String("score-excludes", "", "show but leave out of the totals the files matching these path prefixes or globs, like *.pb.go (comma separated)")
+	respectGitignore := fs.Bool("respect-gitignore", false, "leave the files ignored by git out of the report")
+	external := fs.String("external", config.ExternalSkip, "handling of files outside of the root package (skip, include)")
+	var remaps []string
+	fs.Func("remap", "rewrite the leading path of the files of the profile, like /workspace=/home/me/app (repeatable)", func(remap string) error {
+		remaps = append(remaps, remap)
+		return nil
+	})
+	groupBy := fs.String("group-by", "", "print a flat coverage summary grouped by top-dir, ext, owner or component to stdout")
+	codeOwners := fs.String("codeowners", "", "CODEOWNERS file giving the owners shown in the views of the html report")
+	title := fs.String("title", "", "title of the html report, such as the project name")
+	format := fs.String("format", "", "output format (html, json, markdown, treemap, sunburst, csv, badge, github-actions, text, teamcity), inferred from the output file extension by default")
+	diff := fs.String("diff", "", "report coverage of lines changed since git revision")
+	compare := fs.String("compare", "", "baseline profile to compare the coverage with")
+	failUnder := fs.Float64("fail-under", 0, "minimum total coverage percentage")
+	thresholds := fs.String("thresholds", "", "yaml file of minimum coverage percentages by package path prefix")
+	failOnZero := fs.Bool("fail-on-zero", false, "fail when a file has no covered statement")
+	context := fs.Int("context", 0, "source lines printed around uncovered lines in the text format (0 to only list them)")
+	maxAnnotations := fs.Int("max-annotations", 0, "maximum number of github-actions annotations (0 for no limit)")
+	badgeStyle := fs.String("badge-style", config.BadgeStyleCompact, "badge text (compact for the percentage, full for the statement counts too)")
+	layout := fs.String("layout", config.LayoutDrilldown, "html layout (drilldown, tree, flat)")
+	pathStyle := fs.String("path-style", config.PathStyleBase, "paths shown in breadcrumbs and items (base, full)")
+	sort := fs.String("sort", config.SortWorst, "order of directory items (worst, best, name, uncovered)")
+	collapse := fs.Int("collapse", 0, "collapse runs of lines with the same coverage longer than this in file views (0 to disable)")
+	precision := fs.Int("precision", 1, "number of decimal places of percentages")
+	constraints := fs.String("constraints", config.ConstraintsAnnotate, "handling of files not built for goos/goarch (annotate, exclude, ignore)")
+	goos := fs.String("goos", build.Default.GOOS, "target operating system of the profile")
+	goarch := fs.String("goarch", build.Default.GOARCH, "target architecture of the profile")
+	includeTests := fs.Bool("include-tests", false, "count _test.go files in the coverage")
+	selfContained := fs.Bool("self-contained", false, "embed the input profile as json in the html report")
+	strictLines := fs.Bool("strict-lines", false, "only show lines as covered when every block overlapping them ran")
+	linkUncovered := fs.Bool("link-uncovered", false, "link the files listed in the html report to their first uncovered line")
+	onlyIncomplete := fs.Bool("only-incomplete", false, "hide fully covered files, and the directories left empty, from the report")
+	sidebar := fs.Bool("sidebar", false, "show a navigation pane listing every directory in the html report")
+	minify := fs.Bool("minify", false, "strip unnecessary whitespace from the html report")
+	allowEmpty := fs.Bool("allow-empty", false, "report profiles without coverage data instead of failing")
+	strict := fs.Bool("strict", false, "fail instead of warning when the profile references lines past the end of a source file")
+	quiet := fs.Bool("quiet", false, "don't print the coverage summary line nor warnings to stderr")
+	verbose := fs.Bool("v", false, "print debug messages about the files read and the profile blocks to stderr")
+	progress := fs.Bool("progress", false, "print the progress of parsing the profile and rendering the files to stderr")
+	goOnly := fs.Bool("go-only", false, "leave the files of other languages than go, such as the c and assembly files of cgo packages, out of the totals")
+	stats := fs.Bool("stats", false, "print the number of blocks, files, directories and statements, and the parse and render times to stderr")
+	cacheSources := fs.Bool("cache-sources", false, "keep source files in memory once read instead of reading them every time they are needed")
+	readRetries := fs.Int("read-retries", 0, "number of times a failed read of a source file is tried again with a backoff, for flaky network filesystems")
+	dryRun := fs.Bool("dry-run", false, "parse the profile and print the coverage summary to stdout without writing the report")
+	watch := fs.Bool("watch", false, "regenerate the report whenever the profile changes, the html report reloading itself in the browser")
+	watchInterval := fs.Duration("watch-interval", defaultWatchInterval, "interval between the checks of the profile in watch mode")
+	lenient := fs.Bool("lenient", false, "skip malformed profile lines with a warning instead of failing")
+	configFile := fs.String("config", "", "configuration file name (default "+config.DefaultFile+" if it exists)")
+	fs.Usage = usage(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	file, err := loadConfigFile(*configFile)
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, settings := range []*config.File{config.LoadEnv(), file} {
+		if settings == nil {
+			continue
+		}
+		fill := func(name string, value *string, setting string) {
+			if !set[name] && setting != "" {
+				*value = setting
+				set[name] = true
+			}
+		}
+		fill("i", input, settings.Input)
+		fill("o", output, settings.Output)
+		fill("cutlines", cutlines, settings.Cutlines)
+		fill("root", root, settings.Root)
+		fill("ignores", ignores, strings.Join(settings.Ignores, ","))
+	}
 
 	parsedCutlines, err := ParseCutlines(*cutlines)
 	if err != nil {
 		return nil, err
 	}
 
+	parsedColors, err := ParseColors(*colors)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedLineBackgrounds, err := ParseLineBackgrounds(*lineBackgrounds)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedRemaps, err := ParseRemaps(remaps)
+	if err != nil {
+		return nil, err
+	}
+
+	if *precision < 0 {
+		return nil, fmt.Errorf("invalid precision %d", *precision)
+	}
+
+	var parsedThresholds map[string]float64
+	if *thresholds != "" {
+		if parsedThresholds, err = LoadThresholds(*thresholds); err != nil {
+			return nil, err
+		}
+	}
+
 	return &config.Config{
-		Input:    *input,
-		Output:   *output,
-		Cutlines: parsedCutlines,
-		Root:     *root,
-		Ignores:  ParseIgnores(*ignores),
+		Input:       *input,
+		InputFormat: *inputFormat,
+		Profile:     *profile,
+		Output:      *output,
+		SplitOutput: *splitOutput,
+		Manifest:    *manifest,
+		Format:      *format,
+
+		Root:             *root,
+		Workspace:        *workspace,
+		Ignores:          ParseIgnores(*ignores),
+		ScoreExcludes:    ParseIgnores(*scoreExcludes),
+		GoOnly:           *goOnly,
+		External:         *external,
+		Remaps:           parsedRemaps,
+		RespectGitignore: *respectGitignore,
+		CodeOwners:       *codeOwners,
+		IncludeTests:     *includeTests,
+		Constraints:      *constraints,
+		GOOS:             *goos,
+		GOARCH:           *goarch,
+
+		Title:           *title,
+		Cutlines:        parsedCutlines,
+		Colors:          parsedColors,
+		LineBackgrounds: parsedLineBackgrounds,
+		Layout:          *layout,
+		PathStyle:       *pathStyle,
+		Sort:            *sort,
+		Collapse:        *collapse,
+		NoCollapseRoot:  !*collapseRoot,
+		Precision:       precision,
+		GroupBy:         *groupBy,
+		OnlyIncomplete:  *onlyIncomplete,
+		SelfContained:   *selfContained,
+		Minify:          *minify,
+		StrictLines:     *strictLines,
+		LinkUncovered:   *linkUncovered,
+		Sidebar:         *sidebar,
+		MaxAnnotations:  *maxAnnotations,
+		BadgeStyle:      *badgeStyle,
+		Context:         *context,
+
+		Diff:       *diff,
+		Compare:    *compare,
+		FailUnder:  *failUnder,
+		Thresholds: parsedThresholds,
+		FailOnZero: *failOnZero,
+
+		Lenient:       *lenient,
+		AllowEmpty:    *allowEmpty,
+		Strict:        *strict,
+		Summary:       !*quiet,
+		Quiet:         *quiet,
+		Verbose:       *verbose,
+		Progress:      *progress,
+		Stats:         *stats,
+		CacheSources:  *cacheSources,
+		ReadRetries:   *readRetries,
+		DryRun:        *dryRun,
+		Watch:         *watch,
+		WatchInterval: *watchInterval,
 	}, nil
 }
 
-// ParseCutlines parses the cutlines argument.
+// loadConfigFile loads the given configuration file, or the default one if it exists.
+// It returns nil when there is no configuration file to load.
+func loadConfigFile(filename string) (*config.File, error) {
+	if filename == "" {
+		if _, err := os.Stat(config.DefaultFile); err != nil {
+			return nil, nil
+		}
+		filename = config.DefaultFile
+	}
+	return config.LoadFile(filename)
+}
+
+// ParseCutlines parses the cutlines argument: safe,warning with an optional excellent cutline.
+// A single value is used as both the safe and the warning cutlines.
 func ParseCutlines(cutlines string) (*config.Cutlines, error) {
 	frags := strings.Split(cutlines, ",")
+	if len(frags) > 3 {
+		return nil, fmt.Errorf("invalid cutlines %q, expected safe,warning[,excellent]", cutlines)
+	}
 	safe, err := strconv.ParseFloat(frags[0], 64)
 	if err != nil {
 		return nil, err
 	}
-	warning, err := strconv.ParseFloat(frags[len(frags)-1], 64)
+	warning, err := strconv.ParseFloat(frags[min(len(frags), 2)-1], 64)
 	if err != nil {
 		return nil, err
 	}
+	var excellent float64
+	if len(frags) == 3 {
+		if excellent, err = strconv.ParseFloat(frags[2], 64); err != nil {
+			return nil, err
+		}
+	}
 
 	return &config.Cutlines{
-		Safe:    safe,
-		Warning: warning,
+		Safe:      safe,
+		Warning:   warning,
+		Excellent: excellent,
 	}, nil
 }
 
+// hexColorRe matches the hex codes of colors, such as #f00 or #ff0000.
+var hexColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ParseColors parses the colors argument, returning nil when it is empty.
+func ParseColors(colors string) (*config.Colors, error) {
+	if colors == "" {
+		return nil, nil
+	}
+	frags := strings.Split(colors, ",")
+	if len(frags) != 3 && len(frags) != 4 {
+		return nil, fmt.Errorf("invalid colors %q, expected danger,warning,safe[,excellent]", colors)
+	}
+	for _, frag := range frags {
+		if !hexColorRe.MatchString(frag) {
+			return nil, fmt.Errorf("invalid color %q", frag)
+		}
+	}
+
+	result := &config.Colors{
+		Danger:  frags[0],
+		Warning: frags[1],
+		Safe:    frags[2],
+	}
+	if len(frags) == 4 {
+		result.Excellent = frags[3]
+	}
+	return result, nil
+}
+
+// defaultLineOpacity is the opacity of the backgrounds of lines given a color only.
+const defaultLineOpacity = 0.4
+
+// ParseLineBackgrounds parses the line backgrounds argument, returning nil when it is empty.
+// It holds the uncovered and covered backgrounds, each an opacity, a hex color, or a hex color and an opacity
+// separated by a slash.
+func ParseLineBackgrounds(backgrounds string) (*config.LineBackgrounds, error) {
+	if backgrounds == "" {
+		return nil, nil
+	}
+	frags := strings.Split(backgrounds, ",")
+	if len(frags) != 2 {
+		return nil, fmt.Errorf("invalid line backgrounds %q, expected uncovered,covered", backgrounds)
+	}
+
+	parsed := make([]config.LineBackground, len(frags))
+	for i, frag := range frags {
+		color, opacity, hasOpacity := strings.Cut(frag, "/")
+		if !hasOpacity && !strings.HasPrefix(frag, "#") {
+			color, opacity, hasOpacity = "", frag, true
+		}
+		if color != "" && !hexColorRe.MatchString(color) {
+			return nil, fmt.Errorf("invalid color %q", color)
+		}
+		parsed[i] = config.LineBackground{Color: color, Opacity: defaultLineOpacity}
+		if hasOpacity {
+			value, err := strconv.ParseFloat(opacity, 64)
+			if err != nil || value < 0 || value > 1 {
+				return nil, fmt.Errorf("invalid opacity %q, expected a number between 0 and 1", opacity)
+			}
+			parsed[i].Opacity = value
+		}
+	}
+	return &config.LineBackgrounds{Uncovered: parsed[0], Covered: parsed[1]}, nil
+}
+
+// ParseRemaps parses the remap arguments, each rewriting a leading path to another one like from=to.
+func ParseRemaps(remaps []string) ([]config.PathRemap, error) {
+	var result []config.PathRemap
+	for _, remap := range remaps {
+		from, to, ok := strings.Cut(remap, "=")
+		if !ok || from == "" {
+			return nil, fmt.Errorf("invalid remap %q, expected from=to", remap)
+		}
+		result = append(result, config.PathRemap{From: from, To: to})
+	}
+	return result, nil
+}
+
 // ParseIgnores parses the ignores argument.
 func ParseIgnores(ignores string) []string {
 	if ignores == "" {
@@ -80,3 +727,17 @@ func ParseIgnores(ignores string) []string {
 	}
 	return strings.Split(ignores, ",")
 }
+
+// LoadThresholds reads a yaml file mapping package path prefixes to minimum coverage percentages.
+func LoadThresholds(filename string) (map[string]float64, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %q: %v", filename, err)
+	}
+
+	var thresholds map[string]float64
+	if err := yaml.Unmarshal(data, &thresholds); err != nil {
+		return nil, fmt.Errorf("can't parse %q: %v", filename, err)
+	}
+	return thresholds, nil
+}
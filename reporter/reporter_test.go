@@ -1,9 +1,17 @@
 package reporter_test
 
 import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/drappier-charles/covreport/reporter"
+	"github.com/drappier-charles/covreport/reporter/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,10 +51,23 @@ func TestParseCutlines(t *testing.T) {
 		assert.Equal(t, 3.0, cutlines.Safe)
 		assert.Equal(t, 5.0, cutlines.Warning)
 
-		cutlines, err = reporter.ParseCutlines("3,5,7")
+		assert.Equal(t, 0.0, cutlines.Excellent)
+	})
+
+	t.Run("should return third number as excellent cut", func(t *testing.T) {
+		cutlines, err := reporter.ParseCutlines("70,40,95")
 		assert.NoError(t, err)
-		assert.Equal(t, 3.0, cutlines.Safe)
-		assert.Equal(t, 7.0, cutlines.Warning)
+		assert.Equal(t, 70.0, cutlines.Safe)
+		assert.Equal(t, 40.0, cutlines.Warning)
+		assert.Equal(t, 95.0, cutlines.Excellent)
+
+		_, err = reporter.ParseCutlines("70,40,not-a-number")
+		assert.ErrorContains(t, err, "invalid syntax")
+	})
+
+	t.Run("should return error with more than three cuts", func(t *testing.T) {
+		_, err := reporter.ParseCutlines("70,40,95,99")
+		assert.EqualError(t, err, `invalid cutlines "70,40,95,99", expected safe,warning[,excellent]`)
 	})
 }
 
@@ -65,6 +86,91 @@ func TestParseIgnores(t *testing.T) {
 	})
 }
 
+func TestParseLineBackgrounds(t *testing.T) {
+	t.Run("should return nil with empty string", func(t *testing.T) {
+		backgrounds, err := reporter.ParseLineBackgrounds("")
+		assert.NoError(t, err)
+		assert.Nil(t, backgrounds)
+	})
+
+	t.Run("should parse opacities and colors", func(t *testing.T) {
+		backgrounds, err := reporter.ParseLineBackgrounds("0.25,#0072b2")
+		assert.NoError(t, err)
+		assert.Equal(t, &config.LineBackgrounds{
+			Uncovered: config.LineBackground{Opacity: 0.25},
+			Covered:   config.LineBackground{Color: "#0072b2", Opacity: 0.4},
+		}, backgrounds)
+
+		backgrounds, err = reporter.ParseLineBackgrounds("#d55e00/0.6,1")
+		assert.NoError(t, err)
+		assert.Equal(t, config.LineBackground{Color: "#d55e00", Opacity: 0.6}, backgrounds.Uncovered)
+		assert.Equal(t, config.LineBackground{Opacity: 1}, backgrounds.Covered)
+	})
+
+	t.Run("should return error without two backgrounds", func(t *testing.T) {
+		_, err := reporter.ParseLineBackgrounds("0.2")
+		assert.EqualError(t, err, `invalid line backgrounds "0.2", expected uncovered,covered`)
+	})
+
+	t.Run("should return error with invalid values", func(t *testing.T) {
+		_, err := reporter.ParseLineBackgrounds("red/0.2,0.4")
+		assert.EqualError(t, err, `invalid color "red"`)
+
+		_, err = reporter.ParseLineBackgrounds("0.2,#0f0/1.5")
+		assert.EqualError(t, err, `invalid opacity "1.5", expected a number between 0 and 1`)
+
+		_, err = reporter.ParseLineBackgrounds("0.2;},0.4")
+		assert.EqualError(t, err, `invalid opacity "0.2;}", expected a number between 0 and 1`)
+	})
+}
+
+func TestParseRemaps(t *testing.T) {
+	t.Run("should parse the remaps in order", func(t *testing.T) {
+		remaps, err := reporter.ParseRemaps([]string{"/workspace/app=/home/me/app", "/ci=."})
+		assert.NoError(t, err)
+		assert.Equal(t, []config.PathRemap{{From: "/workspace/app", To: "/home/me/app"}, {From: "/ci", To: "."}}, remaps)
+	})
+
+	t.Run("should return error without from", func(t *testing.T) {
+		_, err := reporter.ParseRemaps([]string{"/workspace"})
+		assert.EqualError(t, err, `invalid remap "/workspace", expected from=to`)
+
+		_, err = reporter.ParseRemaps([]string{"=/home/me"})
+		assert.EqualError(t, err, `invalid remap "=/home/me", expected from=to`)
+	})
+}
+
+func TestParseColors(t *testing.T) {
+	t.Run("should return nil with empty string", func(t *testing.T) {
+		colors, err := reporter.ParseColors("")
+		assert.NoError(t, err)
+		assert.Nil(t, colors)
+	})
+
+	t.Run("should work", func(t *testing.T) {
+		colors, err := reporter.ParseColors("#d55e00,#f0e442,#0072B2")
+		assert.NoError(t, err)
+		assert.Equal(t, &config.Colors{Danger: "#d55e00", Warning: "#f0e442", Safe: "#0072B2"}, colors)
+
+		colors, err = reporter.ParseColors("#d55e00,#f0e442,#009e73,#0072b2")
+		assert.NoError(t, err)
+		assert.Equal(t, "#0072b2", colors.Excellent)
+	})
+
+	t.Run("should return error without three colors", func(t *testing.T) {
+		_, err := reporter.ParseColors("#f00,#ff0")
+		assert.EqualError(t, err, `invalid colors "#f00,#ff0", expected danger,warning,safe[,excellent]`)
+	})
+
+	t.Run("should return error with invalid hex", func(t *testing.T) {
+		_, err := reporter.ParseColors("#f00,yellow,#0f0")
+		assert.EqualError(t, err, `invalid color "yellow"`)
+
+		_, err = reporter.ParseColors("#f00,#ff0;},#0f0")
+		assert.EqualError(t, err, `invalid color "#ff0;}"`)
+	})
+}
+
 func TestNewCLIConfig(t *testing.T) {
 	t.Run("should have valid default values", func(t *testing.T) {
 		cfg, err := reporter.NewCLIConfig()
@@ -77,3 +183,459 @@ func TestNewCLIConfig(t *testing.T) {
 		assert.Equal(t, ".", cfg.Root)
 	})
 }
+
+func TestReport(t *testing.T) {
+	t.Run("should return error with unknown format", func(t *testing.T) {
+		err := reporter.Report(&config.Config{Format: "unknown"})
+		assert.ErrorContains(t, err, `unknown format "unknown"`)
+	})
+
+	t.Run("should return error with split output of another format than html", func(t *testing.T) {
+		err := reporter.Report(&config.Config{Format: config.FormatJSON, SplitOutput: t.TempDir()})
+		assert.EqualError(t, err, "split output is only supported by the html format")
+	})
+
+	t.Run("should infer the format from the output file extension", func(t *testing.T) {
+		err := reporter.Report(&config.Config{Output: "cover.json", SplitOutput: t.TempDir()})
+		assert.EqualError(t, err, "split output is only supported by the html format")
+	})
+
+	t.Run("should return error with an output file extension implying no format", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "lcov.info")
+		err := reporter.Report(&config.Config{Output: output})
+		assert.EqualError(t, err, fmt.Sprintf("can't infer the format of %q from its extension, set it with -format", output))
+		assert.NoFileExists(t, output)
+	})
+
+	t.Run("should return error with unknown grouping", func(t *testing.T) {
+		err := reporter.Report(&config.Config{GroupBy: "depth"})
+		assert.EqualError(t, err, `unknown grouping "depth"`)
+	})
+
+	t.Run("should return error when grouping by owner without codeowners", func(t *testing.T) {
+		err := reporter.Report(&config.Config{GroupBy: config.GroupByOwner})
+		assert.EqualError(t, err, "grouping by owner requires a codeowners file")
+	})
+
+	t.Run("should return error when both quiet and verbose", func(t *testing.T) {
+		err := reporter.Report(&config.Config{Quiet: true, Verbose: true})
+		assert.EqualError(t, err, "quiet and verbose modes are mutually exclusive")
+	})
+
+	t.Run("should print the group table to stderr when the report goes to stdout", func(t *testing.T) {
+		temp := t.TempDir()
+		source := filepath.Join(temp, "x.go")
+		assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+		input := filepath.Join(temp, "cover.prof")
+		assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 0\n", source)), 0o644))
+
+		var stdout, stderr bytes.Buffer
+		err := reporter.Report(&config.Config{
+			Input:    input,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Format:   config.FormatText,
+			GroupBy:  config.GroupByExt,
+			Quiet:    true,
+			Stdout:   &stdout,
+			Stderr:   &stderr,
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, stdout.String(), "x.go")
+		assert.NotContains(t, stdout.String(), "GROUP")
+		assert.Contains(t, stderr.String(), "GROUP")
+	})
+
+	t.Run("should return error when restricting a workspace with a root", func(t *testing.T) {
+		err := reporter.Report(&config.Config{Workspace: t.TempDir(), Root: "example.com/api", Quiet: true})
+		assert.EqualError(t, err, "a workspace spans the modules of its go.work file, it can't be restricted with a root")
+	})
+}
+
+func TestReportTo(t *testing.T) {
+	temp := t.TempDir()
+	source := filepath.Join(temp, "x.go")
+	assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+	input := filepath.Join(temp, "cover.prof")
+	assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+
+	t.Run("should write the report to the writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := reporter.ReportTo(&config.Config{
+			Input:    input,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Format:   config.FormatBadge,
+			Quiet:    true,
+		}, &buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "<title>coverage: 100.0%</title>")
+	})
+
+	t.Run("should print the messages to the writers of the configuration", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := reporter.ReportTo(&config.Config{
+			Input:    input,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Format:   config.FormatBadge,
+			GroupBy:  config.GroupByExt,
+			Summary:  true,
+			Stats:    true,
+			Stdout:   &stdout,
+			Stderr:   &stderr,
+		}, io.Discard)
+		assert.NoError(t, err)
+		assert.Regexp(t, `\.go +100\.0% +1/1`, stdout.String())
+		assert.Contains(t, stderr.String(), "COVERAGE: 100.0% (1/1 statements)")
+		assert.Contains(t, stderr.String(), "STATS: 1 blocks, 1 files")
+	})
+
+	t.Run("should print the warnings to the stderr of the configuration", func(t *testing.T) {
+		malformed := filepath.Join(temp, "malformed.prof")
+		assert.NoError(t, os.WriteFile(malformed, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\ngarbage\n", source)), 0o644))
+		var stderr bytes.Buffer
+		err := reporter.ReportTo(&config.Config{
+			Input:    malformed,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Format:   config.FormatBadge,
+			Lenient:  true,
+			Stderr:   &stderr,
+		}, io.Discard)
+		assert.NoError(t, err)
+		assert.Contains(t, stderr.String(), "warning: skipping malformed profile line 3")
+		assert.NotContains(t, stderr.String(), "COVERAGE:")
+	})
+
+	t.Run("should keep the warnings of concurrent reports apart", func(t *testing.T) {
+		names := []string{"first", "second"}
+		stderrs := make([]bytes.Buffer, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			malformed := filepath.Join(temp, name+".prof")
+			assert.NoError(t, os.WriteFile(malformed, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n%s\n", source, name)), 0o644))
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				err := reporter.ReportTo(&config.Config{
+					Input:    malformed,
+					Root:     temp,
+					Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+					Format:   config.FormatBadge,
+					Lenient:  true,
+					Stderr:   &stderrs[i],
+				}, io.Discard)
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+		assert.Contains(t, stderrs[0].String(), `skipping malformed profile line 3: "first"`)
+		assert.NotContains(t, stderrs[0].String(), "second")
+		assert.Contains(t, stderrs[1].String(), `skipping malformed profile line 3: "second"`)
+		assert.NotContains(t, stderrs[1].String(), "first")
+	})
+
+	t.Run("should not write the report in dry-run mode", func(t *testing.T) {
+		output := filepath.Join(temp, "dry-run.html")
+		err := reporter.Report(&config.Config{
+			Input:     input,
+			Output:    output,
+			Root:      temp,
+			Cutlines:  &config.Cutlines{Safe: 70, Warning: 40},
+			DryRun:    true,
+			FailUnder: 100,
+		})
+		assert.NoError(t, err)
+		assert.NoFileExists(t, output)
+	})
+
+	t.Run("should not create the output file when the profile can't be parsed", func(t *testing.T) {
+		output := filepath.Join(temp, "cover.html")
+		err := reporter.Report(&config.Config{Input: filepath.Join(temp, "not-exist.prof"), Output: output, Quiet: true})
+		assert.Error(t, err)
+		assert.NoFileExists(t, output)
+	})
+
+	t.Run("should replace the output file once the report is written", func(t *testing.T) {
+		dir := t.TempDir()
+		output := filepath.Join(dir, "cover.html")
+		assert.NoError(t, os.WriteFile(output, []byte("previous"), 0o600))
+		err := reporter.Report(&config.Config{
+			Input:     input,
+			Output:    output,
+			Root:      temp,
+			Cutlines:  &config.Cutlines{Safe: 70, Warning: 40},
+			Quiet:     true,
+			FailUnder: 101,
+		})
+		assert.ErrorContains(t, err, "coverage")
+		content, err := os.ReadFile(output)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "<!DOCTYPE html>")
+		info, err := os.Stat(output)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("should create the output file with the mode of new files", func(t *testing.T) {
+		dir := t.TempDir()
+		output := filepath.Join(dir, "cover.html")
+		err := reporter.Report(&config.Config{
+			Input:    input,
+			Output:   output,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+		})
+		assert.NoError(t, err)
+		reference, err := os.OpenFile(filepath.Join(dir, "reference"), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		assert.NoError(t, err)
+		assert.NoError(t, reference.Close())
+		want, err := os.Stat(reference.Name())
+		assert.NoError(t, err)
+		info, err := os.Stat(output)
+		assert.NoError(t, err)
+		assert.Equal(t, want.Mode().Perm(), info.Mode().Perm())
+	})
+
+	t.Run("should leave the output file untouched when the report fails", func(t *testing.T) {
+		dir := t.TempDir()
+		missing := filepath.Join(dir, "missing.prof")
+		assert.NoError(t, os.WriteFile(missing, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", filepath.Join(dir, "missing.go"))), 0o644))
+		output := filepath.Join(dir, "cover.html")
+		assert.NoError(t, os.WriteFile(output, []byte("previous"), 0o644))
+		err := reporter.Report(&config.Config{
+			Input:    missing,
+			Output:   output,
+			Root:     dir,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Quiet:    true,
+		})
+		assert.ErrorContains(t, err, "can't read")
+		content, err := os.ReadFile(output)
+		assert.NoError(t, err)
+		assert.Equal(t, "previous", string(content))
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+}
+
+func TestReportCompare(t *testing.T) {
+	temp := t.TempDir()
+	source := filepath.Join(temp, "x.go")
+	assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+	input := filepath.Join(temp, "cover.prof")
+	assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+	base := filepath.Join(temp, "base.prof")
+	removed := filepath.Join(temp, "removed", "y.go")
+	assert.NoError(t, os.WriteFile(base, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 0\n%s:1.1,9.2 4 1\n", source, removed)), 0o644))
+
+	t.Run("should compare with a baseline of removed packages", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := reporter.ReportTo(&config.Config{
+			Input:    input,
+			Compare:  base,
+			Root:     temp,
+			Cutlines: &config.Cutlines{Safe: 70, Warning: 40},
+			Strict:   true,
+			Quiet:    true,
+		}, &buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "<summary>1 removed files</summary>")
+		assert.Contains(t, buf.String(), "<li>"+filepath.ToSlash(removed)+"</li>")
+		assert.Contains(t, buf.String(), `<span class="delta up">+100.0%</span>`)
+	})
+}
+
+func TestReportTestJSON(t *testing.T) {
+	temp := t.TempDir()
+	source := filepath.Join(temp, "x.go")
+	assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+	profile := filepath.Join(temp, "cover.prof")
+	assert.NoError(t, os.WriteFile(profile, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 1\n", source)), 0o644))
+	events := filepath.Join(temp, "events.json")
+	assert.NoError(t, os.WriteFile(events, []byte(fmt.Sprintf(
+		`{"Action":"pass","Package":%[1]q,"Test":"TestF"}`+"\n"+`{"Action":"fail","Package":%[1]q,"Test":"TestG"}`+"\n",
+		filepath.ToSlash(temp))), 0o644))
+
+	t.Run("should show the test results with the coverage of the profile", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := reporter.ReportTo(&config.Config{
+			Input:       events,
+			InputFormat: config.InputFormatJSON,
+			Profile:     profile,
+			Compare:     profile,
+			Root:        temp,
+			Cutlines:    &config.Cutlines{Safe: 70, Warning: 40},
+			Quiet:       true,
+		}, &buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `<div class="note tests warning" title="go test results">tests: 1 passed, 1 failed</div>`)
+	})
+
+	t.Run("should return error without profile", func(t *testing.T) {
+		err := reporter.ReportTo(&config.Config{Input: events, InputFormat: config.InputFormatJSON, Quiet: true}, io.Discard)
+		assert.EqualError(t, err, "the json input format requires a coverage profile given with -profile")
+	})
+
+	t.Run("should return error with an unknown input format", func(t *testing.T) {
+		err := reporter.ReportTo(&config.Config{Input: profile, InputFormat: "xml", Quiet: true}, io.Discard)
+		assert.EqualError(t, err, `unknown input format "xml"`)
+	})
+}
+
+func TestLoadThresholds(t *testing.T) {
+	t.Run("should return error when cannot read file", func(t *testing.T) {
+		_, err := reporter.LoadThresholds("not-exist.yaml")
+		assert.ErrorContains(t, err, `can't read "not-exist.yaml"`)
+	})
+
+	t.Run("should parse prefixes and minimums", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "thresholds.yaml")
+		err := os.WriteFile(filename, []byte("app/internal/crypto: 90\napp/experimental: 20.5\n"), 0o644)
+		assert.NoError(t, err)
+
+		thresholds, err := reporter.LoadThresholds(filename)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"app/internal/crypto": 90, "app/experimental": 20.5}, thresholds)
+	})
+
+	t.Run("should return error when cannot parse file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "thresholds.yaml")
+		err := os.WriteFile(filename, []byte("app: high\n"), 0o644)
+		assert.NoError(t, err)
+
+		_, err = reporter.LoadThresholds(filename)
+		assert.ErrorContains(t, err, "can't parse")
+	})
+}
+
+func TestNewFlagConfig(t *testing.T) {
+	newFlagSet := func() *flag.FlagSet {
+		fs := flag.NewFlagSet("covreport", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return fs
+	}
+
+	t.Run("should parse flags", func(t *testing.T) {
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), []string{"-i", "a.prof", "-cutlines", "80,50", "-ignores", "a,b"})
+		assert.NoError(t, err)
+		assert.Equal(t, "a.prof", cfg.Input)
+		assert.Equal(t, "cover.html", cfg.Output)
+		assert.Equal(t, 80.0, cfg.Cutlines.Safe)
+		assert.Equal(t, []string{"a", "b"}, cfg.Ignores)
+	})
+
+	t.Run("should print the usage with examples and formats", func(t *testing.T) {
+		var out bytes.Buffer
+		fs := flag.NewFlagSet("covreport", flag.ContinueOnError)
+		fs.SetOutput(&out)
+		_, err := reporter.NewFlagConfig(fs, []string{"-h"})
+		assert.ErrorIs(t, err, flag.ErrHelp)
+		assert.Contains(t, out.String(), "Usage: covreport [flags]")
+		assert.Contains(t, out.String(), "covreport -i cover.prof -o coverage.json")
+		assert.Regexp(t, `\n  markdown +\.md +summary table`, out.String())
+		assert.Regexp(t, `\n  text +uncovered lines`, out.String())
+		assert.Contains(t, out.String(), "-fail-under float")
+	})
+
+	t.Run("should print the usage with unknown flags", func(t *testing.T) {
+		var out bytes.Buffer
+		fs := flag.NewFlagSet("covreport", flag.ContinueOnError)
+		fs.SetOutput(&out)
+		_, err := reporter.NewFlagConfig(fs, []string{"-unknown"})
+		assert.ErrorContains(t, err, "flag provided but not defined: -unknown")
+		assert.Contains(t, out.String(), "Formats, set with -format")
+	})
+
+	t.Run("should repeat remaps", func(t *testing.T) {
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), []string{"-remap", "/workspace/app=/home/me/app", "-remap", "/ci=."})
+		assert.NoError(t, err)
+		assert.Equal(t, []config.PathRemap{{From: "/workspace/app", To: "/home/me/app"}, {From: "/ci", To: "."}}, cfg.Remaps)
+	})
+
+	t.Run("should default precision to one decimal", func(t *testing.T) {
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, *cfg.Precision)
+	})
+
+	t.Run("should keep the root apart from the workspace", func(t *testing.T) {
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), []string{"-workspace", "."})
+		assert.NoError(t, err)
+		assert.Equal(t, ".", cfg.Root)
+		assert.Equal(t, ".", cfg.Workspace)
+	})
+
+	t.Run("should collapse the root by default", func(t *testing.T) {
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), nil)
+		assert.NoError(t, err)
+		assert.False(t, cfg.NoCollapseRoot)
+
+		cfg, err = reporter.NewFlagConfig(newFlagSet(), []string{"-collapse-root=false"})
+		assert.NoError(t, err)
+		assert.True(t, cfg.NoCollapseRoot)
+	})
+
+	t.Run("should print the summary line unless quiet", func(t *testing.T) {
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), nil)
+		assert.NoError(t, err)
+		assert.True(t, cfg.Summary)
+
+		cfg, err = reporter.NewFlagConfig(newFlagSet(), []string{"-quiet"})
+		assert.NoError(t, err)
+		assert.False(t, cfg.Summary)
+		assert.True(t, cfg.Quiet)
+	})
+
+	t.Run("should return error with negative precision", func(t *testing.T) {
+		_, err := reporter.NewFlagConfig(newFlagSet(), []string{"-precision", "-1"})
+		assert.EqualError(t, err, "invalid precision -1")
+	})
+
+	t.Run("should return error with unknown flag", func(t *testing.T) {
+		_, err := reporter.NewFlagConfig(newFlagSet(), []string{"-unknown"})
+		assert.ErrorContains(t, err, "flag provided but not defined: -unknown")
+	})
+
+	t.Run("should fill unset flags from configuration file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "covreport.yaml")
+		err := os.WriteFile(filename, []byte("input: file.prof\noutput: file.html\ncutlines: 90,60\nroot: app\nignores: [app/gen, app/mock]\n"), 0o644)
+		assert.NoError(t, err)
+
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), []string{"-config", filename, "-o", "flag.html", "-root", "."})
+		assert.NoError(t, err)
+		assert.Equal(t, "file.prof", cfg.Input)
+		assert.Equal(t, "flag.html", cfg.Output)
+		assert.Equal(t, 90.0, cfg.Cutlines.Safe)
+		assert.Equal(t, 60.0, cfg.Cutlines.Warning)
+		assert.Equal(t, ".", cfg.Root)
+		assert.Equal(t, []string{"app/gen", "app/mock"}, cfg.Ignores)
+	})
+
+	t.Run("should fill unset flags from environment variables before configuration file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "covreport.yaml")
+		err := os.WriteFile(filename, []byte("input: file.prof\noutput: file.html\ncutlines: 90,60\n"), 0o644)
+		assert.NoError(t, err)
+		t.Setenv("COVREPORT_INPUT", "env.prof")
+		t.Setenv("COVREPORT_OUTPUT", "env.html")
+		t.Setenv("COVREPORT_IGNORES", "app/gen,app/mock")
+
+		cfg, err := reporter.NewFlagConfig(newFlagSet(), []string{"-config", filename, "-o", "flag.html"})
+		assert.NoError(t, err)
+		assert.Equal(t, "env.prof", cfg.Input)
+		assert.Equal(t, "flag.html", cfg.Output)
+		assert.Equal(t, 90.0, cfg.Cutlines.Safe)
+		assert.Equal(t, ".", cfg.Root)
+		assert.Equal(t, []string{"app/gen", "app/mock"}, cfg.Ignores)
+	})
+
+	t.Run("should return error when configuration file is missing", func(t *testing.T) {
+		_, err := reporter.NewFlagConfig(newFlagSet(), []string{"-config", "not-exist.yaml"})
+		assert.ErrorContains(t, err, `can't read "not-exist.yaml"`)
+	})
+}
@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+)
+
+// usageFormats describes the formats of the report, in the order of the usage message.
+var usageFormats = []struct {
+	format      string
+	description string
+}{
+	{config.FormatHTML, "browsable report of the directories and files, the default"},
+	{config.FormatJSON, "coverage tree with the profile blocks, for scripts"},
+	{config.FormatMarkdown, "summary table for pull request comments"},
+	{config.FormatCSV, "statements and percentage per file, for spreadsheets"},
+	{config.FormatBadge, "SVG badge of the total coverage"},
+	{config.FormatTreemap, "SVG treemap of the files sized by statements"},
+	{config.FormatSunburst, "HTML page of a zoomable sunburst of the directories and files"},
+	{config.FormatGitHubActions, "workflow annotations of the uncovered blocks, to stdout"},
+	{config.FormatText, "uncovered lines of every file, to stdout"},
+	{config.FormatTeamCity, "service messages recording the total coverage, to stdout"},
+}
+
+// usageExamples are the command lines shown in the usage message, with what they do.
+var usageExamples = [][2]string{
+	{"go test -coverprofile=cover.prof ./... && covreport", "write cover.html from cover.prof"},
+	{"covreport -i cover.prof -o coverage.json", "write the json report, inferred from the extension"},
+	{"covreport -cutlines 80,50 -fail-under 70", "color below 50% and 80%, fail below 70%"},
+	{"covreport -format text -context 2", "print the uncovered lines with 2 lines of context"},
+	{"covreport -dry-run -thresholds thresholds.yaml", "only check the thresholds of the packages"},
+	{"covreport -diff origin/main -o diff.html", "report the coverage of the lines changed since main"},
+	{"covreport -watch", "regenerate the report whenever the profile changes"},
+}
+
+// usage returns the usage message of the flag set: the examples, the formats and their extensions,
+// then the defaults of every flag.
+func usage(fs *flag.FlagSet) func() {
+	return func() {
+		out := fs.Output()
+		fmt.Fprint(out, "Usage: covreport [flags]\n\nGenerates a coverage report from a Go coverage profile.\n\nExamples:\n")
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, example := range usageExamples {
+			fmt.Fprintf(tw, "  %s\t# %s\n", example[0], example[1])
+		}
+		tw.Flush()
+
+		fmt.Fprint(out, "\nFormats, set with -format or inferred from the extension of -o:\n")
+		tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, f := range usageFormats {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", f.format, strings.Join(config.FormatExtensions(f.format), " "), f.description)
+		}
+		tw.Flush()
+
+		fmt.Fprint(out, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+}
@@ -0,0 +1,81 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/drappier-charles/covreport/reporter/internal"
+)
+
+// defaultWatchInterval is the interval between the checks of the input in watch mode when not configured.
+const defaultWatchInterval = 500 * time.Millisecond
+
+// Watch generates the report of the configuration like Report, then polls the input files and regenerates it
+// whenever they change, until the context is done. Successive writes are debounced: the report is only
+// regenerated once the input files are left unchanged for an interval, so a test runner writing the profile
+// in several steps triggers a single report. Errors, such as a missing profile or unmet thresholds, are logged
+// without stopping the watch.
+func Watch(ctx context.Context, cfg *config.Config) error {
+	names := strings.Split(cfg.Input, internal.InputSeparator)
+	if cfg.Profile != "" {
+		names = append(names, strings.Split(cfg.Profile, internal.InputSeparator)...)
+	}
+	for _, name := range names {
+		if name == internal.StdinInput {
+			return errors.New("can't watch the standard input")
+		}
+	}
+	interval := cfg.WatchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	report := func() {
+		if err := Report(cfg); err != nil {
+			log.Printf("error: %v", err)
+		}
+	}
+	reported := inputState(names)
+	report()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	pending := reported
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		state := inputState(names)
+		if state != pending {
+			// Still being written, wait for the input to settle.
+			pending = state
+			continue
+		}
+		if state != reported {
+			reported = state
+			report()
+		}
+	}
+}
+
+// inputState returns the modification times and sizes of the named files, missing files included,
+// to tell when they change.
+func inputState(names []string) string {
+	var sb strings.Builder
+	for _, name := range names {
+		if info, err := os.Stat(name); err == nil {
+			fmt.Fprintf(&sb, "%s %d %d\n", name, info.ModTime().UnixNano(), info.Size())
+		} else {
+			fmt.Fprintf(&sb, "%s missing\n", name)
+		}
+	}
+	return sb.String()
+}
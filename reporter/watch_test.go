@@ -0,0 +1,64 @@
+package reporter_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drappier-charles/covreport/reporter"
+	"github.com/drappier-charles/covreport/reporter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	t.Run("should regenerate the report when the profile changes", func(t *testing.T) {
+		temp := t.TempDir()
+		source := filepath.Join(temp, "x.go")
+		assert.NoError(t, os.WriteFile(source, []byte("package x\n\nfunc f() {\n\tprintln()\n}\n"), 0o644))
+		input := filepath.Join(temp, "cover.prof")
+		writeProfile := func(count int) {
+			assert.NoError(t, os.WriteFile(input, []byte(fmt.Sprintf("mode: set\n%s:3.10,5.2 1 %d\n", source, count)), 0o644))
+		}
+		output := filepath.Join(temp, "cover.csv")
+		readOutput := func() string {
+			content, _ := os.ReadFile(output)
+			return string(content)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- reporter.Watch(ctx, &config.Config{
+				Input:         input,
+				Output:        output,
+				Root:          temp,
+				Cutlines:      &config.Cutlines{Safe: 70, Warning: 40},
+				Quiet:         true,
+				WatchInterval: 10 * time.Millisecond,
+			})
+		}()
+
+		// The profile doesn't exist yet when the watch starts.
+		time.Sleep(50 * time.Millisecond)
+		assert.NoFileExists(t, output)
+
+		writeProfile(0)
+		assert.Eventually(t, func() bool { return readOutput() != "" }, 5*time.Second, 10*time.Millisecond)
+		assert.Contains(t, readOutput(), "TOTAL,1,0,0.0\n")
+
+		writeProfile(1)
+		assert.Eventually(t, func() bool { return strings.Contains(readOutput(), "TOTAL,1,1,100.0\n") }, 5*time.Second, 10*time.Millisecond)
+
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("should return error when watching stdin", func(t *testing.T) {
+		err := reporter.Watch(context.Background(), &config.Config{Input: "cover.prof,-"})
+		assert.EqualError(t, err, "can't watch the standard input")
+	})
+}